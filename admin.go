@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+/* ---------- Admin auth ---------- */
+
+// adminEnabled reports whether any admin credential is configured.
+func adminEnabled() bool {
+	return appConfig.AdminToken != "" || appConfig.JWTIssuer != ""
+}
+
+// requireAdmin accepts:
+//   - "Authorization: Bearer <ADMIN_TOKEN>" from scripts
+//   - HTTP Basic auth with ADMIN_TOKEN as the password, for browsers
+//   - a JWT from JWT_ISSUER (Bearer header or the OIDC session cookie) whose
+//     subject passes ADMIN_EMAILS / ADMIN_DOMAINS
+//
+// Admin routes are disabled entirely while neither is configured. Browsers
+// without credentials are sent to the OIDC sign-in page when it is set up.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if !adminEnabled() {
+		writeJSON(w, http.StatusForbidden, ErrorResponse{Error: "admin_disabled", Detail: "Set ADMIN_TOKEN or JWT_ISSUER to enable admin routes"})
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		_, token, ok = r.BasicAuth()
+	}
+	if !ok {
+		if c, err := r.Cookie(oidcSessionName); err == nil {
+			token, ok = c.Value, true
+		}
+	}
+
+	detail := "Missing or invalid admin credentials"
+	if ok && appConfig.AdminToken != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(appConfig.AdminToken)) == 1 {
+		return true
+	}
+	if ok && appConfig.JWTIssuer != "" && strings.Count(token, ".") == 2 {
+		claims, err := verifyJWT(r.Context(), token)
+		if err == nil {
+			err = authorizeAdmin(claims)
+		}
+		if err == nil {
+			return true
+		}
+		detail = err.Error()
+	}
+
+	if oidcLoginEnabled() && r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html") {
+		http.Redirect(w, r, "/admin/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+		return false
+	}
+	if appConfig.AdminToken != "" {
+		w.Header().Add("WWW-Authenticate", `Basic realm="admin"`)
+	}
+	w.Header().Add("WWW-Authenticate", `Bearer realm="admin"`)
+	writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "unauthorized", Detail: detail})
+	return false
+}
+
+// sameOrigin rejects cross-site form posts: browsers resend Basic credentials
+// and session cookies automatically, so dashboard actions must come from the
+// dashboard itself.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		return true // not a browser form post
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// requireSameOrigin is sameOrigin for the admin JSON APIs' writes, answering
+// 403 itself. json.Decoder ignores Content-Type, so a cross-site form posted
+// as text/plain could otherwise pass for a JSON body.
+func requireSameOrigin(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || sameOrigin(r) {
+		return true
+	}
+	writeJSON(w, http.StatusForbidden, ErrorResponse{Error: "cross_origin", Detail: "Admin actions must come from this host"})
+	return false
+}
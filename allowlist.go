@@ -0,0 +1,94 @@
+package main
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strings"
+)
+
+/* ---------- Network allowlist ---------- */
+
+// restrictedPrefixes are only reachable from ADMIN_ALLOW_CIDRS, whatever
+// credentials the request carries.
+var restrictedPrefixes = []string{"/admin", "/debug", "/pprof"}
+
+func isRestrictedPath(path string) bool {
+	for _, p := range restrictedPrefixes {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// allowlist rejects requests for restricted paths from outside the
+// configured networks. With no networks configured everything is allowed.
+func allowlist(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(appConfig.AdminAllowNets) > 0 && isRestrictedPath(r.URL.Path) && !ipAllowed(clientIP(r)) {
+			incCounter("gotsport_requests_denied_total", "reason", "ip_allowlist")
+			writeJSON(w, http.StatusForbidden, ErrorResponse{Error: "ip_not_allowed", Detail: "This endpoint is not reachable from your network"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func ipAllowed(addr string) bool {
+	return inNets(addr, appConfig.AdminAllowNets)
+}
+
+func inNets(addr string, nets []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCIDRs reads the environment variable name as comma-separated
+// networks; bare addresses are single hosts.
+func parseCIDRs(name string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, part := range strings.Split(os.Getenv(name), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.Contains(part, "/") {
+			if ip := net.ParseIP(part); ip != nil && ip.To4() != nil {
+				part += "/32"
+			} else {
+				part += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(part)
+		if err != nil {
+			log.Printf("ignoring malformed %s entry %q", name, part)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// mountPprof registers the runtime profiler under /debug/pprof/.
+func mountPprof(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+func init() {
+	describeMetric("gotsport_requests_denied_total", kindCounter, "Requests refused before reaching a handler, by reason.")
+}
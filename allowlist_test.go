@@ -0,0 +1,45 @@
+package main
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// Behind a proxy, a client can't reach restricted paths by claiming an
+// allowed address in X-Forwarded-For; only the hop the proxy appended counts.
+func TestAllowlistIgnoresSpoofedForwardedFor(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	saved := appConfig
+	defer func() { appConfig = saved }()
+	t.Setenv("ADMIN_ALLOW_CIDRS", "10.0.0.0/8")
+	t.Setenv("TRUSTED_PROXIES", "172.16.0.0/12")
+	appConfig.TrustProxy = true
+	appConfig.AdminAllowNets = parseCIDRs("ADMIN_ALLOW_CIDRS")
+	appConfig.TrustedProxies = parseCIDRs("TRUSTED_PROXIES")
+
+	h := allowlist(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, tc := range []struct {
+		fwd  string
+		want int
+	}{
+		{"10.0.0.1, 198.51.100.7", http.StatusForbidden},             // spoofed hop, then the real client
+		{"10.0.0.1, 198.51.100.7, 172.16.0.9", http.StatusForbidden}, // the same through a second proxy
+		{"198.51.100.7, 10.0.0.1", http.StatusOK},                    // appended by the proxy
+		{"198.51.100.7, 10.0.0.1, 172.16.0.9", http.StatusOK},        // through a second, trusted proxy
+	} {
+		req := httptest.NewRequest("GET", "/admin/", nil)
+		req.RemoteAddr = "172.16.0.2:41234"
+		req.Header.Set("X-Forwarded-For", tc.fwd)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("X-Forwarded-For %q: got %d, want %d", tc.fwd, rec.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ---------- Game annotations ---------- */
+
+// annotation is a club note on a scraped game, such as who brings snacks or
+// marshals the field. Each game has at most one note per label.
+type annotation struct {
+	GameID    string    `json:"gameId"`
+	Label     string    `json:"label"`
+	Note      string    `json:"note"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// annotationSet is an in-memory copy of the game_annotations table.
+type annotationSet struct {
+	mu    sync.RWMutex
+	notes map[string][]annotation // by game ID, sorted by label
+}
+
+func (s *annotationSet) replace(as []annotation) {
+	m := map[string][]annotation{}
+	for _, a := range as {
+		m[a.GameID] = append(m[a.GameID], a)
+	}
+	s.mu.Lock()
+	s.notes = m
+	s.mu.Unlock()
+}
+
+// list returns the annotations for gameID, or every annotation when it is
+// empty.
+func (s *annotationSet) list(gameID string) []annotation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := []annotation{}
+	for id, as := range s.notes {
+		if gameID == "" || id == gameID {
+			out = append(out, as...)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].GameID != out[j].GameID {
+			return out[i].GameID < out[j].GameID
+		}
+		return out[i].Label < out[j].Label
+	})
+	return out
+}
+
+// withAnnotations sets Annotations on games that have any. It edits games in
+// place; getSchedule already returns a copy.
+func (s *Server) withAnnotations(games []Game) []Game {
+	s.annotations.mu.RLock()
+	defer s.annotations.mu.RUnlock()
+	for i, g := range games {
+		as := s.annotations.notes[g.ID]
+		if len(as) == 0 {
+			continue
+		}
+		games[i].Annotations = make(map[string]string, len(as))
+		for _, a := range as {
+			games[i].Annotations[a.Label] = a.Note
+		}
+	}
+	return games
+}
+
+func (s *Server) reloadAnnotations() error {
+	rows, err := s.store.Query(`SELECT game_id, label, note, updated_at FROM game_annotations ORDER BY game_id, label`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var as []annotation
+	for rows.Next() {
+		var a annotation
+		var updated int64
+		if err := rows.Scan(&a.GameID, &a.Label, &a.Note, &updated); err != nil {
+			return err
+		}
+		a.UpdatedAt = time.Unix(updated, 0).UTC()
+		as = append(as, a)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.annotations.replace(as)
+	return nil
+}
+
+// adminAnnotationsHandler lists (GET [?gameid=]), upserts (PUT/POST JSON
+// {gameId, label, note}) and deletes (DELETE ?gameid=&label=) annotations.
+// Game IDs are the id field of /schedule's games.
+func (s *Server) adminAnnotationsHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	if !requireAdmin(w, r) || !requireSameOrigin(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.annotations.list(strings.TrimSpace(r.URL.Query().Get("gameid"))))
+
+	case http.MethodPut, http.MethodPost:
+		var a annotation
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Detail: "Invalid JSON body"})
+			return
+		}
+		a.GameID, a.Label, a.Note = strings.TrimSpace(a.GameID), strings.TrimSpace(a.Label), strings.TrimSpace(a.Note)
+		if a.GameID == "" || a.Label == "" || a.Note == "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "gameId, label and note are required"})
+			return
+		}
+		a.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+		_, err := s.store.Exec(`INSERT INTO game_annotations (game_id, label, note, updated_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (game_id, label) DO UPDATE SET note = excluded.note, updated_at = excluded.updated_at`,
+			a.GameID, a.Label, a.Note, a.UpdatedAt.Unix())
+		if !s.annotationsChanged(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusOK, a)
+
+	case http.MethodDelete:
+		q := r.URL.Query()
+		gameID, label := strings.TrimSpace(q.Get("gameid")), strings.TrimSpace(q.Get("label"))
+		if gameID == "" || label == "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "gameid and label are required"})
+			return
+		}
+		res, err := s.store.Exec(`DELETE FROM game_annotations WHERE game_id = ? AND label = ?`, gameID, label)
+		if err == nil {
+			if n, _ := res.RowsAffected(); n == 0 {
+				writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "No " + label + " annotation on game " + gameID})
+				return
+			}
+		}
+		if !s.annotationsChanged(w, err) {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{
+			Error:  "method_not_allowed",
+			Detail: "Use GET, PUT, POST or DELETE",
+		})
+	}
+}
+
+// annotationsChanged reloads the index after a write, reporting any failure.
+func (s *Server) annotationsChanged(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		err = s.reloadAnnotations()
+	}
+	if err != nil {
+		log.Printf("annotations: %v", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return false
+	}
+	return true
+}
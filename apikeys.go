@@ -0,0 +1,160 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/* ---------- API keys and quotas ---------- */
+
+// apiKey is a registered client and its request quota per RateLimitWindow.
+type apiKey struct {
+	Name  string
+	Quota int
+}
+
+// apiKeyFrom returns the caller's key from X-API-Key, or from apikey= for
+// calendar apps that cannot set headers on subscriptions.
+func apiKeyFrom(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("apikey")
+}
+
+// quotaWindow counts one key's requests in the current fixed window.
+type quotaWindow struct {
+	start time.Time
+	used  int
+}
+
+var (
+	quotaMu sync.Mutex
+	quotas  = map[string]*quotaWindow{} // by key name
+)
+
+// windowStart aligns t to the start of its RateLimitWindow so every
+// replica agrees on reset times.
+func windowStart(t time.Time) time.Time {
+	return t.Truncate(quotaPeriod())
+}
+
+// quotaPeriod is RateLimitWindow, falling back to an hour if misconfigured.
+func quotaPeriod() time.Duration {
+	if appConfig.RateLimitWindow <= 0 {
+		return time.Hour
+	}
+	return appConfig.RateLimitWindow
+}
+
+// quotaState reports a key's usage; consume counts the current request.
+func quotaState(key apiKey, consume bool) (used int, reset time.Time) {
+	now := time.Now()
+	start := windowStart(now)
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+	q := quotas[key.Name]
+	if q == nil || !q.start.Equal(start) {
+		q = &quotaWindow{start: start}
+		quotas[key.Name] = q
+	}
+	if consume {
+		q.used++
+	}
+	return q.used, start.Add(quotaPeriod())
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, key apiKey, used int, reset time.Time) {
+	remaining := key.Quota - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(key.Quota))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+}
+
+// rateLimit enforces quotas for requests carrying an API key. Requests
+// without one are unaffected; unknown keys are rejected once any key is
+// configured.
+func rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := apiKeyFrom(r)
+		if raw == "" || len(appConfig.APIKeys) == 0 || r.Method == http.MethodOptions ||
+			r.URL.Path == "/health" || r.URL.Path == "/metrics" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key, ok := appConfig.APIKeys[raw]
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "invalid_api_key", Detail: "Unknown API key"})
+			return
+		}
+		// Checking usage neither uses up quota nor is refused for lack of it.
+		consume := r.URL.Path != "/me/usage"
+		used, reset := quotaState(key, consume)
+		setRateLimitHeaders(w, key, used, reset)
+		if consume && used > key.Quota {
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(reset).Seconds())+1))
+			writeJSON(w, http.StatusTooManyRequests, ErrorResponse{
+				Error:  "rate_limited",
+				Detail: "Quota of " + strconv.Itoa(key.Quota) + " requests per " + quotaPeriod().String() + " exceeded",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// meUsageHandler shows the calling key's consumption against its quota,
+// plus daily totals for the last week from the usage log.
+func meUsageHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	key, ok := appConfig.APIKeys[apiKeyFrom(r)]
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "invalid_api_key", Detail: "Send a registered key in X-API-Key"})
+		return
+	}
+	used, reset := quotaState(key, false)
+	remaining := key.Quota - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	type day struct {
+		Day      string `json:"day"`
+		Requests int    `json:"requests"`
+		Errors   int    `json:"errors"`
+	}
+	daily := []day{}
+	rows, err := db.Query(`SELECT day, SUM(requests), SUM(errors) FROM api_usage
+		WHERE client = ? AND day >= ? GROUP BY day ORDER BY day DESC`,
+		clientID(r), time.Now().UTC().AddDate(0, 0, -6).Format("2006-01-02"))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d day
+		if err := rows.Scan(&d.Day, &d.Requests, &d.Errors); err != nil {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+			return
+		}
+		daily = append(daily, d)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"name":          key.Name,
+		"limit":         key.Quota,
+		"used":          used,
+		"remaining":     remaining,
+		"windowSeconds": int(quotaPeriod().Seconds()),
+		"resetAt":       reset.UTC(),
+		"daily":         daily,
+	})
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/* ---------- Division audit ---------- */
+
+// auditTeam is one team's fixture count in a division audit. Missing and
+// Extra are against the expected count; Unfaced lists division teams it has
+// no fixture with.
+type auditTeam struct {
+	Team        string   `json:"team"`
+	Fixtures    int      `json:"fixtures"`
+	Home        int      `json:"home"`
+	Away        int      `json:"away"`
+	Unscheduled int      `json:"unscheduled"` // no kickoff date or time yet
+	Missing     int      `json:"missing,omitempty"`
+	Extra       int      `json:"extra,omitempty"`
+	Unfaced     []string `json:"unfaced,omitempty"`
+}
+
+// auditDuplicate is a fixture listed more than once.
+type auditDuplicate struct {
+	HomeTeam string `json:"homeTeam"`
+	AwayTeam string `json:"awayTeam"`
+	Date     string `json:"date"`
+	Time     string `json:"time"`
+	Count    int    `json:"count"`
+}
+
+// divisionAudit is what /audit/division returns.
+type divisionAudit struct {
+	EventID    string           `json:"eventid"`
+	Division   string           `json:"division"`
+	Fixtures   int              `json:"fixtures"`
+	Expected   int              `json:"expected"`
+	OK         bool             `json:"ok"`
+	Teams      []auditTeam      `json:"teams"`
+	Duplicates []auditDuplicate `json:"duplicates"`
+	Issues     []string         `json:"issues"`
+}
+
+// divisionFixtures fetches and parses every fixture in one division of an
+// event, played or not and whoever's they are. A numeric division is the
+// GotSport group ID and GotSport serves just that group; a name fetches the
+// whole event schedule and keeps the rows whose division contains it.
+func divisionFixtures(ctx context.Context, eventID, division string) ([]rawFixture, error) {
+	q := url.Values{}
+	byID := digitsOnly.MatchString(division)
+	if byID {
+		q.Set("group", division)
+	}
+	body, err := fetchPage(ctx, "gotsport", fmt.Sprintf("%s/org_event/events/%s/schedules?%s", gotsportOrigin, url.PathEscape(eventID), q.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	stats := newParseStats(eventID, len(body))
+	stats.keepFixtures = true
+	parseWeekendGames(string(body), eventID, scheduleFilter{}, stats)
+	var out []rawFixture
+	for _, f := range stats.fixtures {
+		if isBracketSlot(f.Home) || isBracketSlot(f.Away) {
+			continue // knockout slots aren't part of the league table
+		}
+		if byID || containsFold(f.Division, division) {
+			out = append(out, f)
+		}
+	}
+	if len(out) == 0 {
+		return nil, classify(ErrParseEmpty, fmt.Errorf("no fixtures found for division %s of event %s", division, eventID))
+	}
+	return out, nil
+}
+
+// auditDivision counts each team's fixtures against expected, or against
+// the most common count when expected is 0, and finds repeated fixtures.
+func auditDivision(eventID, division string, fixtures []rawFixture, expected int) divisionAudit {
+	a := divisionAudit{EventID: eventID, Division: division, Fixtures: len(fixtures), Teams: []auditTeam{}, Duplicates: []auditDuplicate{}, Issues: []string{}}
+	teams := map[string]*auditTeam{}
+	faced := map[string]map[string]bool{}
+	team := func(name string) *auditTeam {
+		t := teams[name]
+		if t == nil {
+			t = &auditTeam{Team: name}
+			teams[name] = t
+			faced[name] = map[string]bool{}
+		}
+		return t
+	}
+	seen := map[string]*auditDuplicate{}
+	var order []string
+	for _, f := range fixtures {
+		home, away := team(f.Home), team(f.Away)
+		home.Fixtures++
+		home.Home++
+		away.Fixtures++
+		away.Away++
+		if f.Date == "" || f.Time == "TBD" {
+			home.Unscheduled++
+			away.Unscheduled++
+		}
+		faced[f.Home][f.Away], faced[f.Away][f.Home] = true, true
+
+		key := strings.ToLower(f.Home + "|" + f.Away + "|" + f.Date + "|" + f.Time)
+		if d, ok := seen[key]; ok {
+			d.Count++
+			continue
+		}
+		seen[key] = &auditDuplicate{HomeTeam: f.Home, AwayTeam: f.Away, Date: f.Date, Time: f.Time, Count: 1}
+		order = append(order, key)
+	}
+
+	if expected <= 0 {
+		expected = modalCount(teams)
+	}
+	a.Expected = expected
+	names := make([]string, 0, len(teams))
+	for name := range teams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t := teams[name]
+		switch {
+		case t.Fixtures < expected:
+			t.Missing = expected - t.Fixtures
+			a.Issues = append(a.Issues, fmt.Sprintf("%s has %d fixtures, %d fewer than expected", name, t.Fixtures, t.Missing))
+		case t.Fixtures > expected:
+			t.Extra = t.Fixtures - expected
+			a.Issues = append(a.Issues, fmt.Sprintf("%s has %d fixtures, %d more than expected", name, t.Fixtures, t.Extra))
+		}
+		if t.Home-t.Away > 1 || t.Away-t.Home > 1 {
+			a.Issues = append(a.Issues, fmt.Sprintf("%s is at home %d times and away %d", name, t.Home, t.Away))
+		}
+		if t.Unscheduled > 0 {
+			a.Issues = append(a.Issues, fmt.Sprintf("%s has %d fixtures with no kickoff yet", name, t.Unscheduled))
+		}
+		// Every team should meet every other once a round robin's worth
+		// of fixtures is expected.
+		if expected >= len(teams)-1 {
+			for _, other := range names {
+				if other != name && !faced[name][other] {
+					t.Unfaced = append(t.Unfaced, other)
+				}
+			}
+			if len(t.Unfaced) > 0 {
+				a.Issues = append(a.Issues, fmt.Sprintf("%s has no fixture with %s", name, strings.Join(t.Unfaced, ", ")))
+			}
+		}
+		a.Teams = append(a.Teams, *t)
+	}
+	for _, key := range order {
+		if d := seen[key]; d.Count > 1 {
+			a.Duplicates = append(a.Duplicates, *d)
+			a.Issues = append(a.Issues, fmt.Sprintf("%s vs %s on %s %s is listed %d times", d.HomeTeam, d.AwayTeam, d.Date, d.Time, d.Count))
+		}
+	}
+	a.OK = len(a.Issues) == 0
+	return a
+}
+
+// modalCount is the most common fixture count, the larger on a tie.
+func modalCount(teams map[string]*auditTeam) int {
+	freq := map[int]int{}
+	for _, t := range teams {
+		freq[t.Fixtures]++
+	}
+	best := 0
+	for count, n := range freq {
+		if n > freq[best] || n == freq[best] && count > best {
+			best = count
+		}
+	}
+	return best
+}
+
+// auditDivisionHandler serves /audit/division?eventid=&division=[&expected=]:
+// every team in the division with its fixture count, flagging teams with
+// fewer or more than expected, lopsided home and away counts, fixtures
+// without a kickoff, and fixtures listed twice. expected defaults to the
+// count most teams have.
+func auditDivisionHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	eventID, division := strings.TrimSpace(q.Get("eventid")), strings.TrimSpace(q.Get("division"))
+	if eventID == "" || division == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "eventid and division are required"})
+		return
+	}
+	expected := 0
+	if v := q.Get("expected"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeJSON(w, http.StatusBadRequest, errorResponse(badParamsf("expected must be a positive number of fixtures")))
+			return
+		}
+		expected = n
+	}
+	fixtures, err := divisionFixtures(r.Context(), eventID, division)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, errorResponse(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, auditDivision(eventID, division, fixtures, expected))
+}
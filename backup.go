@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/* ---------- Backup and restore ---------- */
+
+// maxRestoreBytes caps the size of an uploaded backup.
+const maxRestoreBytes = 1 << 30
+
+// loadIndexes refreshes every in-memory copy of a table in s's datastore, at
+// startup and after a restore.
+func (s *Server) loadIndexes() error {
+	for name, reload := range map[string]func() error{
+		"venues":       s.reloadVenues,
+		"annotations":  s.reloadAnnotations,
+		"overrides":    s.reloadOverrides,
+		"hidden games": s.reloadHidden,
+		"manual games": s.reloadManualGames,
+		"webhooks":     s.reloadWebhooks,
+	} {
+		if err := reload(); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// errNotSQLite answers backup and restore requests against a Postgres
+// datastore, which has its own tools for both.
+func errNotSQLite(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotImplemented, ErrorResponse{
+		Error:  "not_supported",
+		Detail: "Backups are for the SQLite datastore; use pg_dump and pg_restore with Postgres",
+	})
+}
+
+// adminBackupHandler streams a consistent copy of the datastore, made with
+// VACUUM INTO so writers are only blocked while it is taken.
+func (s *Server) adminBackupHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method_not_allowed", Detail: "Use GET"})
+		return
+	}
+	if _, ok := sqliteHandle(s.store); !ok {
+		errNotSQLite(w)
+		return
+	}
+	dir, err := os.MkdirTemp("", "gotsport-backup")
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "backup_failed", Detail: err.Error()})
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "backup.db")
+	if _, err := s.store.ExecContext(r.Context(), `VACUUM INTO ?`, path); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "backup_failed", Detail: err.Error()})
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "backup_failed", Detail: err.Error()})
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="gotsport-%s.db"`, time.Now().UTC().Format("20060102T150405Z")))
+	if fi, err := f.Stat(); err == nil {
+		w.Header().Set("Content-Length", fmt.Sprint(fi.Size()))
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		log.Printf("backup: %v", err)
+	}
+}
+
+// adminRestoreHandler replaces the datastore's contents with an uploaded
+// backup (POST, the raw file as the body). The backup is migrated to the
+// current schema first, then every table is copied over in one transaction,
+// so a failed restore leaves the data as it was.
+func (s *Server) adminRestoreHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method_not_allowed", Detail: "Use POST"})
+		return
+	}
+	if !sameOrigin(r) {
+		writeJSON(w, http.StatusForbidden, ErrorResponse{Error: "cross_origin", Detail: "Admin actions must come from this host"})
+		return
+	}
+	if _, ok := sqliteHandle(s.store); !ok {
+		errNotSQLite(w)
+		return
+	}
+	dir, err := os.MkdirTemp("", "gotsport-restore")
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "restore_failed", Detail: err.Error()})
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "restore.db")
+	f, err := os.Create(path)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "restore_failed", Detail: err.Error()})
+		return
+	}
+	_, err = io.Copy(f, http.MaxBytesReader(w, r.Body, maxRestoreBytes))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	var tooBig *http.MaxBytesError
+	if errors.As(err, &tooBig) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: "too_large", Detail: fmt.Sprintf("backups are limited to %d bytes", int64(maxRestoreBytes))})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Detail: err.Error()})
+		return
+	}
+
+	rows, err := restoreFrom(r.Context(), s.store, path)
+	if err != nil {
+		log.Printf("restore: %v", err)
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "restore_failed", Detail: err.Error()})
+		return
+	}
+	if err := s.loadIndexes(); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return
+	}
+	log.Printf("restore: replaced datastore contents (%d rows)", rows)
+	writeJSON(w, http.StatusOK, map[string]any{"status": "restored", "rows": rows})
+}
+
+// restoreFrom copies every table of the SQLite file at path over d's,
+// returning the number of rows copied.
+func restoreFrom(ctx context.Context, d Store, path string) (int64, error) {
+	src, err := openDB(path) // checks it is a datastore and brings it up to date
+	if err != nil {
+		return 0, fmt.Errorf("not a usable backup: %v", err)
+	}
+	var check string
+	err = src.QueryRow(`PRAGMA integrity_check`).Scan(&check)
+	src.Close()
+	if err != nil {
+		return 0, fmt.Errorf("not a usable backup: %v", err)
+	}
+	if check != "ok" {
+		return 0, fmt.Errorf("backup failed its integrity check: %s", check)
+	}
+
+	handle, ok := sqliteHandle(d)
+	if !ok {
+		return 0, errors.New("the datastore is not SQLite")
+	}
+	conn, err := handle.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS backup`, path); err != nil {
+		return 0, err
+	}
+	defer conn.ExecContext(context.Background(), `DETACH DATABASE backup`)
+
+	var tables []string
+	names, err := conn.QueryContext(ctx, `SELECT name FROM main.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_version'`)
+	if err != nil {
+		return 0, err
+	}
+	for names.Next() {
+		var name string
+		if err := names.Scan(&name); err != nil {
+			names.Close()
+			return 0, err
+		}
+		tables = append(tables, name)
+	}
+	names.Close()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var total int64
+	for _, t := range tables {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM main.`+t); err != nil {
+			return 0, fmt.Errorf("%s: %v", t, err)
+		}
+		res, err := tx.ExecContext(ctx, `INSERT INTO main.`+t+` SELECT * FROM backup.`+t)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %v", t, err)
+		}
+		n, _ := res.RowsAffected()
+		total += n
+	}
+	return total, tx.Commit()
+}
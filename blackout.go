@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+/* ---------- Blackout dates ---------- */
+
+// blackout is a span of days the club, or one of its teams, can't play:
+// tournament travel, holidays, school events.
+type blackout struct {
+	From   string // 2006-01-02, inclusive
+	To     string
+	Team   string // empty for the whole club; else matched within team names
+	Reason string
+}
+
+// parseBlackouts reads BLACKOUT_DATES, ";"-separated entries of the form
+// date[..date][@team]=reason, e.g.
+// "2025-11-27=Thanksgiving;2025-10-11..2025-10-12@2012B=Surf Cup travel".
+func parseBlackouts(s string) []blackout {
+	var out []blackout
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		span, reason, _ := strings.Cut(part, "=")
+		span, team, _ := strings.Cut(span, "@")
+		from, to, ranged := strings.Cut(strings.TrimSpace(span), "..")
+		if !ranged {
+			to = from
+		}
+		b := blackout{From: strings.TrimSpace(from), To: strings.TrimSpace(to), Team: strings.TrimSpace(team), Reason: strings.TrimSpace(reason)}
+		_, err1 := time.Parse("2006-01-02", b.From)
+		_, err2 := time.Parse("2006-01-02", b.To)
+		if err1 != nil || err2 != nil || b.To < b.From {
+			log.Printf("ignoring malformed BLACKOUT_DATES entry %q (want 2025-11-27[..2025-11-28][@team]=reason)", part)
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+func (b blackout) covers(g Game) bool {
+	if g.Date < b.From || g.Date > b.To {
+		return false
+	}
+	return b.Team == "" || containsFold(g.HomeTeam, b.Team) || containsFold(g.AwayTeam, b.Team)
+}
+
+func init() {
+	registerFlagCheck(func(games []Game) {
+		for i := range games {
+			for _, b := range appConfig.Blackouts {
+				if !b.covers(games[i]) {
+					continue
+				}
+				msg := fmt.Sprintf("scheduled on a blackout date (%s)", games[i].Date)
+				if b.Reason != "" {
+					msg = fmt.Sprintf("scheduled on a blackout date: %s", b.Reason)
+				}
+				games[i].flag("blackout", msg)
+				break
+			}
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* ---------- Bye weeks ---------- */
+
+// teamByes is one club team's upcoming weekends without a game.
+type teamByes struct {
+	Team     string   `json:"team"`
+	Division string   `json:"division,omitempty"`
+	Games    int      `json:"games"` // upcoming weekend games
+	Byes     []string `json:"byes"`  // Saturdays, 2006-01-02
+}
+
+// weekendByes is the club teams off on one weekend.
+type weekendByes struct {
+	Weekend string   `json:"weekend"` // the Saturday
+	Teams   []string `json:"teams"`
+}
+
+// byesReport is what /byes returns.
+type byesReport struct {
+	ClubID    string        `json:"clubid"`
+	Events    []string      `json:"events"`
+	Weekends  []string      `json:"weekends"`
+	Teams     []teamByes    `json:"teams"`
+	ByWeekend []weekendByes `json:"byWeekend"`
+}
+
+// weekendOf returns the Saturday of the weekend date falls on, or false
+// for a weekday or unreadable date.
+func weekendOf(date string) (string, bool) {
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", false
+	}
+	switch d.Weekday() {
+	case time.Saturday:
+		return date, true
+	case time.Sunday:
+		return d.AddDate(0, 0, -1).Format("2006-01-02"), true
+	}
+	return "", false
+}
+
+// clubEvents splits a comma-separated eventid parameter, defaulting to the
+// events configured for clubID.
+func clubEvents(param, clubID string) []string {
+	var events []string
+	for _, id := range strings.Split(param, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			events = append(events, id)
+		}
+	}
+	if len(events) == 0 {
+		for _, ref := range appConfig.Events {
+			if ref.ClubID == clubID {
+				events = append(events, ref.EventID)
+			}
+		}
+	}
+	return events
+}
+
+// byesHandler serves /byes?clubid=[&eventid=]: for each of the club's
+// teams, the weekends from this one to the last scheduled game on which it
+// has no game. eventid (comma-separated) defaults to the configured events
+// for the club. Teams only appear once a schedule listing them has been
+// scraped, and a team whose season ends early shows byes after its last
+// game.
+func (s *Server) byesHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	clubID := strings.TrimSpace(q.Get("clubid"))
+	if clubID == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "clubid is required"})
+		return
+	}
+	events := clubEvents(q.Get("eventid"), clubID)
+	if len(events) == 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse(badParamsf("no events are configured for club %s; pass eventid", clubID)))
+		return
+	}
+
+	// This weekend still counts until it is over.
+	today := s.clock.Now().In(eventLocation())
+	from, _ := weekendOf(today.Format("2006-01-02"))
+	if from == "" {
+		from = today.AddDate(0, 0, int(time.Saturday-today.Weekday())).Format("2006-01-02")
+	}
+
+	// AllSides keeps away games and every weekend, not just the next.
+	f := scheduleFilter{AllSides: true}
+	played := map[string]map[string]bool{} // team -> Saturdays with a game
+	teams := map[string]*teamByes{}
+	last := ""
+	for _, eventID := range events {
+		res, err := s.getSchedule(r.Context(), eventID, clubID, f, false, s.cache.maxAge())
+		if err != nil {
+			writeJSON(w, http.StatusBadGateway, errorResponse(err))
+			return
+		}
+		for _, g := range res.Games {
+			team := clubSide(g)
+			sat, ok := weekendOf(g.Date)
+			if team == "" || isBracketSlot(g.HomeTeam) || isBracketSlot(g.AwayTeam) {
+				continue
+			}
+			t := teams[team]
+			if t == nil {
+				t = &teamByes{Team: team, Division: g.Division, Byes: []string{}}
+				teams[team] = t
+				played[team] = map[string]bool{}
+			}
+			if !ok || sat < from {
+				continue
+			}
+			t.Games++
+			played[team][sat] = true
+			if sat > last {
+				last = sat
+			}
+		}
+	}
+
+	report := byesReport{ClubID: clubID, Events: events, Weekends: []string{}, Teams: []teamByes{}, ByWeekend: []weekendByes{}}
+	if last != "" {
+		start, _ := time.Parse("2006-01-02", from)
+		for d := start; d.Format("2006-01-02") <= last; d = d.AddDate(0, 0, 7) {
+			report.Weekends = append(report.Weekends, d.Format("2006-01-02"))
+		}
+	}
+	names := make([]string, 0, len(teams))
+	for name := range teams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	off := map[string][]string{}
+	for _, name := range names {
+		t := teams[name]
+		for _, sat := range report.Weekends {
+			if !played[name][sat] {
+				t.Byes = append(t.Byes, sat)
+				off[sat] = append(off[sat], name)
+			}
+		}
+		report.Teams = append(report.Teams, *t)
+	}
+	for _, sat := range report.Weekends {
+		if len(off[sat]) > 0 {
+			report.ByWeekend = append(report.ByWeekend, weekendByes{Weekend: sat, Teams: off[sat]})
+		}
+	}
+	writeJSON(w, http.StatusOK, report)
+}
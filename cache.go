@@ -0,0 +1,85 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+/* ---------- Schedule cache ---------- */
+
+// cacheEntry holds one scrape result and when it was fetched.
+type cacheEntry struct {
+	games     []Game
+	fetchedAt time.Time
+}
+
+// scheduleCache is an in-memory store of scrape results keyed by event/club.
+// Every filtered request adds an entry of its own, so it holds at most
+// maxEntries (CACHE_MAX_ENTRIES), dropping the oldest to make room.
+type scheduleCache struct {
+	mu         sync.RWMutex
+	entries    map[string]cacheEntry
+	ttl        time.Duration
+	maxEntries int // 0 for no limit
+}
+
+var defaultCache = newScheduleCache(durationFromEnv("CACHE_TTL", 10*time.Minute))
+
+func init() {
+	describeMetric("gotsport_cache_evictions_total", kindCounter, "Schedules dropped from the cache to stay within CACHE_MAX_ENTRIES.")
+}
+
+func newScheduleCache(ttl time.Duration) *scheduleCache {
+	return &scheduleCache{entries: make(map[string]cacheEntry), ttl: ttl, maxEntries: intFromEnv("CACHE_MAX_ENTRIES", 1000)}
+}
+
+func cacheKey(eventID, clubID string) string {
+	return eventID + "|" + clubID
+}
+
+// get returns the cached games for key if they are no older than maxAge.
+func (c *scheduleCache) get(key string, maxAge time.Duration) ([]Game, time.Duration, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, 0, false
+	}
+	age := time.Since(e.fetchedAt)
+	if age > maxAge {
+		return nil, age, false
+	}
+	return append([]Game(nil), e.games...), age, true
+}
+
+// peek returns the cached games for key regardless of age.
+func (c *scheduleCache) peek(key string) ([]Game, time.Time, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[key]
+	c.mu.RUnlock()
+	return e.games, e.fetchedAt, ok
+}
+
+func (c *scheduleCache) maxAge() time.Duration { return c.ttl }
+
+func (c *scheduleCache) set(key string, games []Game) {
+	c.mu.Lock()
+	if _, ok := c.entries[key]; !ok && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evictOldest()
+	}
+	c.entries[key] = cacheEntry{games: append([]Game(nil), games...), fetchedAt: time.Now()}
+	c.mu.Unlock()
+}
+
+// evictOldest drops the entry fetched longest ago. c.mu must be held.
+func (c *scheduleCache) evictOldest() {
+	var oldest string
+	var at time.Time
+	for key, e := range c.entries {
+		if oldest == "" || e.fetchedAt.Before(at) {
+			oldest, at = key, e.fetchedAt
+		}
+	}
+	delete(c.entries, oldest)
+	incCounter("gotsport_cache_evictions_total")
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ---------- Carpool export ---------- */
+
+// maxCarpoolTeams bounds how many team pages one /carpool request scrapes.
+const maxCarpoolTeams = 6
+
+// carpoolGame is a game in a family's merged schedule. Overlap is set when
+// another of the family's games is on at the same time; Conflict when that
+// game is at a different venue, so one driver can't cover both.
+type carpoolGame struct {
+	Game
+	// FamilyTeams are the family's teams playing in this game; two when
+	// siblings' teams meet.
+	FamilyTeams  []string `json:"familyTeams"`
+	Overlap      bool     `json:"overlap"`
+	Conflict     bool     `json:"conflict"`
+	OverlapsWith []string `json:"overlapsWith,omitempty"` // the other games' family teams
+
+	start, end time.Time
+}
+
+// mergeFamilySchedules merges each team's games chronologically, collapsing
+// games two of the teams share, and flags the ones that overlap.
+func mergeFamilySchedules(byTeam [][]Game) []carpoolGame {
+	var out []carpoolGame
+	seen := map[string]int{} // fixture key -> index in out
+	for _, games := range byTeam {
+		for _, g := range games {
+			team := clubSide(g)
+			start, ok := gameKickoff(g)
+			if team == "" || !ok {
+				continue // bracket slots and unreadable kickoffs
+			}
+			key := fixtureKickoffKey(g)
+			if i, ok := seen[key]; ok {
+				if !containsString(out[i].FamilyTeams, team) {
+					out[i].FamilyTeams = append(out[i].FamilyTeams, team)
+				}
+				continue
+			}
+			seen[key] = len(out)
+			out = append(out, carpoolGame{Game: g, FamilyTeams: []string{team}, start: start, end: start.Add(gameDuration(g, start))})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].start.Before(out[j].start) })
+	for i := range out {
+		for j := i + 1; j < len(out) && out[j].start.Before(out[i].end); j++ {
+			a, b := &out[i], &out[j]
+			a.Overlap, b.Overlap = true, true
+			a.OverlapsWith = appendMissing(a.OverlapsWith, b.FamilyTeams...)
+			b.OverlapsWith = appendMissing(b.OverlapsWith, a.FamilyTeams...)
+			if !strings.EqualFold(venueName(a.Location), venueName(b.Location)) {
+				a.Conflict, b.Conflict = true, true
+			}
+		}
+	}
+	return out
+}
+
+func appendMissing(list []string, items ...string) []string {
+	for _, s := range items {
+		if !containsString(list, s) {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// venueName is a location without its field, "Golden Eagle Regional Park"
+// for "Golden Eagle Regional Park - Field 3", so games at one complex don't
+// count as a conflict.
+func venueName(location string) string {
+	name, _, _ := strings.Cut(location, " - ")
+	return strings.TrimSpace(name)
+}
+
+// carpoolHandler serves /carpool?eventid=44145&teams=111,222[&format=csv|ics]
+// [&refresh=true]: every game of a family's teams, home and away, in one
+// chronological list with overlaps flagged.
+func (s *Server) carpoolHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	eventIDs, teams := splitEventIDs(q.Get("eventid")), splitEventIDs(q.Get("teams"))
+	if len(eventIDs) == 0 || len(teams) == 0 {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "missing_parameters",
+			Detail: "eventid and teams are required",
+		})
+		return
+	}
+	if len(teams) > maxCarpoolTeams {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "invalid_parameters",
+			Detail: "at most " + strconv.Itoa(maxCarpoolTeams) + " teams",
+		})
+		return
+	}
+	format := q.Get("format")
+	if format != "" && format != "json" && format != "csv" && format != "ics" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "invalid_parameters",
+			Detail: "format must be json, csv or ics",
+		})
+		return
+	}
+	refresh := q.Get("refresh") == "true"
+
+	var byTeam [][]Game
+	overall := scheduleResult{Tier: tierFresh}
+	for _, team := range teams {
+		f := scheduleFilter{Team: team, AllSides: true}
+		if err := f.validate(); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:  "invalid_parameters",
+				Detail: "teams must be numeric GotSport team IDs",
+			})
+			return
+		}
+		res, err := s.getMergedSchedule(r.Context(), eventIDs, "", f, refresh, s.cache.maxAge())
+		if errors.Is(err, errScrapeQueueFull) {
+			rejectOverloaded(w, http.StatusTooManyRequests, "scrape_queue",
+				"Scrape queue is full; retry shortly")
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{
+				Error:  errorCode(err),
+				Detail: "team " + team + ": " + err.Error(),
+			})
+			return
+		}
+		byTeam = append(byTeam, res.Games)
+		overall.Age = max(overall.Age, res.Age)
+		overall.Tier = worseTier(overall.Tier, res.Tier)
+	}
+	games := mergeFamilySchedules(byTeam)
+
+	setCacheHeaders(w, overall)
+	switch format {
+	case "ics":
+		plain := make([]Game, len(games))
+		for i, g := range games {
+			plain[i] = g.Game
+		}
+		writeICS(w, plain, nil)
+	case "csv":
+		writeCarpoolCSV(w, games)
+	default:
+		writeJSON(w, http.StatusOK, games)
+	}
+}
+
+func writeCarpoolCSV(w http.ResponseWriter, games []carpoolGame) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="carpool.csv"`)
+	w.WriteHeader(http.StatusOK)
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"date", "time", "endTime", "familyTeams", "homeTeam", "awayTeam", "location", "address", "overlap", "conflict", "overlapsWith"})
+	for _, g := range games {
+		_ = cw.Write([]string{g.Date, g.Time, g.EndTime, strings.Join(g.FamilyTeams, "; "), g.HomeTeam, g.AwayTeam, g.Location, g.Address,
+			strconv.FormatBool(g.Overlap), strconv.FormatBool(g.Conflict), strings.Join(g.OverlapsWith, "; ")})
+	}
+	cw.Flush()
+}
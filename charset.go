@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+/* ---------- Response charsets ---------- */
+
+// metaCharsetPattern finds <meta charset="..."> or the charset parameter of
+// <meta http-equiv="Content-Type" content="text/html; charset=...">.
+var metaCharsetPattern = regexp.MustCompile(`(?i)<meta\b[^>]*?\bcharset\s*=\s*["']?\s*([\w.:-]+)`)
+
+// metaPrescan is how much of a page is searched for a meta charset, as in
+// the HTML spec's prescan.
+const metaPrescan = 1024
+
+func init() {
+	describeMetric("gotsport_transcoded_responses_total", kindCounter, "Upstream responses transcoded to UTF-8, by source charset.")
+}
+
+// toUTF8 transcodes an upstream body to UTF-8. The charset comes from a
+// byte-order mark, the Content-Type header or a meta tag, in that order;
+// with none declared, a body that isn't valid UTF-8 is read as
+// windows-1252, which is what ECNL serves undeclared.
+func toUTF8(body []byte, contentType string) []byte {
+	if bytes.HasPrefix(body, []byte("\xef\xbb\xbf")) {
+		return body[3:]
+	}
+	label := declaredCharset(body, contentType)
+	if label == "" {
+		if utf8.Valid(body) {
+			return body
+		}
+		label = "windows-1252"
+	}
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		log.Printf("unknown charset %q; reading as UTF-8", label)
+		return body
+	}
+	name, _ := htmlindex.Name(enc)
+	if name == "utf-8" {
+		return body
+	}
+	out, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		log.Printf("transcoding from %s: %v", name, err)
+		return body
+	}
+	incCounter("gotsport_transcoded_responses_total", "charset", name)
+	return out
+}
+
+// declaredCharset returns the charset named by contentType or, failing that,
+// a meta tag near the top of body.
+func declaredCharset(body []byte, contentType string) string {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		return strings.ToLower(params["charset"])
+	}
+	if m := metaCharsetPattern.FindSubmatch(body[:min(len(body), metaPrescan)]); m != nil {
+		return strings.ToLower(string(m[1]))
+	}
+	return ""
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/* ---------- CLI ---------- */
+
+// The binary doubles as a one-shot CLI for cron jobs:
+//
+//	gotsport-scraper schedule --event 44145 --club 12893 --format csv
+//	gotsport-scraper export --event 44145 --club 12893 --out weekend.xlsx
+//	gotsport-scraper standings --event 44145 --club 12893 --division U13
+//
+// With no subcommand (or "serve") it starts the HTTP server.
+
+var cliCommands = map[string]func(args []string) error{
+	"schedule":  cliSchedule,
+	"export":    cliExport,
+	"results":   cliResults,
+	"standings": cliStandings,
+}
+
+const cliUsage = `usage: gotsport-scraper <command> [flags]
+
+commands:
+  serve      start the HTTP server (default)
+  schedule   scrape once and print the schedule to stdout (or --out)
+  export     scrape once and write the schedule to --out; format from extension
+  results    scrape once and print the final scores on the club's schedule page
+  standings  scrape once and print division tables worked out from those scores
+  watch      poll a schedule and print (and optionally act on) changes
+  selftest   check the parser against the bundled fixtures
+
+run "gotsport-scraper <command> -h" for flags`
+
+// runCLI runs a subcommand and reports whether args named one.
+func runCLI(args []string) (bool, int) {
+	if len(args) == 0 || args[0] == "serve" {
+		return false, 0
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintln(os.Stderr, cliUsage)
+		return true, 0
+	}
+	cmd, ok := cliCommands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", args[0], cliUsage)
+		return true, 2
+	}
+	if err := cmd(args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return true, 1
+	}
+	return true, 0
+}
+
+type cliScheduleFlags struct {
+	event, club, format, out, alarm string
+	quiet, explain                  bool
+}
+
+func (f *cliScheduleFlags) register(fs *flag.FlagSet, defaultFormat string) {
+	fs.StringVar(&f.event, "event", "", "GotSport event ID (required)")
+	fs.StringVar(&f.club, "club", "", "GotSport club ID (required)")
+	fs.StringVar(&f.format, "format", defaultFormat, "output format: json, csv, ics, xml, rss, xlsx, pdf, msgpack")
+	fs.StringVar(&f.out, "out", "", "write to this file instead of stdout")
+	fs.StringVar(&f.alarm, "alarm", "", "ICS reminders before kickoff, e.g. 60m or 1d,60m")
+	fs.BoolVar(&f.quiet, "quiet", false, "suppress scraper logging")
+	fs.BoolVar(&f.explain, "explain", false, "print the parser's per-row decisions as JSON instead of the schedule")
+}
+
+func cliSchedule(args []string) error {
+	var f cliScheduleFlags
+	fs := flag.NewFlagSet("schedule", flag.ContinueOnError)
+	f.register(fs, "json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return cliWriteSchedule(f)
+}
+
+func cliExport(args []string) error {
+	var f cliScheduleFlags
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	f.register(fs, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if f.out == "" {
+		return fmt.Errorf("export needs --out")
+	}
+	if f.format == "" {
+		f.format = strings.TrimPrefix(strings.ToLower(filepath.Ext(f.out)), ".")
+	}
+	return cliWriteSchedule(f)
+}
+
+func cliWriteSchedule(f cliScheduleFlags) error {
+	if f.event == "" || f.club == "" {
+		return fmt.Errorf("--event and --club are required")
+	}
+	if f.quiet {
+		log.SetOutput(io.Discard)
+	}
+	format := strings.ToLower(f.format)
+	if _, ok := formatByName(format); !ok || format == "print" {
+		return fmt.Errorf("unsupported format %q", f.format)
+	}
+	alarms, err := parseAlarms(f.alarm)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if f.out != "" {
+		file, err := os.Create(f.out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if f.explain {
+		games, stats, err := scrapeGotSport(context.Background(), f.event, f.club, scheduleFilter{}, true)
+		if stats == nil {
+			return err
+		}
+		resp := map[string]any{"games": games, "stats": stats}
+		if err != nil {
+			resp["error"] = err.Error()
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(resp)
+	}
+
+	games, tier, err := defaultServer.loadSchedule(context.Background(), f.event, f.club, scheduleFilter{})
+	if err != nil {
+		return err
+	}
+	for _, pw := range scheduleWarnings([]string{f.event}, f.club, scheduleFilter{}) {
+		fmt.Fprintf(os.Stderr, "warning: row %d (%s): %s\n", pw.Row, pw.Value, pw.Message)
+	}
+	w := &streamResponseWriter{w: out, header: http.Header{}}
+	res := scheduleResult{Games: markPast(defaultServer.presentGames(games), clock(), appConfig.UpcomingOnly), Tier: tier}
+	writeSchedule(w, scheduleReq{EventID: f.event, ClubID: f.club, Format: format}, res, alarms, nil)
+	return w.err
+}
+
+// cliResultsFlags are the flags results and standings share.
+type cliResultsFlags struct {
+	event, club, division, format, out string
+	quiet                              bool
+}
+
+func (f *cliResultsFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.event, "event", "", "GotSport event ID (required)")
+	fs.StringVar(&f.club, "club", "", "GotSport club ID whose schedule page to read (required)")
+	fs.StringVar(&f.division, "division", "", "keep divisions whose name contains this")
+	fs.StringVar(&f.format, "format", "json", "output format: json or csv")
+	fs.StringVar(&f.out, "out", "", "write to this file instead of stdout")
+	fs.BoolVar(&f.quiet, "quiet", false, "suppress scraper logging")
+}
+
+// scrape reads the final scores on the club's schedule page, those of
+// games between other clubs included.
+func (f *cliResultsFlags) scrape() ([]postedResult, error) {
+	if f.event == "" || f.club == "" {
+		return nil, fmt.Errorf("--event and --club are required")
+	}
+	f.format = strings.ToLower(f.format)
+	if f.format != "json" && f.format != "csv" {
+		return nil, fmt.Errorf("unsupported format %q", f.format)
+	}
+	if f.quiet {
+		log.SetOutput(io.Discard)
+	}
+	_, stats, err := scrapeGotSport(context.Background(), f.event, f.club, scheduleFilter{}, false)
+	if stats == nil {
+		return nil, err
+	}
+	return stats.Results, nil
+}
+
+// write prints v as JSON, or header and rows as CSV, to stdout or --out.
+func (f *cliResultsFlags) write(v any, header []string, rows [][]string) error {
+	var out io.Writer = os.Stdout
+	if f.out != "" {
+		file, err := os.Create(f.out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	if f.format == "csv" {
+		cw := csv.NewWriter(out)
+		_ = cw.Write(header)
+		return cw.WriteAll(rows)
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func cliResults(args []string) error {
+	var f cliResultsFlags
+	fs := flag.NewFlagSet("results", flag.ContinueOnError)
+	f.register(fs)
+	team := fs.String("team", "", "keep results where either side's name contains this")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	results, err := f.scrape()
+	if err != nil {
+		return err
+	}
+	results = filterResults(results, f.division, *team)
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{r.Date, r.HomeTeam, r.AwayTeam, r.Result, r.Division})
+	}
+	return f.write(results, []string{"date", "home", "away", "result", "division"}, rows)
+}
+
+func cliStandings(args []string) error {
+	var f cliResultsFlags
+	fs := flag.NewFlagSet("standings", flag.ContinueOnError)
+	f.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	results, err := f.scrape()
+	if err != nil {
+		return err
+	}
+	tables := standingsFrom(results, f.division)
+	var rows [][]string
+	for _, d := range tables {
+		for _, t := range d.Teams {
+			rows = append(rows, []string{d.Division, strconv.Itoa(t.Position), t.Team, strconv.Itoa(t.Played),
+				strconv.Itoa(t.Won), strconv.Itoa(t.Drawn), strconv.Itoa(t.Lost),
+				strconv.Itoa(t.GoalsFor), strconv.Itoa(t.GoalsAgainst), strconv.Itoa(t.Points)})
+		}
+	}
+	return f.write(tables, []string{"division", "position", "team", "played", "won", "drawn", "lost", "goalsFor", "goalsAgainst", "points"}, rows)
+}
+
+// streamResponseWriter lets the HTTP output writers target a file or stdout.
+type streamResponseWriter struct {
+	w      io.Writer
+	header http.Header
+	err    error
+}
+
+func (s *streamResponseWriter) Header() http.Header { return s.header }
+func (s *streamResponseWriter) WriteHeader(int)     {}
+
+func (s *streamResponseWriter) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	if err != nil && s.err == nil {
+		s.err = err
+	}
+	return n, err
+}
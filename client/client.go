@@ -0,0 +1,416 @@
+// Package client is a Go client for the GotSport parser API.
+//
+//	c := client.New("https://gotsport-api.example.com")
+//	res, err := c.Schedule(ctx, "44145", "12893", nil)
+//	for _, g := range res.Games { ... }
+//
+// Results and Standings read the final scores the server has recorded from
+// its scrapes of an event.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Game mirrors the API's game object.
+type Game struct {
+	ID       string `json:"id"` // stable across scrapes; keys admin annotations
+	HomeTeam string `json:"homeTeam"`
+	AwayTeam string `json:"awayTeam"`
+	// HomeTeamID and AwayTeamID are GotSport's team IDs, when known.
+	HomeTeamID string `json:"homeTeamId,omitempty"`
+	AwayTeamID string `json:"awayTeamId,omitempty"`
+	// HomeOrAway is "home" or "away" for the club's side.
+	HomeOrAway string `json:"homeOrAway,omitempty"`
+	Date       string `json:"date"`
+	Time       string `json:"time"`
+	// EndTime and DurationMinutes estimate the game's end from its age group.
+	EndTime         string `json:"endTime,omitempty"`
+	DurationMinutes int    `json:"durationMinutes,omitempty"`
+	Location        string `json:"location"`
+	Division        string `json:"division"`
+	Competition     string `json:"competition"`
+	OpponentClub    string `json:"opponentClub"`
+	SourceURL       string `json:"sourceUrl,omitempty"` // the game's own GotSport page, when linked
+	Address         string `json:"address"`
+	AtHome          bool   `json:"atHome"` // at one of the club's configured home venues
+	IsPast          bool   `json:"isPast"`
+	// KickoffInMinutes and KickoffIn are set when ScheduleOptions.Countdown is.
+	KickoffInMinutes *int     `json:"kickoffInMinutes,omitempty"`
+	KickoffIn        string   `json:"kickoffIn,omitempty"`
+	Source           string   `json:"source,omitempty"` // "manual" for games the club added by hand
+	Events           []string `json:"events,omitempty"` // set when several events are merged
+	// Status, ManualFields and ManualNote come from an admin override of
+	// the scraped data; ManualFields lists the fields it changed.
+	Status       string   `json:"status,omitempty"`
+	ManualFields []string `json:"manualFields,omitempty"`
+	ManualNote   string   `json:"manualNote,omitempty"`
+	// Annotations are club notes by label, set when ScheduleOptions.Annotations is.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Flags are scheduling problems the server found in the game.
+	Flags []Flag `json:"flags,omitempty"`
+	// Logistics are the venue's arrival notes, when the server has any.
+	Logistics *Logistics `json:"logistics,omitempty"`
+	// KitColor is the kit the club's team wears, when the server knows it.
+	KitColor string `json:"kitColor,omitempty"`
+}
+
+// Logistics is what parents need to know on arrival at a venue.
+type Logistics struct {
+	Parking       string `json:"parking,omitempty"`
+	Entry         string `json:"entry,omitempty"`
+	SpectatorSide string `json:"spectatorSide,omitempty"`
+}
+
+// Flag is one scheduling problem with a game, such as a blackout date.
+type Flag struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Result is a final score the server has recorded.
+type Result struct {
+	ID       string `json:"id"` // the game's ID, as in Game.ID
+	Date     string `json:"date"`
+	HomeTeam string `json:"homeTeam"`
+	AwayTeam string `json:"awayTeam"`
+	Result   string `json:"result"` // as the page prints it, e.g. "3 - 1"
+	Division string `json:"division"`
+}
+
+// Standing is one team's line in a division table.
+type Standing struct {
+	Position     int    `json:"position"`
+	Team         string `json:"team"`
+	Played       int    `json:"played"`
+	Won          int    `json:"won"`
+	Drawn        int    `json:"drawn"`
+	Lost         int    `json:"lost"`
+	GoalsFor     int    `json:"goalsFor"`
+	GoalsAgainst int    `json:"goalsAgainst"`
+	Points       int    `json:"points"`
+}
+
+// DivisionStandings is one division's table, worked out by the server from
+// the results it has recorded rather than taken from the league.
+type DivisionStandings struct {
+	Division string     `json:"division"`
+	Teams    []Standing `json:"teams"`
+}
+
+// Codes in APIError.Code that say why a schedule could not be served.
+const (
+	CodeInvalidParameters = "invalid_parameters"
+	CodeUpstreamBlocked   = "upstream_blocked" // GotSport refused the scrape
+	CodeUpstreamTimeout   = "upstream_timeout"
+	CodeParseEmpty        = "parse_empty" // the page had no games the server could read
+	CodeScrapeFailed      = "scrape_failed"
+)
+
+// APIError is a non-2xx response from the API.
+type APIError struct {
+	StatusCode int
+	Code       string // machine-readable error field, e.g. "scrape_failed"
+	Detail     string
+	RetryAfter time.Duration
+	Warnings   []Warning // rows the parser could not read; set on strict-mode 422s
+}
+
+// Warning describes a schedule row that matched the club but could not be
+// turned into a game.
+type Warning struct {
+	EventID  string `json:"eventid,omitempty"`
+	Row      int    `json:"row"`
+	Strategy string `json:"strategy,omitempty"`
+	MatchID  string `json:"matchId,omitempty"`
+	Field    string `json:"field"`
+	Value    string `json:"value"`
+	Message  string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("gotsport-api: HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("gotsport-api: HTTP %d %s: %s", e.StatusCode, e.Code, e.Detail)
+}
+
+// temporary reports whether retrying the request may succeed.
+func (e *APIError) temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Client calls the API. The zero value is not usable; use New.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	userAgent  string
+	apiKey     string
+	retries    int
+	backoff    time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient replaces the default http.Client (60s timeout; scrapes are slow).
+func WithHTTPClient(hc *http.Client) Option { return func(c *Client) { c.httpClient = hc } }
+
+// WithRetries sets how many times a failed request is retried (default 2).
+// Only network errors, 429 and 5xx responses are retried.
+func WithRetries(n int) Option { return func(c *Client) { c.retries = n } }
+
+// WithBackoff sets the initial retry delay, doubled on each attempt (default 1s).
+// A Retry-After header from the server takes precedence.
+func WithBackoff(d time.Duration) Option { return func(c *Client) { c.backoff = d } }
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option { return func(c *Client) { c.userAgent = ua } }
+
+// WithAPIKey sends key in X-API-Key so requests count against its quota.
+func WithAPIKey(key string) Option { return func(c *Client) { c.apiKey = key } }
+
+// New returns a Client for the API at baseURL.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		userAgent:  "gotsport-api-go-client/1.0",
+		retries:    2,
+		backoff:    time.Second,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// ScheduleOptions tune a Schedule call. A nil *ScheduleOptions uses defaults.
+type ScheduleOptions struct {
+	// Refresh forces a live scrape instead of a cached result.
+	Refresh bool
+	// MaxAge accepts cached data up to this age; zero means the server's TTL.
+	MaxAge time.Duration
+	// UpcomingOnly, when non-nil, overrides the server's UPCOMING_ONLY default.
+	UpcomingOnly *bool
+	// From and To (2006-01-02, inclusive) ask for a date range instead of
+	// next weekend. Division keeps games whose division contains it.
+	From, To string
+	Division string
+	// Team is a GotSport team ID; the server scrapes that team's page.
+	Team string
+	// Venue is a GotSport field ID, scraping that venue's page, or a
+	// location name to filter on.
+	Venue string
+	// TZ is an IANA zone to convert dates and times to, e.g. "America/New_York".
+	TZ string
+	// Countdown asks for KickoffInMinutes and KickoffIn on each game.
+	Countdown bool
+	// HomeVenueOnly keeps games at the club's home venues; the server must
+	// have HOME_VENUES configured.
+	HomeVenueOnly bool
+	// Strict fails the call with a 422 *APIError carrying Warnings when any
+	// of the club's rows could not be parsed.
+	Strict bool
+	// Annotations asks for the club's admin notes on each game.
+	Annotations bool
+	// TeamSlug keeps one team's games, by its /teams/directory slug.
+	TeamSlug string
+	// TeamID keeps the games of the team with this GotSport team ID.
+	TeamID string
+	// Club asks for another club's games than the server's CLUB_NAME,
+	// matched within team names.
+	Club string
+	// Side is "away" or "all" to get the club's away games instead of, or
+	// as well as, its home games.
+	Side string
+}
+
+// ScheduleResult is a schedule plus the cache metadata the server reported.
+type ScheduleResult struct {
+	Games    []Game
+	Age      time.Duration // age of the data when served
+	CacheHit bool
+	// Tier names the source that served Games: fresh, revalidated, cache or
+	// persisted (the server's last stored scrape, used when a scrape fails
+	// with nothing cached).
+	Tier string
+	// Warnings counts club rows the server could not read; when non-zero
+	// Games may be incomplete.
+	Warnings int
+}
+
+// Schedule returns the games for an event and club.
+func (c *Client) Schedule(ctx context.Context, eventID, clubID string, opts *ScheduleOptions) (*ScheduleResult, error) {
+	q := url.Values{"eventid": {eventID}, "clubid": {clubID}}
+	if opts != nil {
+		if opts.Refresh {
+			q.Set("refresh", "true")
+		}
+		if opts.MaxAge > 0 {
+			q.Set("maxAge", strconv.Itoa(int(opts.MaxAge.Seconds())))
+		}
+		if opts.Countdown {
+			q.Set("countdown", "true")
+		}
+		if opts.HomeVenueOnly {
+			q.Set("homeVenueOnly", "true")
+		}
+		if opts.Strict {
+			q.Set("strict", "true")
+		}
+		if opts.Annotations {
+			q.Set("annotations", "true")
+		}
+		if opts.UpcomingOnly != nil {
+			q.Set("upcomingOnly", strconv.FormatBool(*opts.UpcomingOnly))
+		}
+		for k, v := range map[string]string{"from": opts.From, "to": opts.To, "division": opts.Division, "team": opts.Team, "venue": opts.Venue, "tz": opts.TZ, "teamSlug": opts.TeamSlug, "teamId": opts.TeamID, "club": opts.Club, "side": opts.Side} {
+			if v != "" {
+				q.Set(k, v)
+			}
+		}
+	}
+	var res ScheduleResult
+	hdr, err := c.get(ctx, "/schedule", q, &res.Games)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := strconv.Atoi(hdr.Get("Age")); err == nil {
+		res.Age = time.Duration(n) * time.Second
+	}
+	res.CacheHit = hdr.Get("X-Cache") == "HIT"
+	res.Tier = hdr.Get("X-Data-Tier")
+	res.Warnings, _ = strconv.Atoi(hdr.Get("X-Parse-Warnings"))
+	return &res, nil
+}
+
+// ResultsOptions narrow a Results call. A nil *ResultsOptions returns every
+// result recorded for the event.
+type ResultsOptions struct {
+	// Division and Team keep results whose division, or either side,
+	// contains them, ignoring case.
+	Division string
+	Team     string
+}
+
+// Results returns the final scores recorded for an event, newest first.
+func (c *Client) Results(ctx context.Context, eventID string, opts *ResultsOptions) ([]Result, error) {
+	q := url.Values{"eventid": {eventID}}
+	if opts != nil {
+		for k, v := range map[string]string{"division": opts.Division, "team": opts.Team} {
+			if v != "" {
+				q.Set(k, v)
+			}
+		}
+	}
+	var out []Result
+	_, err := c.get(ctx, "/results", q, &out)
+	return out, err
+}
+
+// Standings returns the division tables for an event, or only those whose
+// name contains division when it is set.
+func (c *Client) Standings(ctx context.Context, eventID, division string) ([]DivisionStandings, error) {
+	q := url.Values{"eventid": {eventID}}
+	if division != "" {
+		q.Set("division", division)
+	}
+	var out []DivisionStandings
+	_, err := c.get(ctx, "/standings", q, &out)
+	return out, err
+}
+
+// Health returns the server's /health document.
+func (c *Client) Health(ctx context.Context) (map[string]string, error) {
+	var out map[string]string
+	_, err := c.get(ctx, "/health", nil, &out)
+	return out, err
+}
+
+// get performs a GET with retries and decodes the JSON body into out.
+func (c *Client) get(ctx context.Context, path string, q url.Values, out any) (http.Header, error) {
+	u := c.baseURL + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	delay := c.backoff
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			wait := delay
+			var apiErr *APIError
+			if errors.As(lastErr, &apiErr) && apiErr.RetryAfter > 0 {
+				wait = apiErr.RetryAfter
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+			delay *= 2
+		}
+		hdr, err := c.do(ctx, u, out)
+		if err == nil {
+			return hdr, nil
+		}
+		lastErr = err
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && !apiErr.temporary() {
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, lastErr
+}
+
+func (c *Client) do(ctx context.Context, u string, out any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var e struct {
+			Error    string    `json:"error"`
+			Detail   string    `json:"detail"`
+			Warnings []Warning `json:"warnings"`
+		}
+		if json.Unmarshal(body, &e) == nil {
+			apiErr.Code, apiErr.Detail, apiErr.Warnings = e.Error, e.Detail, e.Warnings
+		}
+		if n, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(n) * time.Second
+		}
+		return nil, apiErr
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return nil, fmt.Errorf("gotsport-api: decode %s: %v", u, err)
+	}
+	return resp.Header, nil
+}
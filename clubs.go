@@ -0,0 +1,69 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+/* ---------- Opponent clubs ---------- */
+
+// builtinClubAliases covers common short forms seen on NorCal schedules;
+// CLUB_ALIASES entries take precedence.
+var builtinClubAliases = map[string]string{
+	"sac united": "Sacramento United",
+	"srfc":       "Sacramento Republic FC",
+}
+
+// teamSuffix matches one trailing token that names a team within a club
+// rather than the club itself: birth years, age groups, gender and level.
+var teamSuffix = regexp.MustCompile(`(?i)^(?:` +
+	`[bg]?(?:19|20)?\d{2}[bg]?|u-?\d{1,2}|\d{1,2}u|` +
+	`boys|girls|men|women|` +
+	`ecnl|ecrl|ecnl-rl|rl|npl|mls|next|dpl|pre-ecnl|pre|academy|elite|premier|select|` +
+	`gold|silver|bronze|red|white|blue|black|i|ii|iii|\(\w\))$`)
+
+// opponentClub reduces a team name such as "Sacramento United 2012B Premier"
+// to its club, "Sacramento United", resolving aliases on the way.
+func opponentClub(team string) string {
+	team = strings.TrimSpace(team)
+	if club, ok := lookupClubAlias(team); ok {
+		return club
+	}
+	words := strings.Fields(team)
+	for len(words) > 1 && teamSuffix.MatchString(words[len(words)-1]) {
+		words = words[:len(words)-1]
+	}
+	club := strings.Join(words, " ")
+	if alias, ok := lookupClubAlias(club); ok {
+		return alias
+	}
+	return club
+}
+
+func lookupClubAlias(name string) (string, bool) {
+	key := strings.ToLower(name)
+	if club, ok := appConfig.ClubAliases[key]; ok {
+		return club, true
+	}
+	club, ok := builtinClubAliases[key]
+	return club, ok
+}
+
+// isClubTeam reports whether team belongs to club, whose name it contains
+// ignoring case. An empty club matches nothing.
+func isClubTeam(team, club string) bool {
+	club = strings.TrimSpace(club)
+	return club != "" && strings.Contains(strings.ToLower(team), strings.ToLower(club))
+}
+
+// homeOrAway says which side of a fixture is club's, preferring home when
+// both are (intra-club games), or "" when neither is.
+func homeOrAway(home, away, club string) string {
+	switch {
+	case isClubTeam(home, club):
+		return sideHome
+	case isClubTeam(away, club):
+		return sideAway
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* ---------- Coach conflicts ---------- */
+
+// parseCoaches reads COACHES, ";"-separated entries of the form
+// coach=team,team, e.g. "Jane Doe=2012B,2013G;Sam Lee=2011B". Teams match
+// within club team names, ignoring case.
+func parseCoaches(s string) map[string][]string {
+	coaches := map[string][]string{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		coach, list, ok := strings.Cut(part, "=")
+		coach = strings.TrimSpace(coach)
+		var teams []string
+		for _, t := range strings.Split(list, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				teams = append(teams, t)
+			}
+		}
+		if !ok || coach == "" || len(teams) == 0 {
+			log.Printf("ignoring malformed COACHES entry %q (want Coach=team,team)", part)
+			continue
+		}
+		coaches[coach] = append(coaches[coach], teams...)
+	}
+	return coaches
+}
+
+// coachGame is one game in a coach conflict, with the coach's team in it.
+type coachGame struct {
+	Team     string `json:"team"`
+	HomeTeam string `json:"homeTeam"`
+	AwayTeam string `json:"awayTeam"`
+	Date     string `json:"date"`
+	Time     string `json:"time"`
+	EndTime  string `json:"endTime,omitempty"`
+	Location string `json:"location"`
+
+	start, end time.Time
+}
+
+// coachConflict is two of a coach's games that are on at the same time.
+// SameVenue is set when they are at one complex, so the coach can at least
+// move between fields.
+type coachConflict struct {
+	Date      string    `json:"date"`
+	First     coachGame `json:"first"`
+	Second    coachGame `json:"second"`
+	SameVenue bool      `json:"sameVenue"`
+}
+
+// coachReport is one coach's upcoming games and their clashes.
+type coachReport struct {
+	Coach     string          `json:"coach"`
+	Teams     []string        `json:"teams"`
+	Games     int             `json:"games"`
+	Conflicts []coachConflict `json:"conflicts"`
+}
+
+// coachConflicts is what /conflicts/coaches returns.
+type coachConflicts struct {
+	ClubID    string        `json:"clubid"`
+	Events    []string      `json:"events"`
+	Conflicts int           `json:"conflicts"`
+	Coaches   []coachReport `json:"coaches"`
+}
+
+// coachTeam returns the first of teams contained in team, or "".
+func coachTeam(team string, teams []string) string {
+	for _, t := range teams {
+		if containsFold(team, t) {
+			return t
+		}
+	}
+	return ""
+}
+
+// findCoachConflicts pairs up the coach's games whose kickoff falls before
+// another's end. Games are the club's, in any order; a fixture between two
+// of the coach's teams counts once.
+func findCoachConflicts(coach string, teams []string, games []Game) coachReport {
+	rep := coachReport{Coach: coach, Teams: teams, Conflicts: []coachConflict{}}
+	var mine []coachGame
+	seen := map[string]bool{}
+	for _, g := range games {
+		side := clubSide(g)
+		if side == "" || coachTeam(side, teams) == "" || g.Status == "cancelled" || g.Status == "postponed" {
+			continue
+		}
+		start, ok := gameKickoff(g)
+		if !ok || seen[fixtureKickoffKey(g)] {
+			continue
+		}
+		seen[fixtureKickoffKey(g)] = true
+		mine = append(mine, coachGame{Team: side, HomeTeam: g.HomeTeam, AwayTeam: g.AwayTeam, Date: g.Date, Time: g.Time,
+			EndTime: g.EndTime, Location: g.Location, start: start, end: start.Add(gameDuration(g, start))})
+	}
+	sort.SliceStable(mine, func(i, j int) bool { return mine[i].start.Before(mine[j].start) })
+	rep.Games = len(mine)
+	for i := range mine {
+		for j := i + 1; j < len(mine) && mine[j].start.Before(mine[i].end); j++ {
+			rep.Conflicts = append(rep.Conflicts, coachConflict{
+				Date: mine[i].Date, First: mine[i], Second: mine[j],
+				SameVenue: strings.EqualFold(venueName(mine[i].Location), venueName(mine[j].Location)),
+			})
+		}
+	}
+	return rep
+}
+
+// coachConflictsHandler serves /conflicts/coaches?clubid=[&eventid=][&coach=]:
+// for each coach in COACHES, their teams' upcoming games that overlap one
+// another. eventid (comma-separated) defaults to the configured events for
+// the club; coach narrows to coaches whose name contains it.
+func (s *Server) coachConflictsHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	clubID := strings.TrimSpace(q.Get("clubid"))
+	if clubID == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "clubid is required"})
+		return
+	}
+	if len(appConfig.Coaches) == 0 {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "No coaches are configured; set COACHES"})
+		return
+	}
+	events := clubEvents(q.Get("eventid"), clubID)
+	if len(events) == 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse(badParamsf("no events are configured for club %s; pass eventid", clubID)))
+		return
+	}
+
+	// AllSides keeps every upcoming weekend, not just the next.
+	f := scheduleFilter{AllSides: true}
+	today := s.clock.Now().In(eventLocation()).Format("2006-01-02")
+	var games []Game
+	for _, eventID := range events {
+		res, err := s.getSchedule(r.Context(), eventID, clubID, f, false, s.cache.maxAge())
+		if err != nil {
+			writeJSON(w, http.StatusBadGateway, errorResponse(err))
+			return
+		}
+		for _, g := range res.Games {
+			if g.Date >= today {
+				games = append(games, g)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(appConfig.Coaches))
+	for name := range appConfig.Coaches {
+		if containsFold(name, q.Get("coach")) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	out := coachConflicts{ClubID: clubID, Events: events, Coaches: []coachReport{}}
+	for _, name := range names {
+		rep := findCoachConflicts(name, appConfig.Coaches[name], games)
+		out.Conflicts += len(rep.Conflicts)
+		out.Coaches = append(out.Coaches, rep)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
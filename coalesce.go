@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+/* ---------- Request coalescing ---------- */
+
+// flightGroup shares one scrape between identical concurrent callers, and
+// keeps a successful result around for a short window afterwards so a burst
+// of refresh=true pollers arriving just after a scrape reuse it.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done  chan struct{}
+	games []Game
+	tier  dataTier
+	err   error
+}
+
+// do runs fn once per key; callers arriving while it runs, or within window of
+// a successful finish, get the same result.
+func (g *flightGroup) do(key string, window time.Duration, fn func() ([]Game, dataTier, error)) ([]Game, dataTier, error) {
+	g.mu.Lock()
+	if f, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-f.done
+		return append([]Game(nil), f.games...), f.tier, f.err
+	}
+	f := &flight{done: make(chan struct{})}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	func() {
+		// A panicking parser fails this scrape, not every caller waiting on it.
+		defer func() {
+			if p := recover(); p != nil {
+				logPanic("scrape", "scrape "+key, p)
+				f.games, f.tier, f.err = nil, tierFresh, fmt.Errorf("scrape panicked: %v", p)
+			}
+		}()
+		f.games, f.tier, f.err = fn()
+	}()
+	close(f.done)
+
+	if f.err != nil || window <= 0 {
+		g.forget(key, f)
+	} else {
+		time.AfterFunc(window, func() { g.forget(key, f) })
+	}
+	return append([]Game(nil), f.games...), f.tier, f.err
+}
+
+func (g *flightGroup) forget(key string, f *flight) {
+	g.mu.Lock()
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+}
+
+// scrapeTimeout bounds a shared scrape, including time spent queued for a
+// fetch slot.
+const scrapeTimeout = 90 * time.Second
+
+// detachedScrapeContext lets a shared scrape outlive the request that started
+// it; other callers may still be waiting on the result.
+func detachedScrapeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), scrapeTimeout)
+}
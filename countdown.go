@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+/* ---------- Countdown fields ---------- */
+
+// withCountdown sets KickoffInMinutes and KickoffIn on games with a
+// parseable kickoff, relative to now. Day-level phrases ("tomorrow",
+// "in 3 days") count calendar days in loc, the zone the response is in.
+// It edits games in place and, like markPast, must run before inTimezone.
+func withCountdown(games []Game, now time.Time, loc *time.Location) []Game {
+	for i, g := range games {
+		start, ok := gameKickoff(g)
+		if !ok {
+			continue
+		}
+		mins := int(math.Floor(start.Sub(now).Minutes()))
+		games[i].KickoffInMinutes = &mins
+		games[i].KickoffIn = relativeTime(start, now, loc)
+	}
+	return games
+}
+
+// relativeTime phrases t from now's point of view: "in 45 minutes",
+// "in 3 hours", "tomorrow", "in 2 days", "20 minutes ago", "yesterday".
+func relativeTime(t, now time.Time, loc *time.Location) string {
+	d := t.Sub(now)
+	future := d >= 0
+	if !future {
+		d = -d
+	}
+	var amount string
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		amount = plural(int(d/time.Minute), "minute")
+	case d < 12*time.Hour || calendarDays(now, t, loc) == 0:
+		amount = plural(int(d/time.Hour), "hour")
+	default:
+		days := calendarDays(now, t, loc)
+		switch days {
+		case 1:
+			return "tomorrow"
+		case -1:
+			return "yesterday"
+		}
+		if days < 0 {
+			days = -days
+		}
+		amount = plural(days, "day")
+	}
+	if future {
+		return "in " + amount
+	}
+	return amount + " ago"
+}
+
+// calendarDays is the number of midnights in loc between from and to,
+// negative when to is earlier.
+func calendarDays(from, to time.Time, loc *time.Location) int {
+	day := func(t time.Time) time.Time {
+		y, m, d := t.In(loc).Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	return int(day(to).Sub(day(from)).Hours() / 24)
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
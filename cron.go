@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ---------- Scheduled scraping ---------- */
+
+const jobKindScrapeCron = "scrape-cron"
+
+// cronMacros are the shorthands SCRAPE_CRON accepts besides five fields.
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+}
+
+// cronSpec is a parsed five-field cron expression, minute hour
+// day-of-month month day-of-week, read in event time.
+type cronSpec struct {
+	expr                     string
+	minute, hour, dom, month map[int]bool
+	dow                      map[int]bool
+	domAny, dowAny           bool // the field was *, so only the other day field counts
+}
+
+// parseCron reads a cron expression: five fields of *, numbers, ranges
+// (1-5), lists (0,30) and steps (*/15, 8-18/2), or one of cronMacros.
+func parseCron(expr string) (*cronSpec, error) {
+	expr = strings.TrimSpace(expr)
+	fields := strings.Fields(expr)
+	if m, ok := cronMacros[strings.ToLower(expr)]; ok {
+		fields = strings.Fields(m)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("want five fields (minute hour day month weekday), got %d", len(fields))
+	}
+	c := &cronSpec{expr: expr, domAny: fields[2] == "*", dowAny: fields[4] == "*"}
+	var err error
+	for i, f := range []struct {
+		set      *map[int]bool
+		min, max int
+	}{{&c.minute, 0, 59}, {&c.hour, 0, 23}, {&c.dom, 1, 31}, {&c.month, 1, 12}, {&c.dow, 0, 7}} {
+		if *f.set, err = cronField(fields[i], f.min, f.max); err != nil {
+			return nil, fmt.Errorf("field %d (%q): %v", i+1, fields[i], err)
+		}
+	}
+	if c.dow[7] {
+		c.dow[0] = true // 7 is Sunday too
+	}
+	return c, nil
+}
+
+// cronField expands one field into the values it allows.
+func cronField(field string, min, max int) (map[int]bool, error) {
+	set := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if r, s, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("bad step %q", s)
+			}
+			rng, step = r, n
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(a)
+			hi, err2 = lo, nil
+			if isRange {
+				hi, err2 = strconv.Atoi(b)
+			} else if step > 1 {
+				hi = max // "5/15" runs from 5 on
+			}
+			if err1 != nil || err2 != nil || lo < min || hi > max || lo > hi {
+				return nil, fmt.Errorf("bad value %q (want %d-%d)", rng, min, max)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// matches reports whether t (in event time) is one of c's minutes. As in
+// cron, a restricted day of month and day of week match either.
+func (c *cronSpec) matches(t time.Time) bool {
+	if !c.minute[t.Minute()] || !c.hour[t.Hour()] || !c.month[int(t.Month())] {
+		return false
+	}
+	dom, dow := c.dom[t.Day()], c.dow[int(t.Weekday())]
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// next returns the first minute after t that c matches, or the zero time
+// if none falls within a year (an expression such as "0 0 31 2 *").
+func (c *cronSpec) next(t time.Time) time.Time {
+	t = t.In(eventLocation()).Truncate(time.Minute).Add(time.Minute)
+	for end := t.AddDate(1, 0, 0); t.Before(end); t = t.Add(time.Minute) {
+		if c.matches(t) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func scrapeCronFromEnv() *cronSpec {
+	v := stringFromEnv("SCRAPE_CRON", "")
+	if v == "" {
+		return nil
+	}
+	c, err := parseCron(v)
+	if err != nil {
+		log.Printf("invalid SCRAPE_CRON=%q (%v); scraping on request instead", v, err)
+		return nil
+	}
+	return c
+}
+
+func init() {
+	registerJobHandler(jobKindScrapeCron, func(ctx context.Context, _ json.RawMessage) error {
+		defer scheduleScrapeCron(appConfig.ScrapeCron.next(time.Now()))
+		// The scrape jobs retry on their own; this pass only queues them.
+		warmCache(appConfig.Events, appConfig.WarmInterval)
+		return nil
+	})
+}
+
+// scheduleScrapeCron queues the background refresh at at. Like reminders,
+// each pass queues the next, and the dedupe key is per run.
+func scheduleScrapeCron(at time.Time) {
+	if at.IsZero() {
+		log.Printf("scrape cron: %q never runs again", appConfig.ScrapeCron.expr)
+		return
+	}
+	key := jobKindScrapeCron + "@" + at.UTC().Format(time.RFC3339)
+	if err := enqueueJob(jobKindScrapeCron, struct{}{}, jobOptions{DedupeKey: key, RunAt: at, MaxAttempts: 1}); err != nil {
+		log.Printf("scrape cron: %v", err)
+	}
+}
+
+// cronRefreshed reports whether SCRAPE_CRON keeps the schedule for
+// eventID/clubID fresh, so requests serve what it last scraped instead of
+// scraping themselves.
+func cronRefreshed(eventID, clubID string) bool {
+	return appConfig.ScrapeCron != nil && containsRef(appConfig.Events, eventRef{EventID: eventID, ClubID: clubID})
+}
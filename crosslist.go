@@ -0,0 +1,135 @@
+package main
+
+import (
+	"log"
+	"regexp"
+	"strings"
+)
+
+/* ---------- Cross-listed teams ---------- */
+
+// parseTeamAliases reads TEAM_ALIASES, ";"-separated entries of the form
+// name=alias,alias, e.g. "Reno Apex 2012B Elite=Reno Apex 12B ECNL,Reno
+// Apex B2012". Keys are lower-cased aliases.
+func parseTeamAliases(s string) map[string]string {
+	aliases := map[string]string{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, list, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.TrimSpace(list) == "" {
+			log.Printf("ignoring malformed TEAM_ALIASES entry %q (want Team=alias,alias)", part)
+			continue
+		}
+		for _, alias := range strings.Split(list, ",") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				aliases[strings.ToLower(alias)] = name
+			}
+		}
+	}
+	return aliases
+}
+
+// teamBirthYear finds a birth year and gender in a team name: "2012B",
+// "12B", "B2012", "B12" or "2012 Boys".
+var teamBirthYear = regexp.MustCompile(`(?i)\b(?:(?:20)?(\d{2})\s?([bg])|([bg])(?:20)?(\d{2})|20(\d{2})\s+(boys|girls))\b`)
+
+// teamIdentity reduces a team name to its club, birth year and gender,
+// "reno apex|2012|b" for both "Reno Apex 2012B Elite" and "Reno Apex B12
+// ECNL", or false when the name has no birth year.
+func teamIdentity(name string) (string, bool) {
+	name = normalizeText(name)
+	m := teamBirthYear.FindStringSubmatchIndex(name)
+	if m == nil {
+		return "", false
+	}
+	sub := func(i int) string {
+		if m[2*i] < 0 {
+			return ""
+		}
+		return name[m[2*i]:m[2*i+1]]
+	}
+	year := firstNonEmpty(sub(1), sub(4), sub(5))
+	gender := strings.ToLower(firstNonEmpty(sub(2), sub(3), sub(6))[:1])
+	club := strings.TrimSpace(slugUnsafe.ReplaceAllString(strings.ToLower(name[:m[0]]), " "))
+	if club == "" {
+		return "", false
+	}
+	return club + "|20" + year + "|" + gender, true
+}
+
+// teamLinks maps lower-cased team names to the name of the team they are
+// listed as elsewhere. Names it lacks stand for themselves.
+type teamLinks map[string]string
+
+func (l teamLinks) canonical(name string) string {
+	if c, ok := l[strings.ToLower(name)]; ok {
+		return c
+	}
+	return name
+}
+
+// apply renames g's sides to their canonical names.
+func (l teamLinks) apply(g Game) Game {
+	g.HomeTeam, g.AwayTeam = l.canonical(g.HomeTeam), l.canonical(g.AwayTeam)
+	return g
+}
+
+// linkTeams works out which club teams in sets are one team listed under
+// different names. TEAM_ALIASES entries link first; then club teams in
+// different events whose club, birth year and gender agree are linked to
+// the name in the earliest set, unless some one event lists two such
+// teams, which are then told apart only by level and left alone.
+func linkTeams(sets []eventGames) teamLinks {
+	links := teamLinks{}
+	for alias, name := range appConfig.TeamAliases {
+		links[alias] = name
+	}
+	type candidate struct {
+		names  []string            // in order of first appearance
+		events map[string][]string // event -> names it lists
+	}
+	groups := map[string]*candidate{}
+	var order []string
+	for _, set := range sets {
+		for _, g := range set.Games {
+			name := links.canonical(clubSide(g))
+			key, ok := teamIdentity(name)
+			if !ok {
+				continue
+			}
+			c := groups[key]
+			if c == nil {
+				c = &candidate{events: map[string][]string{}}
+				groups[key] = c
+				order = append(order, key)
+			}
+			if !containsString(c.names, name) {
+				c.names = append(c.names, name)
+			}
+			if !containsString(c.events[set.EventID], name) {
+				c.events[set.EventID] = append(c.events[set.EventID], name)
+			}
+		}
+	}
+	for _, key := range order {
+		c := groups[key]
+		if len(c.names) < 2 {
+			continue
+		}
+		ambiguous := false
+		for _, names := range c.events {
+			ambiguous = ambiguous || len(names) > 1
+		}
+		if ambiguous {
+			continue
+		}
+		for _, name := range c.names[1:] {
+			links[strings.ToLower(name)] = c.names[0]
+		}
+	}
+	return links
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+)
+
+/* ---------- CSV output ---------- */
+
+var csvHeader = []string{"date", "time", "endTime", "homeTeam", "awayTeam", "location", "division", "competition", "opponentClub", "address", "atHome", "isPast", "status"}
+
+func writeCSV(w http.ResponseWriter, games []Game) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="schedule.csv"`)
+	w.WriteHeader(http.StatusOK)
+	cw := csv.NewWriter(w)
+	_ = cw.Write(csvHeader)
+	for _, g := range games {
+		_ = cw.Write([]string{g.Date, g.Time, g.EndTime, g.HomeTeam, g.AwayTeam, g.Location, g.Division, g.Competition, g.OpponentClub, g.Address, strconv.FormatBool(g.AtHome), strconv.FormatBool(g.IsPast), g.Status})
+	}
+	cw.Flush()
+}
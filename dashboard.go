@@ -0,0 +1,254 @@
+package main
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/* ---------- Admin dashboard ---------- */
+
+var dashboardTmpl = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"ago": func(t time.Time) string {
+		if t.IsZero() {
+			return "never"
+		}
+		return time.Since(t).Round(time.Second).String() + " ago"
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>GotSport API admin</title>
+<style>
+body{margin:0 auto;max-width:1100px;padding:16px;color:#1b1f24;font:14px/1.4 system-ui,-apple-system,Segoe UI,Roboto,sans-serif}
+h1{font-size:20px}h2{font-size:16px;margin-top:28px}
+table{width:100%;border-collapse:collapse}
+th,td{padding:5px 8px;border-bottom:1px solid #e3e6ea;text-align:left;vertical-align:top}
+th{color:#5b636d;font-weight:600;font-size:12px;text-transform:uppercase}
+.bad{color:#b3261e;font-weight:600}.ok{color:#1e7b34}.muted{color:#5b636d}
+pre{margin:0;white-space:pre-wrap;font-size:12px}
+button{font:inherit;padding:2px 10px}
+</style></head>
+<body>
+<h1>GotSport API admin</h1>
+{{with .Flash}}<p class="ok">{{.}}</p>{{end}}
+
+<h2>Events</h2>
+<table>
+<thead><tr><th>Event / club</th><th>Cached</th><th>Last scrape</th><th>Games</th><th>Status</th><th></th></tr></thead>
+<tbody>{{range .Events}}
+<tr><td>{{.EventID}} / {{.ClubID}}</td>
+<td>{{if .Cached}}{{ago .CachedAt}}{{if .Stale}} <span class="bad">stale</span>{{end}}{{else}}<span class="muted">not cached</span>{{end}}</td>
+<td>{{ago .Scrape.At}}{{if .Scrape.DurationMs}} <span class="muted">({{.Scrape.DurationMs}} ms)</span>{{end}}</td>
+<td>{{.Scrape.Games}}</td>
+<td>{{if .Scrape.Error}}<span class="bad">{{.Scrape.Error}}</span>{{else if not .Scrape.At.IsZero}}<span class="ok">ok</span>{{end}}</td>
+<td><form method="post" action="/admin/refresh"><input type="hidden" name="eventid" value="{{.EventID}}"><input type="hidden" name="clubid" value="{{.ClubID}}"><button>Refresh</button></form></td></tr>{{else}}
+<tr><td colspan="6" class="muted">No events configured or scraped yet (set EVENTS).</td></tr>{{end}}
+</tbody></table>
+
+<h2>Recent changes</h2>
+<table>
+<thead><tr><th>When</th><th>Event / club</th><th>Changes</th></tr></thead>
+<tbody>{{range .Changes}}
+<tr><td>{{ago .At}}</td><td>{{.EventID}} / {{.ClubID}}</td><td><pre>{{range .Lines}}{{.}}
+{{end}}</pre></td></tr>{{else}}
+<tr><td colspan="3" class="muted">No schedule changes since startup.</td></tr>{{end}}
+</tbody></table>
+
+<h2>Background jobs</h2>
+<p class="muted">Scrapes and outbound deliveries run through the job queue.</p>
+<table>
+<thead><tr><th>Kind</th><th>Status</th><th>Jobs</th></tr></thead>
+<tbody>{{range .JobCounts}}
+<tr><td>{{.Kind}}</td><td>{{if eq .Status "failed"}}<span class="bad">failed</span>{{else}}{{.Status}}{{end}}</td><td>{{.Count}}</td></tr>{{else}}
+<tr><td colspan="3" class="muted">No jobs.</td></tr>{{end}}
+</tbody></table>
+{{if .JobProblems}}<table>
+<thead><tr><th>Job</th><th>Kind</th><th>Status</th><th>Attempts</th><th>Last error</th><th>Updated</th></tr></thead>
+<tbody>{{range .JobProblems}}
+<tr><td>#{{.ID}}</td><td>{{.Kind}}</td><td>{{.Status}}</td><td>{{.Attempts}}</td><td class="bad">{{.LastError}}</td><td>{{ago .UpdatedAt}}</td></tr>{{end}}
+</tbody></table>{{end}}
+
+<h2>Upstreams</h2>
+<table>
+<thead><tr><th>Source</th><th>p50 / p90</th><th>Failure streak</th><th>Last success</th><th>Circuit</th></tr></thead>
+<tbody>{{range .Upstreams}}
+<tr><td>{{.Source}}</td><td>{{printf "%.0f" .P50Ms}} / {{printf "%.0f" .P90Ms}} ms</td><td>{{.Streak}}</td>
+<td>{{with .LastOK}}{{ago .}}{{else}}never{{end}}</td>
+<td>{{if .CircuitOpen}}<span class="bad">open</span>{{else}}closed{{end}}{{with .LastError}} <span class="muted">{{.}}</span>{{end}}</td></tr>{{else}}
+<tr><td colspan="5" class="muted">No upstream requests yet.</td></tr>{{end}}
+</tbody></table>
+</body></html>
+`))
+
+// dashboardEvent is one event row on the dashboard.
+type dashboardEvent struct {
+	eventRef
+	Cached   bool
+	CachedAt time.Time
+	Stale    bool
+	Scrape   scrapeStatus
+}
+
+type jobCount struct {
+	Kind, Status string
+	Count        int
+}
+
+type jobProblem struct {
+	ID           int64
+	Kind, Status string
+	Attempts     int
+	LastError    string
+	UpdatedAt    time.Time
+}
+
+// adminDashboardHandler serves the HTML dashboard at /admin/.
+func (s *Server) adminDashboardHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/admin/" {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "No admin page at " + r.URL.Path})
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	data := map[string]any{
+		"Flash":     r.URL.Query().Get("flash"),
+		"Events":    s.dashboardEvents(),
+		"Changes":   dashboardChanges(),
+		"Upstreams": upstreamSummaries(),
+	}
+	counts, problems, err := jobOverview()
+	if err != nil {
+		log.Printf("dashboard jobs: %v", err)
+	}
+	data["JobCounts"], data["JobProblems"] = counts, problems
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := dashboardTmpl.Execute(w, data); err != nil {
+		log.Printf("dashboard render: %v", err)
+	}
+}
+
+// dashboardEvents lists configured events plus any other event scraped
+// since startup.
+func (s *Server) dashboardEvents() []dashboardEvent {
+	scrapes := map[string]scrapeStatus{}
+	for _, s := range lastScrapes() {
+		scrapes[cacheKey(s.EventID, s.ClubID)] = s
+	}
+	refs := append([]eventRef(nil), appConfig.Events...)
+	for _, s := range lastScrapes() {
+		ref := eventRef{EventID: s.EventID, ClubID: s.ClubID}
+		if !containsRef(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+
+	out := make([]dashboardEvent, 0, len(refs))
+	for _, ref := range refs {
+		key := cacheKey(ref.EventID, ref.ClubID)
+		e := dashboardEvent{eventRef: ref, Scrape: scrapes[key]}
+		if _, at, ok := s.cache.peek(key); ok {
+			e.Cached, e.CachedAt = true, at
+			e.Stale = time.Since(at) > s.cache.maxAge()
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+type dashboardChange struct {
+	scheduleChange
+	Lines []string
+}
+
+func dashboardChanges() []dashboardChange {
+	changes := latestChanges()
+	out := make([]dashboardChange, len(changes))
+	for i, c := range changes {
+		out[i] = dashboardChange{scheduleChange: c, Lines: c.Diff.describe()}
+	}
+	return out
+}
+
+func containsRef(refs []eventRef, ref eventRef) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// jobOverview counts jobs by kind and status and lists failed or retrying ones.
+func jobOverview() ([]jobCount, []jobProblem, error) {
+	var counts []jobCount
+	rows, err := db.Query(`SELECT kind, status, COUNT(*) FROM jobs GROUP BY kind, status ORDER BY kind, status`)
+	if err != nil {
+		return nil, nil, err
+	}
+	for rows.Next() {
+		var c jobCount
+		if err := rows.Scan(&c.Kind, &c.Status, &c.Count); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		counts = append(counts, c)
+	}
+	rows.Close()
+
+	var problems []jobProblem
+	rows, err = db.Query(`SELECT id, kind, status, attempts, last_error, updated_at FROM jobs
+		WHERE last_error != '' AND status != ?
+		ORDER BY updated_at DESC LIMIT 20`, jobDone)
+	if err != nil {
+		return counts, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p jobProblem
+		var updated int64
+		if err := rows.Scan(&p.ID, &p.Kind, &p.Status, &p.Attempts, &p.LastError, &updated); err != nil {
+			return counts, problems, err
+		}
+		p.UpdatedAt = time.UnixMilli(updated)
+		problems = append(problems, p)
+	}
+	return counts, problems, rows.Err()
+}
+
+// adminRefreshHandler queues a live scrape of one event/club and returns to
+// the dashboard. POST form or query: eventid, clubid.
+func adminRefreshHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method_not_allowed", Detail: "Use POST"})
+		return
+	}
+	if !sameOrigin(r) {
+		writeJSON(w, http.StatusForbidden, ErrorResponse{Error: "cross_origin", Detail: "Admin actions must come from this host"})
+		return
+	}
+	ref := eventRef{EventID: strings.TrimSpace(r.FormValue("eventid")), ClubID: strings.TrimSpace(r.FormValue("clubid"))}
+	if ref.EventID == "" || ref.ClubID == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "eventid and clubid are required"})
+		return
+	}
+	if err := enqueueJob(jobKindScrape, ref, jobOptions{DedupeKey: cacheKey(ref.EventID, ref.ClubID)}); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		http.Redirect(w, r, "/admin/?flash="+url.QueryEscape("Refresh queued for "+ref.EventID+"/"+ref.ClubID), http.StatusSeeOther)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued", "eventid": ref.EventID, "clubid": ref.ClubID})
+}
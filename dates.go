@@ -0,0 +1,161 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ---------- Date parsing ---------- */
+
+// dateLayouts are tried in order against a date with any weekday prefix
+// removed. US month-first forms come before day-first ones, since GotSport
+// and ECNL are US sites; layouts without a year get one from inferYear.
+var dateLayouts = []struct {
+	layout string
+	noYear bool
+}{
+	{"2006-01-02", false},
+	{"Jan 2, 2006", false},
+	{"January 2, 2006", false},
+	{"Jan. 2, 2006", false},
+	{"Jan 2 2006", false},
+	{"January 2 2006", false},
+	{"1/2/2006", false},
+	{"1/2/06", false},
+	{"2-Jan-2006", false},
+	{"2-Jan-06", false},
+	{"2 Jan 2006", false},
+	{"2 January 2006", false},
+	{"Jan 2", true},
+	{"January 2", true},
+	{"Jan. 2", true},
+	{"1/2", true},
+	{"2-Jan", true},
+	{"2 Jan", true},
+}
+
+var (
+	// weekdayPrefix matches "Sat ", "Saturday, " or "Sat. " before a date.
+	weekdayPrefix = regexp.MustCompile(`(?i)^(?:mon|tue|tues|wed|thu|thur|thurs|fri|sat|sun)[a-z]*\.?,?\s+`)
+	// clockPattern matches a 12-hour time with optional minutes and zone, or
+	// a 24-hour time: "1:00PM PDT", "1 pm", "1:00 p.m.", "13:00".
+	clockPattern = regexp.MustCompile(`(?i)\b(\d{1,2})(?::([0-5]\d))?\s*([ap])\.?m\b\.?(?-i:\s+([A-Z]{1,4}T|UTC)\b)?|\b([01]?\d|2[0-3]):([0-5]\d)\b`)
+	spaceRun     = regexp.MustCompile(`\s+`)
+	// sept is the one common month abbreviation Go's layouts don't know.
+	sept = regexp.MustCompile(`(?i)\bsept\b`)
+)
+
+// parseDateTime splits a schedule cell such as "Aug 30, 2025 1:00PM PDT" or
+// "Sat 3/15 9am" into a 2006-01-02 date and a "3:04PM MST" time. The time is
+// "TBD" when missing; with no readable date at all the date falls back to
+// next Saturday.
+func parseDateTime(dateTime string) (string, string) {
+	text := strings.TrimSpace(spaceRun.ReplaceAllString(dateTime, " "))
+	clockText, hour, minute, abbr, hasClock := "", 0, 0, "", false
+	if loc := clockPattern.FindStringSubmatchIndex(text); loc != nil {
+		m := clockPattern.FindStringSubmatch(text)
+		clockText = text[loc[0]:loc[1]]
+		if m[1] != "" {
+			hour, _ = strconv.Atoi(m[1])
+			minute, _ = strconv.Atoi(m[2])
+			hasClock = hour >= 1 && hour <= 12
+			hour %= 12
+			if strings.EqualFold(m[3], "p") {
+				hour += 12
+			}
+			abbr = m[4]
+		} else {
+			hour, _ = strconv.Atoi(m[5])
+			minute, _ = strconv.Atoi(m[6])
+			hasClock = true
+		}
+	}
+
+	day, ok := parseDate(strings.TrimSpace(strings.Replace(text, clockText, "", 1)))
+	if !ok {
+		// Fallback: next Saturday (event time)
+		now := clock().In(eventLocation())
+		add := (6 - int(now.Weekday()) + 7) % 7
+		if add == 0 {
+			add = 7
+		}
+		return now.AddDate(0, 0, add).Format("2006-01-02"), "TBD"
+	}
+	if !hasClock {
+		return day.Format("2006-01-02"), "TBD"
+	}
+	at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, eventLocation())
+	if abbr == "" {
+		abbr = at.Format("MST") // no zone printed; it's the event's
+	}
+	return day.Format("2006-01-02"), at.Format("3:04PM") + " " + abbr
+}
+
+// hasDate reports whether s carries a readable date, with or without a time.
+func hasDate(s string) bool {
+	text := strings.TrimSpace(spaceRun.ReplaceAllString(s, " "))
+	if loc := clockPattern.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]] + text[loc[1]:]
+	}
+	_, ok := parseDate(text)
+	return ok
+}
+
+// parseDate reads a date in any of dateLayouts, in event time.
+func parseDate(s string) (time.Time, bool) {
+	s = strings.Trim(weekdayPrefix.ReplaceAllString(strings.TrimSpace(s), ""), " ,")
+	s = sept.ReplaceAllString(s, "Sep")
+	if s == "" {
+		return time.Time{}, false
+	}
+	loc := eventLocation()
+	for _, l := range dateLayouts {
+		d, err := time.ParseInLocation(l.layout, s, loc)
+		if err != nil {
+			continue
+		}
+		switch {
+		case l.noYear:
+			d = inferYear(d)
+		case strings.Contains(l.layout, "06") && !strings.Contains(l.layout, "2006"):
+			d = nearestCentury(d)
+		}
+		return d, true
+	}
+	return time.Time{}, false
+}
+
+// inferYear places a year-less date in the season around now: the nearest
+// occurrence within six months either side, so "Mar 15" read in November
+// is next March and "Nov 8" read in March is last November.
+func inferYear(d time.Time) time.Time {
+	now := clock().In(d.Location())
+	best := d.AddDate(now.Year()-d.Year(), 0, 0)
+	for _, years := range []int{-1, 1} {
+		if alt := best.AddDate(years, 0, 0); absDuration(alt.Sub(now)) < absDuration(best.Sub(now)) {
+			best = alt
+		}
+	}
+	return best
+}
+
+// nearestCentury moves a two-digit-year date to the century closest to now;
+// Go's own pivot puts "69" in 1969 regardless of the clock.
+func nearestCentury(d time.Time) time.Time {
+	now := clock().In(d.Location())
+	for _, years := range []int{-100, 100} {
+		if alt := d.AddDate(years, 0, 0); absDuration(alt.Sub(now)) < absDuration(d.Sub(now)) {
+			d = alt
+		}
+	}
+	return d
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+)
+
+/* ---------- Degradation tiers ---------- */
+
+// dataTier names the source that served a schedule. getSchedule tries them
+// in order, each only when the ones before it could not answer.
+type dataTier string
+
+const (
+	tierFresh       dataTier = "fresh"       // scraped just now
+	tierRevalidated dataTier = "revalidated" // upstream answered 304; the kept page was re-parsed
+	tierCache       dataTier = "cache"       // the in-memory cache, stale when the scrape failed
+	tierPersisted   dataTier = "persisted"   // the last scrape kept in game_history
+	tierEmpty       dataTier = "empty"       // nothing to serve; the error says why
+)
+
+// tierOrder ranks tiers from best to worst.
+var tierOrder = map[dataTier]int{tierFresh: 0, tierRevalidated: 1, tierCache: 2, tierPersisted: 3, tierEmpty: 4}
+
+// worseTier returns whichever of a and b is further down the chain.
+func worseTier(a, b dataTier) dataTier {
+	if tierOrder[b] > tierOrder[a] {
+		return b
+	}
+	return a
+}
+
+func init() {
+	describeMetric("gotsport_schedule_tier_total", kindCounter, "Schedules served, by the tier that served them.")
+}
+
+// scheduleResult is a schedule and where it came from.
+type scheduleResult struct {
+	Games []Game
+	Age   time.Duration // age of the data when served
+	Tier  dataTier
+	// Cause is why the scrape failed when a later tier served, and the
+	// error itself for tierEmpty.
+	Cause error
+}
+
+// resolveSchedule walks the chain for one schedule: the cache when it is
+// younger than maxAge, then a live scrape (fresh or revalidated), then the
+// cache at any age, then the last persisted scrape. It returns tierEmpty
+// with the scrape's error when none of them can answer. Schedules SCRAPE_CRON
+// refreshes skip the live scrape once it has stored one.
+func (s *Server) resolveSchedule(ctx context.Context, eventID, clubID string, f scheduleFilter, refresh bool, maxAge time.Duration) scheduleResult {
+	key := cacheKey(eventID, clubID) + f.cacheSuffix()
+	if !refresh {
+		if games, age, ok := s.cache.get(key, maxAge); ok {
+			return scheduleResult{Games: games, Age: age, Tier: tierCache}
+		}
+	}
+	if !refresh && f.empty() && cronRefreshed(eventID, clubID) {
+		// SCRAPE_CRON keeps this schedule fresh; a request only scrapes
+		// before its first run has stored anything.
+		if games, fetchedAt, ok := s.cache.peek(key); ok {
+			return scheduleResult{Games: append([]Game(nil), games...), Age: time.Since(fetchedAt), Tier: tierCache}
+		}
+		if games, seen, ok := s.persistedGames(ctx, eventID, clubID, f); ok {
+			return scheduleResult{Games: games, Age: time.Since(seen), Tier: tierPersisted}
+		}
+	}
+
+	var err error
+	if scrapeQueueFull() {
+		err = errScrapeQueueFull
+	} else {
+		var games []Game
+		var tier dataTier
+		if games, tier, err = s.loadSchedule(ctx, eventID, clubID, f); err == nil {
+			return scheduleResult{Games: games, Tier: tier}
+		}
+	}
+
+	if games, fetchedAt, ok := s.cache.peek(key); ok {
+		return scheduleResult{Games: append([]Game(nil), games...), Age: time.Since(fetchedAt), Tier: tierCache, Cause: err}
+	}
+	if games, seen, ok := s.persistedGames(ctx, eventID, clubID, f); ok {
+		return scheduleResult{Games: games, Age: time.Since(seen), Tier: tierPersisted, Cause: err}
+	}
+	return scheduleResult{Games: []Game{}, Tier: tierEmpty, Cause: err}
+}
+
+// persistedGames returns the club's games from the last scrape stored in
+// game_history, narrowed by f and in kickoff order, and when that scrape
+// was. Team and venue pages are not stored, so they have no persisted tier.
+func (s *Server) persistedGames(ctx context.Context, eventID, clubID string, f scheduleFilter) ([]Game, time.Time, bool) {
+	if s.store == nil || clubID == "" || f.ownPage() {
+		return nil, time.Time{}, false
+	}
+	rows, err := s.store.QueryContext(ctx, `SELECT data, last_seen FROM game_history
+		WHERE event_id = ? AND club_id = ?
+		AND last_seen = (SELECT MAX(last_seen) FROM game_history WHERE event_id = ? AND club_id = ?)
+		ORDER BY game_id`, eventID, clubID, eventID, clubID)
+	if err != nil {
+		log.Printf("persisted schedule %s/%s: %v", eventID, clubID, err)
+		return nil, time.Time{}, false
+	}
+	defer rows.Close()
+	games := []Game{}
+	var seen int64
+	found := false
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data, &seen); err != nil {
+			log.Printf("persisted schedule %s/%s: %v", eventID, clubID, err)
+			return nil, time.Time{}, false
+		}
+		found = true
+		var g Game
+		if err := json.Unmarshal([]byte(data), &g); err != nil {
+			continue
+		}
+		if ok, _ := f.keep(g); ok {
+			games = append(games, g)
+		}
+	}
+	if err := rows.Err(); err != nil || !found {
+		return nil, time.Time{}, false
+	}
+	sort.SliceStable(games, func(i, j int) bool {
+		a, _ := gameKickoff(games[i])
+		b, _ := gameKickoff(games[j])
+		return a.Before(b)
+	})
+	return games, time.UnixMilli(seen), true
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ---------- Schedule diffs ---------- */
+
+// scheduleDiff describes how one scrape of a schedule differs from the last.
+type scheduleDiff struct {
+	Added   []Game       `json:"added"`
+	Removed []Game       `json:"removed"`
+	Changed []gameChange `json:"changed"`
+}
+
+// gameChange is a fixture whose kickoff or venue moved between scrapes.
+type gameChange struct {
+	Before Game     `json:"before"`
+	After  Game     `json:"after"`
+	Fields []string `json:"fields"` // which of date, time, location changed
+}
+
+func (d scheduleDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// fixtureKey identifies a fixture independent of when and where it is played.
+func fixtureKey(g Game) string {
+	return strings.ToLower(g.HomeTeam + "|" + g.AwayTeam + "|" + g.Division)
+}
+
+// diffSchedules pairs fixtures by teams and division. When the same pairing
+// appears several times, exact matches are paired first so a rematch isn't
+// reported as a move.
+func diffSchedules(before, after []Game) scheduleDiff {
+	var d scheduleDiff
+	remaining := map[string][]Game{}
+	for _, g := range before {
+		k := fixtureKey(g)
+		remaining[k] = append(remaining[k], g)
+	}
+	var unmatched []Game
+	for _, g := range after {
+		k := fixtureKey(g)
+		olds := remaining[k]
+		idx := -1
+		for i, o := range olds {
+			if len(changedFields(o, g)) == 0 {
+				idx = i
+				break
+			}
+		}
+		if idx >= 0 {
+			remaining[k] = append(olds[:idx], olds[idx+1:]...)
+			continue
+		}
+		unmatched = append(unmatched, g)
+	}
+	for _, g := range unmatched {
+		k := fixtureKey(g)
+		if olds := remaining[k]; len(olds) > 0 {
+			d.Changed = append(d.Changed, gameChange{Before: olds[0], After: g, Fields: changedFields(olds[0], g)})
+			remaining[k] = olds[1:]
+			continue
+		}
+		d.Added = append(d.Added, g)
+	}
+	for _, g := range before {
+		k := fixtureKey(g)
+		for i, o := range remaining[k] {
+			if len(changedFields(o, g)) == 0 {
+				d.Removed = append(d.Removed, g)
+				remaining[k] = append(remaining[k][:i], remaining[k][i+1:]...)
+				break
+			}
+		}
+	}
+	return d
+}
+
+func changedFields(a, b Game) []string {
+	var f []string
+	if a.Date != b.Date {
+		f = append(f, "date")
+	}
+	if a.Time != b.Time {
+		f = append(f, "time")
+	}
+	if a.Location != b.Location {
+		f = append(f, "location")
+	}
+	return f
+}
+
+// describe renders the diff as short human-readable lines, each added or
+// moved game with its kit and followed by its flags.
+func (d scheduleDiff) describe() []string {
+	var lines []string
+	for _, g := range d.Added {
+		lines = append(lines, fmt.Sprintf("+ %s %s  %s vs %s @ %s%s", g.Date, g.Time, g.HomeTeam, g.AwayTeam, g.Location, kitText(g)))
+		lines = append(lines, flagLines(g)...)
+	}
+	for _, g := range d.Removed {
+		lines = append(lines, fmt.Sprintf("- %s %s  %s vs %s @ %s", g.Date, g.Time, g.HomeTeam, g.AwayTeam, g.Location))
+	}
+	for _, c := range d.Changed {
+		lines = append(lines, fmt.Sprintf("~ %s vs %s: %s %s @ %s -> %s %s @ %s%s",
+			c.After.HomeTeam, c.After.AwayTeam,
+			c.Before.Date, c.Before.Time, c.Before.Location,
+			c.After.Date, c.After.Time, c.After.Location, kitText(c.After)))
+		lines = append(lines, flagLines(c.After)...)
+	}
+	return lines
+}
+
+func kitText(g Game) string {
+	if g.KitColor == "" {
+		return ""
+	}
+	return " (" + g.KitColor + " kit)"
+}
+
+func flagLines(g Game) []string {
+	var lines []string
+	for _, f := range g.Flags {
+		lines = append(lines, "  ! "+f.Message)
+	}
+	return lines
+}
+
+// scheduleChange is a non-empty diff seen when a scrape replaced cached data.
+type scheduleChange struct {
+	EventID string       `json:"eventid"`
+	ClubID  string       `json:"clubid"`
+	At      time.Time    `json:"at"`
+	Diff    scheduleDiff `json:"diff"`
+}
+
+const maxRecentChanges = 50
+
+var (
+	recentChangesMu sync.Mutex
+	recentChanges   []scheduleChange // newest last
+)
+
+// recordChange remembers d if anything changed, keeping the latest few.
+func recordChange(eventID, clubID string, d scheduleDiff) {
+	if d.empty() {
+		return
+	}
+	recentChangesMu.Lock()
+	defer recentChangesMu.Unlock()
+	recentChanges = append(recentChanges, scheduleChange{EventID: eventID, ClubID: clubID, At: time.Now(), Diff: d})
+	if n := len(recentChanges) - maxRecentChanges; n > 0 {
+		recentChanges = append([]scheduleChange(nil), recentChanges[n:]...)
+	}
+}
+
+// latestChanges returns recorded changes, newest first.
+func latestChanges() []scheduleChange {
+	recentChangesMu.Lock()
+	defer recentChangesMu.Unlock()
+	out := make([]scheduleChange, len(recentChanges))
+	for i, c := range recentChanges {
+		out[len(out)-1-i] = c
+	}
+	return out
+}
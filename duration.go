@@ -0,0 +1,133 @@
+package main
+
+import (
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ---------- Game durations ---------- */
+
+// ageDuration is the playing time for an age group and every older one up
+// to the next entry.
+type ageDuration struct {
+	Age      int // the U-number, e.g. 9 for U9
+	Duration time.Duration
+}
+
+// defaultGameDurations are US Youth Soccer regulation halves, without the
+// halftime that GAME_BUFFER adds.
+var defaultGameDurations = []ageDuration{
+	{0, 40 * time.Minute},
+	{9, 50 * time.Minute},
+	{11, 60 * time.Minute},
+	{13, 70 * time.Minute},
+	{15, 80 * time.Minute},
+	{17, 90 * time.Minute},
+}
+
+var (
+	// divisionAge reads "U12", "U-12" or "12U" from a division name.
+	divisionAge = regexp.MustCompile(`(?i)\bu-?(\d{1,2})\b|\b(\d{1,2})u\b`)
+	// birthYear reads "2012", "2012B", "B2012" or "12B" from a team name.
+	birthYear = regexp.MustCompile(`(?i)\b[bg]?((?:19|20)\d{2})[bg]?\b|\b[bg](\d{2})\b|\b(\d{2})[bg]\b`)
+)
+
+// parseGameDurations reads GAME_DURATIONS entries such as "U9=50m,U13=70m",
+// each applying from that age up; unlisted ages keep the defaults.
+func parseGameDurations(s string) []ageDuration {
+	byAge := map[int]time.Duration{}
+	for _, d := range defaultGameDurations {
+		byAge[d.Age] = d.Duration
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		group, dur, ok := strings.Cut(part, "=")
+		age, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(group)), "U"))
+		d, derr := time.ParseDuration(strings.TrimSpace(dur))
+		if n, nerr := strconv.Atoi(strings.TrimSpace(dur)); nerr == nil {
+			d, derr = time.Duration(n)*time.Minute, nil
+		}
+		if !ok || err != nil || derr != nil || age < 0 || d <= 0 {
+			log.Printf("ignoring malformed GAME_DURATIONS entry %q (want U9=50m)", part)
+			continue
+		}
+		byAge[age] = d
+	}
+	out := make([]ageDuration, 0, len(byAge))
+	for age, d := range byAge {
+		out = append(out, ageDuration{age, d})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Age < out[j].Age })
+	return out
+}
+
+// gameAge returns a game's U-number from its division or, failing that,
+// from the home team's birth year and the season the game is in.
+func gameAge(g Game, kickoff time.Time) (int, bool) {
+	if m := divisionAge.FindStringSubmatch(g.Division); m != nil {
+		n, _ := strconv.Atoi(m[1] + m[2])
+		return n, true
+	}
+	for _, team := range []string{g.HomeTeam, g.AwayTeam} {
+		m := birthYear.FindStringSubmatch(team)
+		if m == nil {
+			continue
+		}
+		year, _ := strconv.Atoi(m[1] + m[2] + m[3])
+		if year < 100 {
+			year += 2000
+		}
+		// Age groups run August to July and are named for the year the
+		// season ends.
+		season := kickoff.Year()
+		if kickoff.Month() >= time.August {
+			season++
+		}
+		if age := season - year; age > 0 && age < 30 {
+			return age, true
+		}
+	}
+	return 0, false
+}
+
+// gameDuration is how long g occupies its field: the playing time for its
+// age group plus GAME_BUFFER, or defaultGameSpan when the age is unknown.
+func gameDuration(g Game, kickoff time.Time) time.Duration {
+	age, ok := gameAge(g, kickoff)
+	if !ok {
+		return defaultGameSpan
+	}
+	var play time.Duration
+	for _, d := range appConfig.GameDurations {
+		if d.Age <= age {
+			play = d.Duration
+		}
+	}
+	if play == 0 {
+		return defaultGameSpan
+	}
+	return play + appConfig.GameBuffer
+}
+
+// withEndTimes sets DurationMinutes and EndTime on games with a parseable
+// kickoff. It edits games in place; call it on a copy such as withVenues
+// returns.
+func withEndTimes(games []Game) []Game {
+	for i, g := range games {
+		start, ok := gameKickoff(g)
+		if !ok {
+			continue
+		}
+		d := gameDuration(g, start)
+		games[i].DurationMinutes = int(d / time.Minute)
+		games[i].EndTime = start.Add(d).Format("3:04PM MST")
+	}
+	return games
+}
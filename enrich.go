@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ---------- Game pages ---------- */
+
+// detailLabelPattern finds a label on a game's own page ("Venue", "Field:")
+// and the first text after it, whether the page lays them out as th/td,
+// dt/dd or label and value.
+var detailLabelPattern = regexp.MustCompile(`(?is)<(?:th|dt|label|strong|b|span)\b[^>]*>\s*(venue|complex|location|field|pitch|division|bracket|flight)\s*:?\s*</(?:th|dt|label|strong|b|span)>(?:\s*</?\w+\b[^>]*>)*\s*([^<]+)`)
+
+// gamePageTTL is how long what a game page said is reused before the page
+// is fetched again.
+const gamePageTTL = 24 * time.Hour
+
+// gamePage is what one game page listed.
+type gamePage struct {
+	location, division string
+	fetchedAt          time.Time
+}
+
+var (
+	gamePagesMu sync.Mutex
+	gamePages   = map[string]gamePage{} // by URL
+)
+
+func init() {
+	describeMetric("gotsport_game_pages_total", kindCounter, "Game pages followed to fill fields the schedule left out, by outcome (enriched, unchanged, failed or cached).")
+}
+
+// gamePageURL resolves a game page link against GotSport, or returns ""
+// for one that leaves the site.
+func gamePageURL(href string) string {
+	if href == "" {
+		return ""
+	}
+	u, err := url.Parse(strings.ReplaceAll(href, "&amp;", "&"))
+	if err != nil {
+		return ""
+	}
+	u = gotsportBase.ResolveReference(u)
+	if u.Host != gotsportBase.Host {
+		return ""
+	}
+	return u.String()
+}
+
+// gameDetails reads the venue, field and division a game's page lists.
+func gameDetails(html string) (location, division string) {
+	var venue, pitch string
+	for _, m := range detailLabelPattern.FindAllStringSubmatch(html, -1) {
+		value := cleanText(m[2])
+		if value == "" {
+			continue
+		}
+		switch strings.ToLower(m[1]) {
+		case "venue", "complex", "location":
+			venue = firstNonEmpty(venue, value)
+		case "field", "pitch":
+			pitch = firstNonEmpty(pitch, value)
+		default:
+			division = firstNonEmpty(division, value)
+		}
+	}
+	switch {
+	case venue == "" || pitch == "" || containsFold(venue, pitch):
+		location = firstNonEmpty(venue, pitch)
+	default:
+		location = venue + " - " + pitch
+	}
+	return location, division
+}
+
+// needsDetails reports whether g lacks something its page could fill in.
+func needsDetails(g Game) bool {
+	return g.SourceURL != "" && (g.Location == "" || g.Division == "")
+}
+
+// withinEnrichWindow reports whether g kicks off between now and
+// ENRICH_DAYS days from now; pages of later games are left for later
+// scrapes, when they may no longer be needed.
+func withinEnrichWindow(g Game, now time.Time) bool {
+	kickoff, ok := gameKickoff(g)
+	return ok && !kickoff.Before(now) && kickoff.Before(now.AddDate(0, 0, appConfig.EnrichDays))
+}
+
+func cachedGamePage(link string) (gamePage, bool) {
+	gamePagesMu.Lock()
+	defer gamePagesMu.Unlock()
+	p, ok := gamePages[link]
+	if ok && time.Since(p.fetchedAt) > gamePageTTL {
+		delete(gamePages, link)
+		return gamePage{}, false
+	}
+	return p, ok
+}
+
+// rememberGamePage caches page, dropping expired pages so past games'
+// don't pile up.
+func rememberGamePage(link string, page gamePage) {
+	gamePagesMu.Lock()
+	defer gamePagesMu.Unlock()
+	for l, p := range gamePages {
+		if time.Since(p.fetchedAt) > gamePageTTL {
+			delete(gamePages, l)
+		}
+	}
+	gamePages[link] = page
+}
+
+// enrichFromGamePages follows the page link of each game in the enrich
+// window missing its location or division and fills them from the page, in
+// place (ENRICH_DETAILS). Pages already read are reused; at most
+// ENRICH_BUDGET are fetched per scrape, ENRICH_INTERVAL apart. A page that
+// fails to load leaves its game as it was.
+func enrichFromGamePages(ctx context.Context, games []Game, now time.Time) {
+	fetched := 0
+	for i := range games {
+		g := &games[i]
+		if !needsDetails(*g) || !withinEnrichWindow(*g, now) {
+			continue
+		}
+		page, ok := cachedGamePage(g.SourceURL)
+		if ok {
+			incCounter("gotsport_game_pages_total", "outcome", "cached")
+		} else {
+			if fetched >= appConfig.EnrichBudget {
+				continue // still apply what earlier scrapes read
+			}
+			if fetched > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(appConfig.EnrichInterval):
+				}
+			}
+			fetched++
+			body, err := fetchPage(ctx, "gotsport", g.SourceURL)
+			if err != nil {
+				log.Printf("game page %s: %v", g.SourceURL, err)
+				incCounter("gotsport_game_pages_total", "outcome", "failed")
+				if ctx.Err() != nil {
+					return
+				}
+				continue
+			}
+			page.location, page.division = gameDetails(string(body))
+			page.fetchedAt = time.Now()
+			rememberGamePage(g.SourceURL, page)
+		}
+		outcome := "unchanged"
+		if g.Location == "" && page.location != "" {
+			g.Location, outcome = page.location, "enriched"
+		}
+		if g.Division == "" && page.division != "" {
+			g.Division, g.Competition, outcome = page.division, page.division, "enriched"
+		}
+		if !ok {
+			incCounter("gotsport_game_pages_total", "outcome", outcome)
+		}
+	}
+	if fetched > 0 {
+		log.Printf("Followed %d game pages", fetched)
+	}
+}
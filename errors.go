@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+/* ---------- Error taxonomy ---------- */
+
+// The causes a failed scrape or request is classified under. Test for them
+// with errors.Is; errorCode turns them into the codes API responses and
+// metrics carry.
+var (
+	// ErrUpstreamBlocked is GotSport refusing us: 401, 403, 429 or 451.
+	ErrUpstreamBlocked = errors.New("upstream blocked the request")
+	// ErrParseEmpty is a page that downloaded but yielded no games.
+	ErrParseEmpty = errors.New("no games parsed")
+	// ErrTimeout is a fetch, or the wait for a fetch slot, running out of
+	// time.
+	ErrTimeout = errors.New("timed out")
+	// ErrBadParams is a request the API cannot serve as asked.
+	ErrBadParams = errors.New("invalid parameters")
+)
+
+// errorCodes are the stable codes for each cause. invalid_parameters
+// predates the taxonomy.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrBadParams, "invalid_parameters"},
+	{ErrUpstreamBlocked, "upstream_blocked"},
+	{ErrTimeout, "upstream_timeout"},
+	{ErrParseEmpty, "parse_empty"},
+}
+
+// errorCode returns the code for err's cause, or scrape_failed when it has
+// none of them.
+func errorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return "scrape_failed"
+}
+
+// classifiedError tags err with a cause without changing its message.
+type classifiedError struct {
+	cause error
+	err   error
+}
+
+func (e *classifiedError) Error() string   { return e.err.Error() }
+func (e *classifiedError) Unwrap() []error { return []error{e.cause, e.err} }
+
+// classify tags err with cause; a nil err stays nil.
+func classify(cause, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{cause: cause, err: err}
+}
+
+// badParamsf is fmt.Errorf for an ErrBadParams error.
+func badParamsf(format string, args ...any) error {
+	return classify(ErrBadParams, fmt.Errorf(format, args...))
+}
+
+// isTimeout reports whether err is a deadline or network timeout.
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &ne) && ne.Timeout()
+}
+
+// timeoutAware tags wrapped with ErrTimeout when err, the error it
+// describes, was a timeout.
+func timeoutAware(err, wrapped error) error {
+	if isTimeout(err) {
+		return classify(ErrTimeout, wrapped)
+	}
+	return wrapped
+}
+
+// upstreamBlocked reports whether status means GotSport refused us.
+func upstreamBlocked(status int) bool {
+	return status == 401 || status == 403 || status == 429 || status == 451
+}
+
+// errorResponse is the ErrorResponse for err, coded by its cause.
+func errorResponse(err error) ErrorResponse {
+	return ErrorResponse{Error: errorCode(err), Detail: err.Error()}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+)
+
+/* ---------- Parse explain mode ---------- */
+
+// rowExplain records what the parser did with one candidate row.
+type rowExplain struct {
+	Row      int                    `json:"row"`
+	Strategy string                 `json:"strategy,omitempty"`
+	Fields   map[string]fieldSource `json:"fields,omitempty"`
+	Accepted bool                   `json:"accepted"`
+	Reason   string                 `json:"reason,omitempty"` // why it was rejected
+}
+
+// fieldSource says where a value came from in the row markup.
+type fieldSource struct {
+	From  string `json:"from"`
+	Value string `json:"value"`
+}
+
+// explainRow appends ex when explain mode is on.
+func (s *parseStats) explainRow(ex rowExplain) {
+	if s.explain {
+		s.Explain = append(s.Explain, ex)
+	}
+}
+
+// rejectExplained marks an already-accepted row as rejected after the fact.
+func (s *parseStats) rejectExplained(i int, reason string) {
+	if s.explain && i < len(s.Explain) {
+		s.Explain[i].Accepted = false
+		s.Explain[i].Reason = reason
+	}
+}
+
+// debugParseHandler serves /debug/parse?eventid=&clubid=[&explain=true]: a
+// live scrape that bypasses the cache and returns the parse stats, plus the
+// per-row explanation when asked. It is admin-only: the raw scrape lists
+// hidden games, and each call scrapes upstream outside the flight group.
+func (s *Server) debugParseHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	eventID, clubID := q.Get("eventid"), q.Get("clubid")
+	if eventID == "" || clubID == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "missing_parameters",
+			Detail: "eventid and clubid are required",
+		})
+		return
+	}
+	explain, _ := strconv.ParseBool(q.Get("explain"))
+
+	games, stats, err := scrapeGotSport(r.Context(), eventID, clubID, scheduleFilter{}, explain)
+	// Raw output: hidden games stay in, listed so they can be told apart.
+	resp := map[string]any{"games": games, "stats": stats, "hidden": s.hiddenIDs(games)}
+	if err != nil {
+		resp["error"] = err.Error()
+	}
+	if stats == nil {
+		writeJSON(w, http.StatusBadGateway, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+/* ---------- Game history and bulk export ---------- */
+
+// storeGames records an unfiltered scrape, and source, what produced it,
+// in game_history. A game's updated_at moves only when its data changes;
+// last_seen moves every time it is scraped, so games that drop out of the
+// window keep their last state.
+func (s *Server) storeGames(eventID, clubID, source string, games []Game) {
+	if s.store == nil {
+		return // one-shot CLI runs keep no history
+	}
+	now := time.Now().UnixMilli()
+	tx, err := s.store.BeginTx(context.Background(), nil)
+	if err != nil {
+		log.Printf("game history: %v", err)
+		return
+	}
+	defer tx.Rollback()
+	for _, g := range games {
+		data, err := json.Marshal(g)
+		if err != nil {
+			log.Printf("game history: %v", err)
+			return
+		}
+		if _, err := tx.Exec(`INSERT INTO game_history (game_id, event_id, club_id, data, first_seen, last_seen, updated_at, source)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (game_id, event_id, club_id) DO UPDATE SET last_seen = excluded.last_seen,
+				data = excluded.data, source = excluded.source,
+				updated_at = CASE WHEN game_history.data = excluded.data THEN game_history.updated_at ELSE excluded.updated_at END`,
+			g.ID, eventID, clubID, string(data), now, now, now, source); err != nil {
+			log.Printf("game history: %v", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("game history: %v", err)
+	}
+}
+
+// Kinds of exportRecord.
+const (
+	exportGame   = "game"   // a game_history row
+	exportResult = "result" // a posted_results row
+)
+
+// scrapeSource names what produced a scrape's games for game_history: the
+// path stats records, or the upstream for scrapes without one.
+func scrapeSource(eventID string, stats *parseStats) string {
+	if stats != nil && stats.Path != "" {
+		return stats.Path
+	}
+	return sourceOf(eventID)
+}
+
+// exportRecord is one line of /export/full: a stored game, or a posted
+// result. A result is recorded once, so its three times are all when it
+// was first seen posted.
+type exportRecord struct {
+	Kind      string          `json:"kind"`
+	ID        string          `json:"id"`
+	EventID   string          `json:"eventid"`
+	ClubID    string          `json:"clubid"`
+	Source    string          `json:"source,omitempty"` // the path a game was last scraped by; see scrapeSource
+	FirstSeen time.Time       `json:"firstSeen"`
+	LastSeen  time.Time       `json:"lastSeen"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Game      json.RawMessage `json:"game,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+}
+
+// exportPageSize is how many records are read per query. Each page is
+// read and its rows closed before any of it is written, so a slow client
+// never holds the datastore's one connection.
+const exportPageSize = 500
+
+// exportPageTimeout bounds writing one page. It replaces the server's
+// WriteTimeout, which would cut off a long export part way through.
+const exportPageTimeout = time.Minute
+
+// exportFullHandler streams /export/full[?since=2025-08-01T00:00:00Z] as
+// NDJSON: every stored game changed, and every result posted, after since,
+// oldest first. A loader passes the last updatedAt it saw as the next since.
+func (s *Server) exportFullHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	var since int64 = -1
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:  "invalid_parameters",
+				Detail: "since must be an RFC 3339 time like 2025-08-01T00:00:00Z",
+			})
+			return
+		}
+		since = t.UnixMilli()
+	}
+
+	page, err := exportPage(r.Context(), s.store, since, nil)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	for len(page) > 0 {
+		rc.SetWriteDeadline(time.Now().Add(exportPageTimeout))
+		for _, rec := range page {
+			if err := enc.Encode(rec); err != nil {
+				return // client went away
+			}
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return
+		}
+		if len(page) < exportPageSize {
+			return
+		}
+		if page, err = exportPage(r.Context(), s.store, since, &page[len(page)-1]); err != nil {
+			log.Printf("export: %v", err)
+			return
+		}
+	}
+}
+
+// exportPage reads the next page of d's records changed after since,
+// following after (the last record of the previous page) in export order.
+func exportPage(ctx context.Context, d Store, since int64, after *exportRecord) ([]exportRecord, error) {
+	query := `SELECT kind, game_id, event_id, club_id, source, data, result, first_seen, last_seen, updated_at FROM (
+			SELECT 'game' AS kind, game_id, event_id, club_id, source, data, '' AS result, first_seen, last_seen, updated_at
+			FROM game_history
+			UNION ALL
+			SELECT 'result', game_id, event_id, club_id, '', data, result, posted_at, posted_at, posted_at
+			FROM posted_results
+		) AS records WHERE updated_at > ?`
+	args := []any{since}
+	if after != nil {
+		query += ` AND (updated_at, kind, game_id, event_id, club_id) > (?, ?, ?, ?, ?)`
+		args = append(args, after.UpdatedAt.UnixMilli(), after.Kind, after.ID, after.EventID, after.ClubID)
+	}
+	query += ` ORDER BY updated_at, kind, game_id, event_id, club_id LIMIT ?`
+	args = append(args, exportPageSize)
+
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var page []exportRecord
+	for rows.Next() {
+		var rec exportRecord
+		var data, result string
+		var first, last, updated int64
+		if err := rows.Scan(&rec.Kind, &rec.ID, &rec.EventID, &rec.ClubID, &rec.Source, &data, &result, &first, &last, &updated); err != nil {
+			return nil, err
+		}
+		switch {
+		case rec.Kind == exportGame:
+			rec.Game = json.RawMessage(data)
+		case data != "":
+			rec.Result = json.RawMessage(data)
+		default:
+			// Posted before results kept their row; the score is all there is.
+			rec.Result, _ = json.Marshal(postedResult{GameID: rec.ID, Result: result})
+		}
+		rec.FirstSeen, rec.LastSeen, rec.UpdatedAt = time.UnixMilli(first).UTC(), time.UnixMilli(last).UTC(), time.UnixMilli(updated).UTC()
+		page = append(page, rec)
+	}
+	return page, rows.Err()
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ---------- Field usage report ---------- */
+
+// fieldUsage is the club's home-game use of one field in one month.
+type fieldUsage struct {
+	Field string  `json:"field"`
+	Month string  `json:"month"` // 2006-01
+	Games int     `json:"games"`
+	Hours float64 `json:"hours"`
+}
+
+// fieldUsageReport is what /reports/field-usage returns.
+type fieldUsageReport struct {
+	From   string       `json:"from,omitempty"`
+	To     string       `json:"to,omitempty"`
+	Fields []fieldUsage `json:"fields"` // by field, then month
+	Hours  float64      `json:"hours"`
+}
+
+// fieldUsageHandler serves /reports/field-usage?from=&to=[&format=csv]: the
+// hours the club's home games occupied each field, per month, from every
+// game stored from scrapes. A game's hours are its playing time plus
+// GAME_BUFFER, as for end times. from and to are inclusive dates and
+// default to all stored games.
+func (s *Server) fieldUsageHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	f := scheduleFilter{From: q.Get("from"), To: q.Get("to")}
+	if err := f.validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	format := q.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		writeJSON(w, http.StatusBadRequest, errorResponse(badParamsf("format must be json or csv")))
+		return
+	}
+	report := fieldUsageReport{From: f.From, To: f.To, Fields: []fieldUsage{}}
+	if s.store != nil {
+		games, err := s.storedHomeGames(r.Context(), f)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+			return
+		}
+		report = tallyFieldUsage(report, games)
+	}
+	if format == "csv" {
+		writeFieldUsageCSV(w, report)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// storedHomeGames returns the club's stored home games between f.From and
+// f.To, as the schedule presents them, each once however many events list
+// it. Cancelled games are left out.
+func (s *Server) storedHomeGames(ctx context.Context, f scheduleFilter) ([]Game, error) {
+	rows, err := s.store.QueryContext(ctx, `SELECT DISTINCT game_id, data FROM game_history`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var games []Game
+	seen := map[string]bool{}
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		var g Game
+		if seen[id] || json.Unmarshal([]byte(data), &g) != nil {
+			continue
+		}
+		seen[id] = true
+		games = append(games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var out []Game
+	for _, g := range s.presentGames(games) {
+		side := clubSide(g)
+		if side == "" || side != g.HomeTeam || g.Location == "" || g.Location == "TBD" || g.Status == "cancelled" {
+			continue
+		}
+		if f.From != "" && g.Date < f.From || f.To != "" && g.Date > f.To {
+			continue
+		}
+		out = append(out, g)
+	}
+	return out, nil
+}
+
+// tallyFieldUsage adds games' hours to report by field and month. Games
+// whose kickoff can't be read are left out.
+func tallyFieldUsage(report fieldUsageReport, games []Game) fieldUsageReport {
+	byKey := map[string]*fieldUsage{}
+	var total time.Duration
+	spans := map[*fieldUsage]time.Duration{}
+	for _, g := range games {
+		start, ok := gameKickoff(g)
+		if !ok {
+			continue
+		}
+		d := gameDuration(g, start)
+		key := strings.ToLower(g.Location) + "|" + g.Date[:7]
+		u := byKey[key]
+		if u == nil {
+			u = &fieldUsage{Field: g.Location, Month: g.Date[:7]}
+			byKey[key] = u
+		}
+		u.Games++
+		spans[u] += d
+		total += d
+	}
+	for _, u := range byKey {
+		u.Hours = hours(spans[u])
+		report.Fields = append(report.Fields, *u)
+	}
+	sort.Slice(report.Fields, func(i, j int) bool {
+		a, b := report.Fields[i], report.Fields[j]
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Month < b.Month
+	})
+	report.Hours = hours(total)
+	return report
+}
+
+// hours is d in hours to two decimal places.
+func hours(d time.Duration) float64 {
+	return float64(d.Round(36*time.Second)) / float64(time.Hour)
+}
+
+func writeFieldUsageCSV(w http.ResponseWriter, report fieldUsageReport) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="field-usage.csv"`)
+	w.WriteHeader(http.StatusOK)
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"field", "month", "games", "hours"})
+	for _, u := range report.Fields {
+		_ = cw.Write([]string{u.Field, u.Month, strconv.Itoa(u.Games), strconv.FormatFloat(u.Hours, 'f', 2, 64)})
+	}
+	cw.Flush()
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/* ---------- Schedule filters ---------- */
+
+// scheduleFilter narrows a schedule to a date range, division, team or
+// venue. The zero value is next weekend's games, the service's default.
+type scheduleFilter struct {
+	From     string // 2006-01-02, inclusive; replaces the next-weekend window
+	To       string // 2006-01-02, inclusive
+	Division string // case-insensitive substring, or a numeric GotSport group ID
+	Team     string // GotSport team ID; scrapes the team's own schedule page
+	Venue    string // GotSport field ID, scraping that venue's page, or a location substring
+	// AllSides keeps away games and bracket slots and drops the weekend
+	// window; itineraries use it with Team.
+	AllSides bool
+	// Club replaces CLUB_NAME as the club whose games are kept.
+	Club string
+	// Side is sideAway or sideAll to keep the club's away games instead of,
+	// or as well as, its home games; empty keeps home games. Unlike
+	// AllSides it keeps the weekend window and leaves bracket slots out.
+	Side string
+}
+
+// Sides a schedule can ask for, and the values of Game.HomeOrAway.
+const (
+	sideHome = "home"
+	sideAway = "away"
+	sideAll  = "all"
+)
+
+// digitsOnly matches the numeric group IDs GotSport uses for divisions.
+var digitsOnly = regexp.MustCompile(`^\d+$`)
+
+func (f scheduleFilter) empty() bool { return f == scheduleFilter{} }
+
+func (f scheduleFilter) ranged() bool { return f.From != "" || f.To != "" }
+
+// plainPage reports whether f scrapes the club's whole schedule page, its
+// other settings only narrowing what is parsed from it.
+func (f scheduleFilter) plainPage() bool {
+	f.AllSides, f.Club, f.Side = false, "", ""
+	return f.empty()
+}
+
+// keepsSide reports whether f keeps a game the club plays on side (a
+// Game.HomeOrAway), as judgeFixture decides for scraped rows.
+func (f scheduleFilter) keepsSide(side string) bool {
+	switch {
+	case f.AllSides:
+		return true
+	case f.Side == sideAll:
+		return side != ""
+	default:
+		return side == firstNonEmpty(f.Side, sideHome)
+	}
+}
+
+// venueID reports whether Venue is a GotSport field ID rather than a name.
+func (f scheduleFilter) venueID() bool { return digitsOnly.MatchString(f.Venue) }
+
+// ownPage reports whether f scrapes a team or venue page instead of the
+// club's; the XHR endpoint and print/export views are per club.
+func (f scheduleFilter) ownPage() bool { return f.Team != "" || f.venueID() }
+
+// validate reports a malformed filter in terms of the API parameters.
+func (f scheduleFilter) validate() error {
+	for name, v := range map[string]string{"from": f.From, "to": f.To} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return badParamsf("%s must be a date like 2025-08-30", name)
+		}
+	}
+	if f.From != "" && f.To != "" && f.To < f.From {
+		return badParamsf("to must not be before from")
+	}
+	if f.Side != "" && f.Side != sideHome && f.Side != sideAway && f.Side != sideAll {
+		return badParamsf("side must be home, away or all")
+	}
+	if f.Team != "" && !digitsOnly.MatchString(f.Team) {
+		return badParamsf("team must be a numeric GotSport team ID")
+	}
+	if f.Team != "" && digitsOnly.MatchString(f.Venue) {
+		return badParamsf("team and a venue ID select different pages; pass a venue name to filter a team's games")
+	}
+	return nil
+}
+
+// cacheSuffix distinguishes filtered schedules from the default one in
+// cache and coalescing keys; it is empty for the zero filter.
+func (f scheduleFilter) cacheSuffix() string {
+	if f.empty() {
+		return ""
+	}
+	q := url.Values{"from": {f.From}, "to": {f.To}, "division": {strings.ToLower(f.Division)}, "team": {f.Team}, "venue": {strings.ToLower(f.Venue)}, "club": {strings.ToLower(f.Club)}, "side": {f.Side}}
+	if f.AllSides {
+		q.Set("allSides", "true")
+	}
+	return "|" + q.Encode()
+}
+
+// upstreamQuery adds the GotSport parameters that serve the same slice
+// server-side. GotSport filters a single day (date=) and a division by group
+// ID (group=); anything else is filtered after parsing.
+func (f scheduleFilter) upstreamQuery(q url.Values) {
+	if f.From != "" && f.From == f.To {
+		q.Set("date", f.From)
+	}
+	if digitsOnly.MatchString(f.Division) {
+		q.Set("group", f.Division)
+	}
+}
+
+// scheduleURL is the GotSport page to scrape: the team's own schedule or the
+// venue's when f names one, which list only their games, else the club's.
+func (f scheduleFilter) scheduleURL(eventID, clubID string) string {
+	q := url.Values{}
+	switch {
+	case f.Team != "":
+		q.Set("team", f.Team)
+	case f.venueID():
+		q.Set("field", f.Venue)
+	default:
+		q.Set("club", clubID)
+	}
+	f.upstreamQuery(q)
+	return fmt.Sprintf("%s/org_event/events/%s/schedules?%s", gotsportOrigin, url.PathEscape(eventID), q.Encode())
+}
+
+// withUpstreamQuery returns rawURL with f's upstream parameters added.
+func (f scheduleFilter) withUpstreamQuery(rawURL string) string {
+	if f.empty() {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	f.upstreamQuery(q)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// keep reports whether g passes the filter, and if not, why. Dates are only
+// checked for ranged filters; the weekend window is applied by weekendGames.
+func (f scheduleFilter) keep(g Game) (bool, string) {
+	if f.From != "" && g.Date < f.From || f.To != "" && g.Date > f.To {
+		return false, "outside from/to range"
+	}
+	// A group ID was filtered upstream; there is no name to compare here.
+	if f.Division != "" && !digitsOnly.MatchString(f.Division) &&
+		!strings.Contains(strings.ToLower(g.Division), strings.ToLower(f.Division)) {
+		return false, "not in division"
+	}
+	if f.Venue != "" && !f.venueID() &&
+		!strings.Contains(strings.ToLower(g.Location), strings.ToLower(f.Venue)) {
+		return false, "not at venue"
+	}
+	return true, ""
+}
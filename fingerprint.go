@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/* ---------- TLS fingerprints ---------- */
+
+// defaultFingerprint is Go's own TLS handshake.
+const defaultFingerprint = "go"
+
+// tlsDialer dials addr and completes a TLS handshake on it that presents
+// one client profile. It must negotiate HTTP/1.1, which is all a custom
+// dial leaves http.Transport able to speak.
+type tlsDialer func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// tlsProfiles are the fingerprints TLS_FINGERPRINT can name besides "go".
+// The default build has none; fingerprint_utls.go adds the browser ones
+// when built with -tags utls.
+var tlsProfiles = map[string]tlsDialer{}
+
+var (
+	fingerprintClientsMu sync.Mutex
+	fingerprintClients   = map[string]*http.Client{} // by profile
+)
+
+// parseFingerprints reads TLS_FINGERPRINT: a bare profile for every source
+// ("chrome"), or "source=profile" pairs. Names are checked when first used,
+// since config loads before fingerprint_utls.go registers its profiles.
+func parseFingerprints(s string) map[string]string {
+	out := map[string]string{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		source, profile, ok := strings.Cut(part, "=")
+		if !ok {
+			source, profile = "*", source
+		}
+		source, profile = strings.TrimSpace(source), strings.TrimSpace(profile)
+		if source == "" || profile == "" {
+			log.Printf("ignoring malformed TLS_FINGERPRINT entry %q (want source=profile)", part)
+			continue
+		}
+		out[source] = profile
+	}
+	return out
+}
+
+func fingerprintNames() []string {
+	names := []string{defaultFingerprint}
+	for name := range tlsProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// fingerprintFor is the profile TLS_FINGERPRINT picks for source.
+func fingerprintFor(source string) string {
+	return firstNonEmpty(appConfig.Fingerprints[source], appConfig.Fingerprints["*"], defaultFingerprint)
+}
+
+// upstreamClientFor returns the client for requests to source: upstreamClient
+// itself, or one sharing its settings whose HTTPS connections present the
+// source's TLS profile. A profile this build lacks is logged once and falls
+// back to upstreamClient.
+//
+// http.Transport does its own handshake through a proxy, so requests sent
+// through HTTPS_PROXY present Go's fingerprint whatever the profile.
+func upstreamClientFor(source string) *http.Client {
+	profile := fingerprintFor(source)
+	if profile == defaultFingerprint {
+		return upstreamClient
+	}
+	fingerprintClientsMu.Lock()
+	defer fingerprintClientsMu.Unlock()
+	if c := fingerprintClients[profile]; c != nil {
+		return c
+	}
+	c := upstreamClient
+	if dial := tlsProfiles[profile]; dial == nil {
+		log.Printf("TLS_FINGERPRINT %q is not built in (have %s); using %q", profile, strings.Join(fingerprintNames(), ", "), defaultFingerprint)
+	} else {
+		if firstNonEmpty(os.Getenv("HTTPS_PROXY"), os.Getenv("https_proxy")) != "" {
+			log.Printf("TLS_FINGERPRINT %q does not apply to requests sent through HTTPS_PROXY", profile)
+		}
+		t := newUpstreamTransport()
+		t.DialTLSContext = dial
+		c = &http.Client{Timeout: upstreamClient.Timeout, Transport: t}
+	}
+	fingerprintClients[profile] = c
+	return c
+}
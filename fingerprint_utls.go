@@ -0,0 +1,58 @@
+//go:build utls
+
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+// utlsRootCAs verifies upstream certificates; nil trusts the system's.
+var utlsRootCAs *x509.CertPool
+
+// Browser TLS profiles, built with -tags utls.
+func init() {
+	for name, id := range map[string]utls.ClientHelloID{
+		"chrome":  utls.HelloChrome_Auto,
+		"firefox": utls.HelloFirefox_Auto,
+		"safari":  utls.HelloSafari_Auto,
+		"edge":    utls.HelloEdge_Auto,
+	} {
+		tlsProfiles[name] = utlsDialer(id)
+	}
+}
+
+// utlsDialer sends id's ClientHello with ALPN narrowed to http/1.1, since
+// http.Transport can't speak HTTP/2 over a connection it didn't handshake.
+// The cipher suites, extensions and their order are still the browser's.
+func utlsDialer(id utls.ClientHelloID) tlsDialer {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		spec, err := utls.UTLSIdToSpec(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, ext := range spec.Extensions {
+			if alpn, ok := ext.(*utls.ALPNExtension); ok {
+				alpn.AlpnProtocols = []string{"http/1.1"}
+			}
+		}
+		conn, err := upstreamDialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		host, _, _ := net.SplitHostPort(addr)
+		uconn := utls.UClient(conn, &utls.Config{ServerName: host, RootCAs: utlsRootCAs}, utls.HelloCustom)
+		if err := uconn.ApplyPreset(&spec); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if err := uconn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return uconn, nil
+	}
+}
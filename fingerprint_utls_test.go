@@ -0,0 +1,59 @@
+//go:build utls
+
+package main
+
+import (
+	"crypto/tls"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// A profile named in TLS_FINGERPRINT takes effect even though config loads
+// before the profiles register, and the upstream sees a browser's
+// ClientHello (Chrome's GREASE cipher suites, which Go never sends) while
+// the request itself still goes over HTTP/1.1.
+func TestFingerprintChrome(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	var hello *tls.ClientHelloInfo
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	}))
+	srv.TLS = &tls.Config{GetConfigForClient: func(h *tls.ClientHelloInfo) (*tls.Config, error) {
+		hello = h
+		return nil, nil
+	}}
+	srv.StartTLS()
+	defer srv.Close()
+
+	saved, savedRoots := appConfig, utlsRootCAs
+	defer func() { appConfig, utlsRootCAs = saved, savedRoots }()
+	appConfig.Fingerprints = parseFingerprints("chrome")
+	utlsRootCAs = srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	c := upstreamClientFor("gotsport")
+	if c == upstreamClient {
+		t.Fatal("chrome profile fell back to Go's handshake")
+	}
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "HTTP/1.1" {
+		t.Errorf("request went over %q, want HTTP/1.1", body)
+	}
+	grease := false
+	for _, cs := range hello.CipherSuites {
+		grease = grease || cs&0x0f0f == 0x0a0a
+	}
+	if !grease {
+		t.Errorf("ClientHello cipher suites %x have no GREASE value", hello.CipherSuites)
+	}
+}
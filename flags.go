@@ -0,0 +1,36 @@
+package main
+
+/* ---------- Game flags ---------- */
+
+// gameFlag is a scheduling problem with a game, shown with it in responses
+// and in change notifications.
+type gameFlag struct {
+	Code    string `json:"code"` // e.g. "blackout"
+	Message string `json:"message"`
+}
+
+// flagCheck adds flags to games, which are a whole club schedule so checks
+// can compare games with each other.
+type flagCheck func(games []Game)
+
+var flagChecks []flagCheck
+
+// registerFlagCheck adds a check that withFlags runs. Call it from init.
+func registerFlagCheck(fn flagCheck) {
+	flagChecks = append(flagChecks, fn)
+}
+
+// withFlags replaces each game's flags with what the registered checks find.
+func withFlags(games []Game) []Game {
+	for i := range games {
+		games[i].Flags = nil
+	}
+	for _, check := range flagChecks {
+		check(games)
+	}
+	return games
+}
+
+func (g *Game) flag(code, message string) {
+	g.Flags = append(g.Flags, gameFlag{Code: code, Message: message})
+}
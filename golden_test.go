@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"gotsport-api/internal/fixtures"
+)
+
+func TestGoldenFixtures(t *testing.T) {
+	corpus, err := fixtures.Load("testdata/fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corpus) == 0 {
+		t.Fatal("no fixtures found under testdata/fixtures")
+	}
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	for _, f := range corpus {
+		f := f
+		t.Run(f.ID(), func(t *testing.T) {
+			if f.Source != "gotsport" {
+				t.Skipf("no parser for source %q", f.Source)
+			}
+			clock = func() time.Time { return f.Meta.AsOf }
+			defer func() { clock = time.Now }()
+
+			games, err := parseFixture(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Golden(t, games)
+		})
+	}
+}
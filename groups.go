@@ -0,0 +1,75 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+/* ---------- Schedule grouping ---------- */
+
+// dateGroup is one day of games split by field, for printable outputs.
+type dateGroup struct {
+	Date   time.Time
+	Fields []fieldGroup
+}
+
+type fieldGroup struct {
+	Field string
+	Games []Game
+}
+
+// groupByDateAndField orders games by day, then field name, then kickoff.
+// Games without a parseable kickoff are dropped.
+func groupByDateAndField(games []Game) []dateGroup {
+	type item struct {
+		at time.Time
+		g  Game
+	}
+	byDay := map[string]map[string][]item{}
+	days := map[string]time.Time{}
+	for _, g := range games {
+		at, ok := gameKickoff(g)
+		if !ok {
+			continue
+		}
+		day := at.Format("2006-01-02")
+		if byDay[day] == nil {
+			byDay[day] = map[string][]item{}
+			days[day] = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+		}
+		field := strings.TrimSpace(g.Location)
+		if field == "" {
+			field = "TBD"
+		}
+		byDay[day][field] = append(byDay[day][field], item{at, g})
+	}
+
+	dayKeys := make([]string, 0, len(byDay))
+	for d := range byDay {
+		dayKeys = append(dayKeys, d)
+	}
+	sort.Strings(dayKeys)
+
+	out := make([]dateGroup, 0, len(dayKeys))
+	for _, d := range dayKeys {
+		fields := byDay[d]
+		names := make([]string, 0, len(fields))
+		for f := range fields {
+			names = append(names, f)
+		}
+		sort.Strings(names)
+		dg := dateGroup{Date: days[d]}
+		for _, f := range names {
+			items := fields[f]
+			sort.SliceStable(items, func(i, j int) bool { return items[i].at.Before(items[j].at) })
+			fg := fieldGroup{Field: f}
+			for _, it := range items {
+				fg.Games = append(fg.Games, it.g)
+			}
+			dg.Fields = append(dg.Fields, fg)
+		}
+		out = append(out, dg)
+	}
+	return out
+}
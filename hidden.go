@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ---------- Hidden games ---------- */
+
+// hiddenGame keeps a scraped game, such as a duplicate or a scrimmage listed
+// publicly by mistake, out of every public output. The admin-only
+// /debug/parse still shows it.
+type hiddenGame struct {
+	GameID   string    `json:"gameId"`
+	Reason   string    `json:"reason,omitempty"`
+	HiddenAt time.Time `json:"hiddenAt"`
+}
+
+// hiddenSet is an in-memory copy of the game_hidden table.
+type hiddenSet struct {
+	mu   sync.RWMutex
+	byID map[string]hiddenGame
+}
+
+func (s *hiddenSet) replace(hs []hiddenGame) {
+	m := make(map[string]hiddenGame, len(hs))
+	for _, h := range hs {
+		m[h.GameID] = h
+	}
+	s.mu.Lock()
+	s.byID = m
+	s.mu.Unlock()
+}
+
+func (s *hiddenSet) list() []hiddenGame {
+	s.mu.RLock()
+	out := make([]hiddenGame, 0, len(s.byID))
+	for _, h := range s.byID {
+		out = append(out, h)
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].GameID < out[j].GameID })
+	return out
+}
+
+func (s *hiddenSet) has(gameID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.byID[gameID]
+	return ok
+}
+
+// withoutHidden drops hidden games, in place; call it on a copy such as
+// withOverrides returns.
+func (s *Server) withoutHidden(games []Game) []Game {
+	out := games[:0]
+	for _, g := range games {
+		if !s.hidden.has(g.ID) {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// hiddenIDs lists the IDs of games that are hidden, for raw views that keep
+// them.
+func (s *Server) hiddenIDs(games []Game) []string {
+	ids := []string{}
+	for _, g := range games {
+		if s.hidden.has(g.ID) {
+			ids = append(ids, g.ID)
+		}
+	}
+	return ids
+}
+
+func (s *Server) reloadHidden() error {
+	rows, err := s.store.Query(`SELECT game_id, reason, hidden_at FROM game_hidden`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var hs []hiddenGame
+	for rows.Next() {
+		var h hiddenGame
+		var at int64
+		if err := rows.Scan(&h.GameID, &h.Reason, &at); err != nil {
+			return err
+		}
+		h.HiddenAt = time.Unix(at, 0).UTC()
+		hs = append(hs, h)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.hidden.replace(hs)
+	return nil
+}
+
+// adminHiddenHandler lists (GET), hides (PUT/POST JSON {gameId, reason}) and
+// unhides (DELETE ?gameid=) games.
+func (s *Server) adminHiddenHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	if !requireAdmin(w, r) || !requireSameOrigin(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.hidden.list())
+
+	case http.MethodPut, http.MethodPost:
+		var h hiddenGame
+		if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Detail: "Invalid JSON body"})
+			return
+		}
+		h.GameID, h.Reason = strings.TrimSpace(h.GameID), strings.TrimSpace(h.Reason)
+		if h.GameID == "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "gameId is required"})
+			return
+		}
+		h.HiddenAt = time.Now().UTC().Truncate(time.Second)
+		_, err := s.store.Exec(`INSERT INTO game_hidden (game_id, reason, hidden_at) VALUES (?, ?, ?)
+			ON CONFLICT (game_id) DO UPDATE SET reason = excluded.reason`,
+			h.GameID, h.Reason, h.HiddenAt.Unix())
+		if !s.hiddenChanged(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusOK, h)
+
+	case http.MethodDelete:
+		gameID := strings.TrimSpace(r.URL.Query().Get("gameid"))
+		if gameID == "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "gameid is required"})
+			return
+		}
+		res, err := s.store.Exec(`DELETE FROM game_hidden WHERE game_id = ?`, gameID)
+		if err == nil {
+			if n, _ := res.RowsAffected(); n == 0 {
+				writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "Game " + gameID + " is not hidden"})
+				return
+			}
+		}
+		if !s.hiddenChanged(w, err) {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{
+			Error:  "method_not_allowed",
+			Detail: "Use GET, PUT, POST or DELETE",
+		})
+	}
+}
+
+// hiddenChanged reloads the index after a write, reporting any failure.
+func (s *Server) hiddenChanged(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		err = s.reloadHidden()
+	}
+	if err != nil {
+		log.Printf("hidden games: %v", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ---------- iCalendar output ---------- */
+
+const (
+	icsTimeLayout   = "20060102T150405Z"
+	icsLocalLayout  = "20060102T150405" // with a TZID parameter
+	defaultGameSpan = 90 * time.Minute  // when the age group is unknown
+)
+
+// gameKickoff combines a game's Date ("2006-01-02") and Time ("1:00PM PDT")
+// into an event-time instant.
+func gameKickoff(g Game) (time.Time, bool) {
+	clock, abbr := strings.ToUpper(strings.TrimSpace(g.Time)), ""
+	if f := strings.Fields(clock); len(f) > 0 {
+		clock = f[0] // the location handles DST; the abbreviation only disambiguates
+		if len(f) > 1 {
+			abbr = f[1]
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02 3:04PM", g.Date+" "+clock, eventLocation())
+	if err != nil {
+		return time.Time{}, false
+	}
+	// The hour repeated when clocks fall back reads the same in both offsets;
+	// "1:30AM PDT" and "1:30AM PST" are an hour apart.
+	if abbr != "" && t.Format("MST") != abbr {
+		for _, d := range []time.Duration{-time.Hour, time.Hour} {
+			if alt := t.Add(d); alt.Format("MST") == abbr && alt.Format("15:04") == t.Format("15:04") {
+				return alt, true
+			}
+		}
+	}
+	return t, true
+}
+
+// gameID hashes a fixture's teams and scheduled kickoff, so it is stable
+// across scrapes. It keys the admin annotation store.
+func gameID(g Game) string {
+	sum := sha1.Sum([]byte(strings.ToLower(g.HomeTeam + "|" + g.AwayTeam + "|" + g.Date + "|" + g.Time)))
+	return hex.EncodeToString(sum[:10])
+}
+
+// gameUID is stable across scrapes so calendar clients update events in place.
+func gameUID(g Game) string {
+	if g.ID != "" {
+		return g.ID + "@gotsport-api"
+	}
+	return gameID(g) + "@gotsport-api"
+}
+
+// parseAlarms reads alarm=60m or alarm=1d,60m (bare numbers are minutes).
+func parseAlarms(s string) ([]time.Duration, error) {
+	var out []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var d time.Duration
+		if n, err := strconv.Atoi(part); err == nil {
+			d = time.Duration(n) * time.Minute
+		} else if strings.HasSuffix(part, "d") {
+			n, err := strconv.Atoi(strings.TrimSuffix(part, "d"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid alarm %q", part)
+			}
+			d = time.Duration(n) * 24 * time.Hour
+		} else if d, err = time.ParseDuration(part); err != nil {
+			return nil, fmt.Errorf("invalid alarm %q (use e.g. 60m, 2h, 1d)", part)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("alarm %q must be positive", part)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+func writeICS(w http.ResponseWriter, games []Game, alarms []time.Duration) {
+	var b strings.Builder
+	line := func(s string) { b.WriteString(foldICSLine(s)) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//RenoApex//GotSport Parser//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+
+	// Times are local to the event zone with a TZID reference, described by
+	// a VTIMEZONE, so subscribers in other zones and across DST changes see
+	// the kickoff the club printed.
+	loc := eventLocation()
+	tzid := loc.String()
+	var starts []time.Time
+	for _, g := range games {
+		if start, ok := gameKickoff(g); ok {
+			starts = append(starts, start)
+		}
+	}
+	if len(starts) > 0 {
+		line("X-WR-TIMEZONE:" + tzid)
+		for _, l := range vtimezone(loc, starts) {
+			line(l)
+		}
+	}
+
+	stamp := time.Now().UTC().Format(icsTimeLayout)
+	for _, g := range games {
+		start, ok := gameKickoff(g)
+		if !ok {
+			continue
+		}
+		line("BEGIN:VEVENT")
+		line("UID:" + gameUID(g))
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;TZID=" + tzid + ":" + start.In(loc).Format(icsLocalLayout))
+		line("DTEND;TZID=" + tzid + ":" + start.Add(gameDuration(g, start)).In(loc).Format(icsLocalLayout))
+		line("SUMMARY:" + escapeICS(g.HomeTeam+" vs "+g.AwayTeam))
+		if g.Location != "" {
+			line("LOCATION:" + escapeICS(gameLocation(g)))
+		}
+		if desc := gameDescription(g); desc != "" {
+			line("DESCRIPTION:" + escapeICS(desc))
+		}
+		if g.Status == "cancelled" {
+			line("STATUS:CANCELLED")
+		}
+		for _, a := range alarms {
+			line("BEGIN:VALARM")
+			line("ACTION:DISPLAY")
+			line("TRIGGER:" + icsDuration(-a))
+			line("DESCRIPTION:" + escapeICS(g.HomeTeam+" vs "+g.AwayTeam))
+			line("END:VALARM")
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="schedule.ics"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// gameDescription is the calendar DESCRIPTION: the division, the kit, then
+// the venue's logistics one per line.
+func gameDescription(g Game) string {
+	lines := []string{}
+	if g.Division != "" {
+		lines = append(lines, g.Division)
+	}
+	if g.KitColor != "" {
+		lines = append(lines, "Kit: "+g.KitColor)
+	}
+	if g.Logistics != nil {
+		lines = append(lines, g.Logistics.lines()...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// gameLocation is the calendar LOCATION: the field name plus, when known,
+// the street address so calendar apps can geocode it.
+func gameLocation(g Game) string {
+	if g.Address == "" {
+		return g.Location
+	}
+	return g.Location + ", " + g.Address
+}
+
+// vtimezone describes loc over the calendar years of starts: the observance
+// in force on January 1 of the first year, then every offset change through
+// the last year, found by scanning the zone rather than assuming US rules.
+func vtimezone(loc *time.Location, starts []time.Time) []string {
+	first, last := starts[0].In(loc).Year(), starts[0].In(loc).Year()
+	for _, s := range starts[1:] {
+		y := s.In(loc).Year()
+		first, last = min(first, y), max(last, y)
+	}
+	from := time.Date(first, 1, 1, 0, 0, 0, 0, loc)
+	to := time.Date(last+1, 1, 1, 0, 0, 0, 0, loc)
+
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + loc.String()}
+	_, offset := from.Zone()
+	lines = append(lines, observance(from, offset)...)
+	for t := from; t.Before(to); {
+		next := t.Add(24 * time.Hour)
+		if _, o := next.Zone(); o != offset {
+			at := zoneTransition(t, next)
+			lines = append(lines, observance(at, offset)...)
+			offset = o
+		}
+		t = next
+	}
+	return append(lines, "END:VTIMEZONE")
+}
+
+// zoneTransition finds, to the second, when the offset changes in (lo, hi].
+func zoneTransition(lo, hi time.Time) time.Time {
+	_, before := lo.Zone()
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if _, o := mid.Zone(); o == before {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+// observance is a STANDARD or DAYLIGHT block for the offset in force from
+// at, whose DTSTART is local time in the previous offset (RFC 5545 3.6.5).
+func observance(at time.Time, offsetFrom int) []string {
+	name, offsetTo := at.Zone()
+	kind := "STANDARD"
+	if at.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	return []string{
+		"BEGIN:" + kind,
+		"DTSTART:" + at.UTC().Add(time.Duration(offsetFrom)*time.Second).Format(icsLocalLayout),
+		"TZOFFSETFROM:" + icsOffset(offsetFrom),
+		"TZOFFSETTO:" + icsOffset(offsetTo),
+		"TZNAME:" + name,
+		"END:" + kind,
+	}
+}
+
+// icsOffset renders seconds east of UTC as an RFC 5545 UTC offset, e.g. -0700.
+func icsOffset(sec int) string {
+	sign := "+"
+	if sec < 0 {
+		sign, sec = "-", -sec
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, sec/3600, sec%3600/60)
+}
+
+// icsDuration renders d as an RFC 5545 duration, e.g. -PT60M or -P1D.
+func icsDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%sP%dD", sign, d/(24*time.Hour))
+	}
+	return fmt.Sprintf("%sPT%dM", sign, d/time.Minute)
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "")
+
+func escapeICS(s string) string { return icsEscaper.Replace(s) }
+
+// foldICSLine splits content lines longer than 75 octets and appends CRLF.
+func foldICSLine(s string) string {
+	const limit = 75
+	if len(s) <= limit {
+		return s + "\r\n"
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > limit {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
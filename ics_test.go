@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Kickoffs either side of the 2025 US DST changes (March 9 and November 2,
+// 2:00AM local) must keep the wall-clock time GotSport printed.
+func TestGameKickoffDST(t *testing.T) {
+	for _, tc := range []struct {
+		date, time string
+		want       string // UTC
+	}{
+		{"2025-03-08", "10:00AM PST", "2025-03-08T18:00:00Z"},
+		{"2025-03-09", "10:00AM PDT", "2025-03-09T17:00:00Z"},
+		{"2025-03-09", "1:30AM PST", "2025-03-09T09:30:00Z"},
+		{"2025-11-01", "10:00AM PDT", "2025-11-01T17:00:00Z"},
+		{"2025-11-02", "10:00AM PST", "2025-11-02T18:00:00Z"},
+		// 1:30AM happens twice on November 2; the abbreviation says which.
+		{"2025-11-02", "1:30AM PDT", "2025-11-02T08:30:00Z"},
+		{"2025-11-02", "1:30AM PST", "2025-11-02T09:30:00Z"},
+	} {
+		got, ok := gameKickoff(Game{Date: tc.date, Time: tc.time})
+		if !ok {
+			t.Errorf("%s %s: not parsed", tc.date, tc.time)
+			continue
+		}
+		if s := got.UTC().Format(time.RFC3339); s != tc.want {
+			t.Errorf("%s %s = %s, want %s", tc.date, tc.time, s, tc.want)
+		}
+	}
+}
+
+func TestICSTimezoneAcrossDST(t *testing.T) {
+	games := []Game{
+		{HomeTeam: "Reno Apex 2012B", AwayTeam: "Placer United 2012B", Date: "2025-03-08", Time: "10:00AM PST"},
+		{HomeTeam: "Reno Apex 2012B", AwayTeam: "Davis Legacy 2012B", Date: "2025-03-09", Time: "10:00AM PDT"},
+		{HomeTeam: "Reno Apex 2012B", AwayTeam: "Folsom Lake Surf 2012B", Date: "2025-11-02", Time: "1:00AM PDT"},
+		{HomeTeam: "Reno Apex 2012B", AwayTeam: "Elk Grove United 2012B", Date: "2025-11-02", Time: "10:00AM PST"},
+	}
+	rec := httptest.NewRecorder()
+	writeICS(rec, games, nil)
+	ics := strings.ReplaceAll(rec.Body.String(), "\r\n", "\n")
+
+	for _, want := range []string{
+		// Events keep local wall-clock times, referenced to the zone.
+		"DTSTART;TZID=America/Los_Angeles:20250308T100000\n",
+		"DTSTART;TZID=America/Los_Angeles:20250309T100000\n",
+		"DTSTART;TZID=America/Los_Angeles:20251102T100000\n",
+		// A U14 game (70 minutes plus a 10-minute buffer) starting 1:00AM PDT
+		// on November 2 ends 1:20AM PST.
+		"DTSTART;TZID=America/Los_Angeles:20251102T010000\nDTEND;TZID=America/Los_Angeles:20251102T012000\n",
+		// The zone definition covers both 2025 transitions.
+		"BEGIN:VTIMEZONE\nTZID:America/Los_Angeles\n",
+		"BEGIN:DAYLIGHT\nDTSTART:20250309T020000\nTZOFFSETFROM:-0800\nTZOFFSETTO:-0700\nTZNAME:PDT\nEND:DAYLIGHT\n",
+		"BEGIN:STANDARD\nDTSTART:20251102T020000\nTZOFFSETFROM:-0700\nTZOFFSETTO:-0800\nTZNAME:PST\nEND:STANDARD\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("ICS missing %q\n%s", want, ics)
+		}
+	}
+	for _, l := range strings.Split(ics, "\n") {
+		if (strings.HasPrefix(l, "DTSTART:") || strings.HasPrefix(l, "DTEND:")) && strings.HasSuffix(l, "Z") {
+			t.Errorf("event time is UTC rather than zone-referenced: %s", l)
+		}
+	}
+}
+
+func TestVTimezoneWithoutDST(t *testing.T) {
+	phoenix, err := time.LoadLocation("America/Phoenix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := vtimezone(phoenix, []time.Time{time.Date(2025, 3, 9, 10, 0, 0, 0, phoenix)})
+	got := strings.Join(lines, "\n")
+	want := "BEGIN:VTIMEZONE\nTZID:America/Phoenix\nBEGIN:STANDARD\nDTSTART:20250101T000000\n" +
+		"TZOFFSETFROM:-0700\nTZOFFSETTO:-0700\nTZNAME:MST\nEND:STANDARD\nEND:VTIMEZONE"
+	if got != want {
+		t.Errorf("vtimezone(Phoenix) =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,162 @@
+// Package fixtures loads the saved upstream pages under testdata/fixtures and
+// compares parser output against their golden expected.json files.
+//
+// Layout, one directory per fixture:
+//
+//	testdata/fixtures/<source>/<name>/page.html      saved upstream page
+//	testdata/fixtures/<source>/<name>/meta.json      {"eventid","clubid","asOf","note","view"}
+//	testdata/fixtures/<source>/<name>/expected.json  golden parser output
+//
+// Run tests with UPDATE_GOLDEN=1 (or `make update-golden`) to rewrite the
+// expected.json files from the current parser.
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Update is true when golden files should be rewritten rather than compared.
+var Update = os.Getenv("UPDATE_GOLDEN") != ""
+
+// Meta describes where and when a fixture page was captured.
+type Meta struct {
+	EventID string    `json:"eventid"`
+	ClubID  string    `json:"clubid"`
+	AsOf    time.Time `json:"asOf"` // clock to parse as of; pages are weekend-relative
+	Note    string    `json:"note,omitempty"`
+	View    string    `json:"view,omitempty"` // "export" when page.html holds a CSV export view
+}
+
+// Fixture is one saved page and its golden output.
+type Fixture struct {
+	Source string // gotsport, ecnl, ...
+	Name   string
+	Dir    string
+	Meta   Meta
+	Page   []byte
+	// Expected is expected.json, or nil when the fixture has none yet.
+	Expected []byte
+}
+
+// Load reads every fixture under root, sorted by source then name.
+func Load(root string) ([]Fixture, error) {
+	out, err := LoadFS(os.DirFS(root))
+	for i := range out {
+		out[i].Dir = filepath.Join(root, filepath.FromSlash(out[i].Dir))
+	}
+	return out, err
+}
+
+// LoadFS is Load for fixtures laid out at the root of fsys, such as an
+// embedded copy of testdata/fixtures. Dir is then a path within fsys.
+func LoadFS(fsys fs.FS) ([]Fixture, error) {
+	pages, err := fs.Glob(fsys, "*/*/page.html")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(pages)
+	out := make([]Fixture, 0, len(pages))
+	for _, page := range pages {
+		dir := path.Dir(page)
+		f := Fixture{
+			Source: path.Base(path.Dir(dir)),
+			Name:   path.Base(dir),
+			Dir:    dir,
+		}
+		if f.Page, err = fs.ReadFile(fsys, page); err != nil {
+			return nil, err
+		}
+		raw, err := fs.ReadFile(fsys, path.Join(dir, "meta.json"))
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s/%s: %v", f.Source, f.Name, err)
+		}
+		if err := json.Unmarshal(raw, &f.Meta); err != nil {
+			return nil, fmt.Errorf("fixture %s/%s: meta.json: %v", f.Source, f.Name, err)
+		}
+		f.Expected, err = fs.ReadFile(fsys, path.Join(dir, "expected.json"))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("fixture %s/%s: %v", f.Source, f.Name, err)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// ID is the fixture's "<source>/<name>" label, used as the subtest name.
+func (f Fixture) ID() string { return f.Source + "/" + f.Name }
+
+// Golden compares got, encoded as indented JSON, with expected.json. With
+// Update set it rewrites the file instead.
+func (f Fixture) Golden(t testing.TB, got any) {
+	t.Helper()
+	want := filepath.Join(f.Dir, "expected.json")
+	if Update {
+		enc, err := encode(got)
+		if err != nil {
+			t.Fatalf("encode output: %v", err)
+		}
+		if err := os.WriteFile(want, enc, 0o644); err != nil {
+			t.Fatalf("update golden: %v", err)
+		}
+		return
+	}
+	if f.Expected == nil {
+		t.Fatalf("%s: no expected.json (run with UPDATE_GOLDEN=1 to create it)", f.ID())
+	}
+	if diff, err := f.Diff(got); err != nil {
+		t.Fatalf("encode output: %v", err)
+	} else if diff != "" {
+		t.Errorf("%s: output differs from expected.json (run with UPDATE_GOLDEN=1 to accept)\n%s", f.ID(), diff)
+	}
+}
+
+// Diff compares got, encoded as Golden encodes it, with Expected and
+// returns the lines that differ, or "" when they match.
+func (f Fixture) Diff(got any) (string, error) {
+	enc, err := encode(got)
+	if err != nil {
+		return "", err
+	}
+	if bytes.Equal(f.Expected, enc) {
+		return "", nil
+	}
+	return lineDiff(string(f.Expected), string(enc)), nil
+}
+
+func encode(got any) ([]byte, error) {
+	enc, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(enc, '\n'), nil
+}
+
+// lineDiff is a minimal -want/+got listing of the lines that differ.
+func lineDiff(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var b strings.Builder
+	for i := 0; i < len(w) || i < len(g); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			fmt.Fprintf(&b, "line %d:\n  -%s\n  +%s\n", i+1, wl, gl)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* ---------- Tournament itinerary ---------- */
+
+// bracketSlotPattern matches knockout placeholders that stand in for a team
+// until the group stage is decided: "Winner Match 12", "Loser of SF1",
+// "1st Group A", "Group B Runner-up", "TBD".
+var bracketSlotPattern = regexp.MustCompile(`(?i)^(?:winner|loser|runner[- ]?up|wild ?card|tb[ad]|[1-9](?:st|nd|rd|th)(?: place)?)\b|\b(?:winner|runner[- ]?up|[1-9](?:st|nd|rd|th)(?: place)?)$`)
+
+// isBracketSlot reports whether team is a placeholder rather than a team.
+func isBracketSlot(team string) bool {
+	return bracketSlotPattern.MatchString(strings.TrimSpace(team))
+}
+
+// itineraryItem is one stop in a team's tournament: check-in before a day's
+// first game, one of the team's games, or a bracket slot it may play in if
+// it advances.
+type itineraryItem struct {
+	Kind     string `json:"kind"`             // "checkin", "game" or "bracket"
+	Number   int    `json:"number,omitempty"` // games only, from 1
+	Date     string `json:"date"`
+	Time     string `json:"time"`
+	EndTime  string `json:"endTime,omitempty"`
+	HomeTeam string `json:"homeTeam,omitempty"`
+	AwayTeam string `json:"awayTeam,omitempty"`
+	// Opponent is the other side of a game, which may itself be a
+	// placeholder such as "Winner Group B".
+	Opponent string `json:"opponent,omitempty"`
+	Location string `json:"location,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Division string `json:"division,omitempty"`
+	// GapMinutes is the time since the team's previous game that day ended;
+	// negative when the two overlap.
+	GapMinutes *int `json:"gapMinutes,omitempty"`
+}
+
+type itinerary struct {
+	EventID  string          `json:"eventid"`
+	TeamID   string          `json:"team"`
+	TeamName string          `json:"teamName,omitempty"`
+	Items    []itineraryItem `json:"items"`
+}
+
+// clubSide returns the side of g that is the club's team (CLUB_NAME), or ""
+// when neither is.
+func clubSide(g Game) string {
+	for _, team := range []string{g.HomeTeam, g.AwayTeam} {
+		if isClubTeam(team, appConfig.ClubName) {
+			return team
+		}
+	}
+	return ""
+}
+
+// buildItinerary orders games by kickoff, adds a check-in CheckinLead before
+// each day's first game and works out the rest between games. Games whose
+// kickoff can't be read are left out.
+func buildItinerary(eventID, teamID string, games []Game) itinerary {
+	type timed struct {
+		at time.Time
+		g  Game
+	}
+	var list []timed
+	for _, g := range games {
+		if at, ok := gameKickoff(g); ok {
+			list = append(list, timed{at, g})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].at.Before(list[j].at) })
+
+	it := itinerary{EventID: eventID, TeamID: teamID, Items: []itineraryItem{}}
+	var prevEnd time.Time
+	day, n, checkedIn := "", 0, false
+	for _, t := range list {
+		g := t.g
+		team := clubSide(g)
+		if it.TeamName == "" {
+			it.TeamName = team
+		}
+		item := itineraryItem{
+			Kind:     "bracket",
+			Date:     g.Date,
+			Time:     g.Time,
+			EndTime:  g.EndTime,
+			HomeTeam: g.HomeTeam,
+			AwayTeam: g.AwayTeam,
+			Location: g.Location,
+			Address:  g.Address,
+			Division: g.Division,
+		}
+		if g.Date != day {
+			day, prevEnd, checkedIn = g.Date, time.Time{}, false
+		}
+		if team != "" && !checkedIn {
+			checkedIn = true
+			checkin := t.at.Add(-appConfig.CheckinLead)
+			it.Items = append(it.Items, itineraryItem{
+				Kind:     "checkin",
+				Date:     checkin.Format("2006-01-02"),
+				Time:     checkin.Format("3:04PM MST"),
+				Location: g.Location,
+				Address:  g.Address,
+			})
+		}
+		if !prevEnd.IsZero() {
+			gap := int(t.at.Sub(prevEnd) / time.Minute)
+			item.GapMinutes = &gap
+		}
+		if team != "" {
+			n++
+			item.Kind, item.Number = "game", n
+			item.Opponent = g.AwayTeam
+			if team == g.AwayTeam {
+				item.Opponent = g.HomeTeam
+			}
+			prevEnd = t.at.Add(gameDuration(g, t.at))
+		}
+		it.Items = append(it.Items, item)
+	}
+	return it
+}
+
+// itineraryHandler serves /itinerary?eventid=44145&team=123456[&refresh=true]:
+// the team's whole tournament, home and away, with check-ins, gaps between
+// games and the bracket slots listed on its schedule page.
+func (s *Server) itineraryHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	eventID, teamID := q.Get("eventid"), q.Get("team")
+	if eventID == "" || teamID == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "missing_parameters",
+			Detail: "eventid and team are required",
+		})
+		return
+	}
+	f := scheduleFilter{Team: teamID, AllSides: true}
+	if err := f.validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	refresh := q.Get("refresh") == "true"
+	res, err := s.getSchedule(r.Context(), eventID, "", f, refresh, s.cache.maxAge())
+	if errors.Is(err, errScrapeQueueFull) {
+		rejectOverloaded(w, http.StatusTooManyRequests, "scrape_queue",
+			"Scrape queue is full; retry shortly")
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	setCacheHeaders(w, res)
+	writeJSON(w, http.StatusOK, buildItinerary(eventID, teamID, res.Games))
+}
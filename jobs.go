@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+/* ---------- Durable job queue ---------- */
+
+// jobHandler runs one job. Returning an error schedules a retry with backoff.
+type jobHandler func(ctx context.Context, payload json.RawMessage) error
+
+type jobOptions struct {
+	// DedupeKey skips the enqueue if an unfinished job of the same kind has it.
+	DedupeKey   string
+	RunAt       time.Time
+	MaxAttempts int
+}
+
+const (
+	jobPending = "pending"
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+
+	defaultJobAttempts = 8
+	jobBackoffBase     = 30 * time.Second
+	jobBackoffMax      = time.Hour
+	jobPollInterval    = time.Second
+	jobTimeout         = 2 * time.Minute
+	// jobStaleAfter is how long a job may stay running before it is taken
+	// for abandoned by a process that died, well past jobTimeout so no live
+	// attempt, on this replica or another, is requeued.
+	jobStaleAfter = 2 * jobTimeout
+)
+
+var (
+	jobHandlersMu sync.RWMutex
+	jobHandlers   = map[string]jobHandler{}
+)
+
+func registerJobHandler(kind string, h jobHandler) {
+	jobHandlersMu.Lock()
+	jobHandlers[kind] = h
+	jobHandlersMu.Unlock()
+}
+
+func lookupJobHandler(kind string) jobHandler {
+	jobHandlersMu.RLock()
+	defer jobHandlersMu.RUnlock()
+	return jobHandlers[kind]
+}
+
+func enqueueJob(kind string, payload any, opts jobOptions) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode %s payload: %v", kind, err)
+	}
+	now := time.Now()
+	runAt := opts.RunAt
+	if runAt.IsZero() {
+		runAt = now
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultJobAttempts
+	}
+	if opts.DedupeKey != "" {
+		var n int
+		err := db.QueryRow(`SELECT COUNT(*) FROM jobs WHERE kind = ? AND dedupe_key = ? AND status IN (?, ?)`,
+			kind, opts.DedupeKey, jobPending, jobRunning).Scan(&n)
+		if err != nil {
+			return fmt.Errorf("enqueue %s: %v", kind, err)
+		}
+		if n > 0 {
+			return nil
+		}
+	}
+	_, err = db.Exec(`INSERT INTO jobs (kind, dedupe_key, payload, max_attempts, run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		kind, opts.DedupeKey, string(body), maxAttempts, runAt.UnixMilli(), now.UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("enqueue %s: %v", kind, err)
+	}
+	return nil
+}
+
+// jobBackoff returns the delay before retry number attempt (1-based).
+func jobBackoff(attempt int) time.Duration {
+	d := jobBackoffBase
+	for i := 1; i < attempt && d < jobBackoffMax; i++ {
+		d *= 2
+	}
+	if d > jobBackoffMax {
+		d = jobBackoffMax
+	}
+	return d
+}
+
+// runJobWorker processes due jobs until ctx is cancelled. Several replicas
+// may share the queue; each claims a job atomically, and jobs left running
+// by a process that died are put back once stale.
+func runJobWorker(ctx context.Context) {
+	requeueStaleJobs()
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	stale := time.NewTicker(jobTimeout)
+	defer stale.Stop()
+	for {
+		for {
+			ran, err := runNextJob(ctx)
+			if err != nil {
+				log.Printf("jobs: %v", err)
+			}
+			if !ran || ctx.Err() != nil {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-stale.C:
+			requeueStaleJobs()
+		}
+	}
+}
+
+// requeueStaleJobs puts jobs running for longer than jobStaleAfter back in
+// the queue.
+func requeueStaleJobs() {
+	cutoff := time.Now().Add(-jobStaleAfter).UnixMilli()
+	if _, err := db.Exec(`UPDATE jobs SET status = ? WHERE status = ? AND updated_at < ?`, jobPending, jobRunning, cutoff); err != nil {
+		log.Printf("jobs: requeue interrupted jobs: %v", err)
+	}
+}
+
+func runNextJob(ctx context.Context) (bool, error) {
+	var (
+		id                    int64
+		kind, payload         string
+		attempts, maxAttempts int
+	)
+	now := time.Now()
+	// The outer status check makes the claim atomic across replicas: under
+	// Postgres's READ COMMITTED a second claimer of the same row re-reads it
+	// after the first commits, finds it running, and claims nothing.
+	err := db.QueryRow(`UPDATE jobs SET status = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id = (SELECT id FROM jobs WHERE status = ? AND run_at <= ? ORDER BY run_at, id LIMIT 1) AND status = ?
+		RETURNING id, kind, payload, attempts, max_attempts`,
+		jobRunning, now.UnixMilli(), jobPending, now.UnixMilli(), jobPending).Scan(&id, &kind, &payload, &attempts, &maxAttempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claim job: %v", err)
+	}
+
+	h := lookupJobHandler(kind)
+	if h == nil {
+		err = fmt.Errorf("no handler for job kind %q", kind)
+	} else {
+		jctx, cancel := context.WithTimeout(ctx, jobTimeout)
+		err = runJobHandler(jctx, h, kind, id, json.RawMessage(payload))
+		cancel()
+	}
+
+	if err == nil {
+		_, err = db.Exec(`UPDATE jobs SET status = ?, last_error = '', updated_at = ? WHERE id = ?`,
+			jobDone, time.Now().UnixMilli(), id)
+		return true, err
+	}
+	if attempts >= maxAttempts {
+		log.Printf("jobs: %s #%d failed permanently after %d attempts: %v", kind, id, attempts, err)
+		_, err = db.Exec(`UPDATE jobs SET status = ?, last_error = ?, updated_at = ? WHERE id = ?`,
+			jobFailed, err.Error(), time.Now().UnixMilli(), id)
+		return true, err
+	}
+	retryAt := time.Now().Add(jobBackoff(attempts))
+	log.Printf("jobs: %s #%d attempt %d failed, retrying at %s: %v", kind, id, attempts, retryAt.Format(time.RFC3339), err)
+	_, err = db.Exec(`UPDATE jobs SET status = ?, last_error = ?, run_at = ?, updated_at = ? WHERE id = ?`,
+		jobPending, err.Error(), retryAt.UnixMilli(), time.Now().UnixMilli(), id)
+	return true, err
+}
+
+// runJobHandler runs h, turning a panic into an error so the attempt fails
+// and is retried like any other instead of taking the process down.
+func runJobHandler(ctx context.Context, h jobHandler, kind string, id int64, payload json.RawMessage) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			logPanic("job", fmt.Sprintf("job %s #%d", kind, id), p)
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return h(ctx, payload)
+}
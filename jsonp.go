@@ -0,0 +1,28 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+)
+
+/* ---------- JSONP ---------- */
+
+// jsonpCallback accepts plain or dotted JavaScript identifiers only.
+var jsonpCallback = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+
+// writeJSONP wraps v in a call to callback for legacy script-tag embeds.
+func writeJSONP(w http.ResponseWriter, status int, callback string, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "encode_failed", Detail: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	// The leading comment defeats content-sniffing attacks on the callback name.
+	_, _ = w.Write([]byte("/**/" + callback + "("))
+	_, _ = w.Write(body)
+	_, _ = w.Write([]byte(");"))
+}
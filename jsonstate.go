@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ---------- Embedded JSON state ---------- */
+
+var (
+	// initialStatePattern captures window.__INITIAL_STATE__ = {...} or
+	// = JSON.parse("...") up to the end of its script element.
+	initialStatePattern = regexp.MustCompile(`(?is)window\.__INITIAL_STATE__\s*=\s*(.+?);?\s*</script>`)
+	jsonScriptPattern   = regexp.MustCompile(`(?is)<script\b[^>]*\btype\s*=\s*["']application/(?:ld\+)?json["'][^>]*>(.*?)</script>`)
+	jsonParseCall       = regexp.MustCompile(`(?s)^JSON\.parse\(\s*("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*')\s*\)$`)
+)
+
+// matchKeys name the fields a schedule object carries; the first present
+// key wins. Objects need a home team, an away team and a kickoff to count.
+var matchKeys = map[string][]string{
+	"home":     {"home_team", "homeTeam", "home_team_name", "homeTeamName", "home", "team_home"},
+	"away":     {"away_team", "awayTeam", "away_team_name", "awayTeamName", "away", "team_away"},
+	"kickoff":  {"start_time", "startTime", "start_at", "startAt", "kickoff", "datetime", "match_time", "start", "date"},
+	"time":     {"time", "start_hour"},
+	"id":       {"match_number", "matchNumber", "number", "match_id", "matchId", "id"},
+	"venue":    {"venue", "venue_name", "venueName", "complex", "location"},
+	"field":    {"field", "field_name", "fieldName", "pitch"},
+	"division": {"division", "division_name", "bracket", "group", "flight", "age_group"},
+	"homeGoal": {"home_score", "homeScore", "home_goals", "homeGoals"},
+	"awayGoal": {"away_score", "awayScore", "away_goals", "awayGoals"},
+	"homeId":   {"home_team_id", "homeTeamId"},
+	"awayId":   {"away_team_id", "awayTeamId"},
+	"result":   {"result", "score"},
+	"link":     {"url", "match_url", "matchUrl", "href", "link"},
+}
+
+// embeddedDocuments returns every JSON document embedded in the page.
+func embeddedDocuments(html string) []any {
+	var raws []string
+	for _, m := range initialStatePattern.FindAllStringSubmatch(html, -1) {
+		raw := strings.TrimSpace(m[1])
+		if pm := jsonParseCall.FindStringSubmatch(raw); pm != nil {
+			lit := pm[1]
+			if strings.HasPrefix(lit, "'") {
+				lit = `"` + strings.ReplaceAll(strings.ReplaceAll(lit[1:len(lit)-1], `\'`, `'`), `"`, `\"`) + `"`
+			}
+			if err := json.Unmarshal([]byte(lit), &raw); err != nil {
+				log.Printf("embedded state: bad JSON.parse literal: %v", err)
+				continue
+			}
+		}
+		raws = append(raws, raw)
+	}
+	for _, m := range jsonScriptPattern.FindAllStringSubmatch(html, -1) {
+		raws = append(raws, m[1])
+	}
+
+	var docs []any
+	for _, raw := range raws {
+		var v any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &v); err != nil {
+			log.Printf("embedded state: %v", err)
+			continue
+		}
+		docs = append(docs, v)
+	}
+	return docs
+}
+
+// collectMatches walks v depth-first, gathering objects shaped like a match.
+func collectMatches(v any, out *[]map[string]any) {
+	switch t := v.(type) {
+	case map[string]any:
+		if firstKey(t, "home") != "" && firstKey(t, "away") != "" && firstKey(t, "kickoff") != "" {
+			*out = append(*out, t)
+			return
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys) // maps are unordered; keep row numbers stable
+		for _, k := range keys {
+			collectMatches(t[k], out)
+		}
+	case []any:
+		for _, e := range t {
+			collectMatches(e, out)
+		}
+	}
+}
+
+// firstKey returns the first of role's keys present in m.
+func firstKey(m map[string]any, role string) string {
+	for _, k := range matchKeys[role] {
+		if v, ok := m[k]; ok && v != nil {
+			return k
+		}
+	}
+	return ""
+}
+
+// field returns role's value in m as display text, plus the key it came from.
+func field(m map[string]any, role string) (string, string) {
+	k := firstKey(m, role)
+	if k == "" {
+		return "", ""
+	}
+	return normalizeText(jsonText(m[k])), k
+}
+
+// jsonText renders a scalar, or the name of an object such as
+// {"id": 3001, "name": "Reno Apex 2012B"}.
+func jsonText(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case map[string]any:
+		for _, k := range []string{"name", "display_name", "displayName", "full_name", "title", "label"} {
+			if s, ok := t[k].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// jsonTeamID returns the team ID of a match side: the id of the side's team
+// object, as in {"home_team": {"id": 3001, ...}}, or else a separate
+// home_team_id style field (idRole).
+func jsonTeamID(m map[string]any, nameKey, idRole string) string {
+	if t, ok := m[nameKey].(map[string]any); ok {
+		if id := jsonText(t["id"]); digitsOnly.MatchString(id) {
+			return id
+		}
+	}
+	if id, _ := field(m, idRole); digitsOnly.MatchString(id) {
+		return id
+	}
+	return ""
+}
+
+// jsonGameLink returns the game page a match object links to.
+func jsonGameLink(m map[string]any) string {
+	link, _ := field(m, "link")
+	return gamePageURL(link)
+}
+
+// jsonKickoff reads an RFC 3339 timestamp, a zone-less local timestamp, a
+// date plus separate time or, failing those, any form parseDateTime knows,
+// returning the Game date and time in event time.
+func jsonKickoff(kickoff, clock string) (string, string) {
+	pt := eventLocation()
+	var at time.Time
+	var err error
+	if at, err = time.Parse(time.RFC3339, kickoff); err != nil {
+		at, err = time.ParseInLocation("2006-01-02T15:04:05", kickoff, pt)
+	}
+	if err != nil && clock != "" {
+		for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02 3:04PM", "2006-01-02 3:04 PM"} {
+			if at, err = time.ParseInLocation(layout, kickoff+" "+clock, pt); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		if d, derr := time.ParseInLocation("2006-01-02", kickoff, pt); derr == nil {
+			return d.Format("2006-01-02"), "TBD"
+		}
+		// Display strings such as "Sat 3/15" with "9:00 AM".
+		if d, t := parseDateTime(kickoff + " " + clock); t != "TBD" {
+			return d, t
+		}
+		return "", "TBD"
+	}
+	at = at.In(pt)
+	return at.Format("2006-01-02"), at.Format("3:04PM MST")
+}
+
+// findEmbeddedGames is the structured counterpart of findClubGames. ok is
+// false when the page carries no recognisable schedule JSON, so the caller
+// falls back to the HTML tables.
+func findEmbeddedGames(html string, stats *parseStats) ([]candidate, map[string]bool, bool) {
+	return gamesFromDocuments(embeddedDocuments(html), "embedded_json", stats)
+}
+
+// gamesFromDocuments judges every match object found in docs. ok is false
+// when there are none.
+func gamesFromDocuments(docs []any, strategy string, stats *parseStats) ([]candidate, map[string]bool, bool) {
+	var matches []map[string]any
+	for _, doc := range docs {
+		collectMatches(doc, &matches)
+	}
+	stats.JSONMatches = len(matches)
+	if len(matches) == 0 {
+		return nil, nil, false
+	}
+	log.Printf("Found %d matches in %s", len(matches), strategy)
+
+	var games []candidate
+	rowDates := map[string]bool{}
+	for i, m := range matches {
+		home, homeKey := field(m, "home")
+		away, awayKey := field(m, "away")
+		kickoff, kickoffKey := field(m, "kickoff")
+		clock, clockKey := field(m, "time")
+		id, idKey := field(m, "id")
+		venue, venueKey := field(m, "venue")
+		pitch, pitchKey := field(m, "field")
+		division, divisionKey := field(m, "division")
+
+		result, resultKey := field(m, "result")
+		if hg, hk := field(m, "homeGoal"); hg != "" {
+			if ag, _ := field(m, "awayGoal"); ag != "" {
+				result, resultKey = hg+" - "+ag, hk
+			}
+		}
+		result = strings.Trim(strings.TrimSpace(result), "-")
+
+		location := venue
+		if pitch != "" && pitch != venue {
+			if location != "" {
+				location += " - "
+			}
+			location += pitch
+		}
+
+		d, t := jsonKickoff(kickoff, clock)
+		if t != "TBD" {
+			rowDates[d] = true
+		}
+
+		src := func(k string) string { return "json:" + k }
+		ex := rowExplain{
+			Row:      i + 1,
+			Strategy: strategy,
+			Fields: map[string]fieldSource{
+				"matchId":  {src(idKey), id},
+				"dateTime": {src(kickoffKey), strings.TrimSpace(kickoff + " " + clock)},
+				"homeTeam": {src(homeKey), home},
+				"result":   {src(resultKey), result},
+				"awayTeam": {src(awayKey), away},
+				"location": {src(venueKey + "+" + pitchKey), location},
+				"division": {src(divisionKey), division},
+				"date":     {"parsed from dateTime", d},
+				"time":     {"parsed from dateTime", t},
+			},
+		}
+		if clockKey == "" {
+			ex.Fields["dateTime"] = fieldSource{src(kickoffKey), kickoff}
+		}
+		// Structured data names the sides explicitly, as a header row would.
+		games = judgeFixture(rawFixture{
+			Home:      home,
+			Away:      away,
+			HomeID:    jsonTeamID(m, homeKey, "homeId"),
+			AwayID:    jsonTeamID(m, awayKey, "awayId"),
+			Link:      jsonGameLink(m),
+			Date:      d,
+			Time:      t,
+			Result:    result,
+			Location:  location,
+			Division:  division,
+			Confirmed: true,
+		}, ex, games, stats)
+	}
+	return games, rowDates, true
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ---------- JWT / OIDC verification ---------- */
+
+// oidcClient fetches discovery documents, key sets and tokens; it is kept
+// apart from upstreamClient so identity traffic never counts against the
+// scrape circuit breakers.
+var oidcClient = &http.Client{Timeout: 10 * time.Second}
+
+const (
+	jwksTTL         = time.Hour
+	jwksMinRefresh  = time.Minute // unknown kid refetch floor
+	jwtClockLeeway  = time.Minute
+	oidcSessionName = "admin_session"
+)
+
+// oidcProvider is the subset of an OpenID discovery document we use.
+type oidcProvider struct {
+	Issuer        string `json:"issuer"`
+	AuthEndpoint  string `json:"authorization_endpoint"`
+	TokenEndpoint string `json:"token_endpoint"`
+	JWKSURI       string `json:"jwks_uri"`
+}
+
+// jwtClaims are the ID/access token claims admin auth checks.
+type jwtClaims struct {
+	Issuer        string          `json:"iss"`
+	Subject       string          `json:"sub"`
+	Audience      json.RawMessage `json:"aud"` // string or array
+	Expires       int64           `json:"exp"`
+	NotBefore     int64           `json:"nbf"`
+	Email         string          `json:"email"`
+	EmailVerified bool            `json:"email_verified"`
+	HostedDomain  string          `json:"hd"` // Google Workspace domain
+}
+
+func (c jwtClaims) hasAudience(aud string) bool {
+	var one string
+	if json.Unmarshal(c.Audience, &one) == nil {
+		return one == aud
+	}
+	var many []string
+	if json.Unmarshal(c.Audience, &many) == nil {
+		return containsString(many, aud)
+	}
+	return false
+}
+
+var (
+	oidcMu       sync.Mutex
+	oidcDiscover *oidcProvider
+	jwksKeys     map[string]*rsa.PublicKey // by kid
+	jwksFetched  time.Time
+)
+
+// provider returns the issuer's discovery document, fetched once. JWT_JWKS_URL
+// overrides the advertised key set for issuers without discovery.
+func provider(ctx context.Context) (*oidcProvider, error) {
+	oidcMu.Lock()
+	defer oidcMu.Unlock()
+	if oidcDiscover != nil {
+		return oidcDiscover, nil
+	}
+	p := &oidcProvider{Issuer: appConfig.JWTIssuer, JWKSURI: appConfig.JWKSURL}
+	if p.JWKSURI == "" || appConfig.OIDCClientSecret != "" {
+		var doc oidcProvider
+		if err := getJSON(ctx, strings.TrimSuffix(appConfig.JWTIssuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+			return nil, fmt.Errorf("oidc discovery: %v", err)
+		}
+		p.AuthEndpoint, p.TokenEndpoint = doc.AuthEndpoint, doc.TokenEndpoint
+		if p.JWKSURI == "" {
+			p.JWKSURI = doc.JWKSURI
+		}
+	}
+	oidcDiscover = p
+	return p, nil
+}
+
+// signingKey returns the RSA key for kid, refetching the key set when it is
+// stale or the kid is new (providers rotate keys without notice).
+func signingKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	p, err := provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	oidcMu.Lock()
+	key, ok := jwksKeys[kid]
+	fresh := time.Since(jwksFetched) < jwksTTL
+	canRefetch := time.Since(jwksFetched) >= jwksMinRefresh
+	oidcMu.Unlock()
+	if ok && fresh {
+		return key, nil
+	}
+	if !canRefetch {
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, p.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %v", err)
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	oidcMu.Lock()
+	jwksKeys, jwksFetched = keys, time.Now()
+	oidcMu.Unlock()
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// verifyJWT checks an RS256 token's signature, issuer, audience and validity
+// window, returning its claims. With no audience configured every token is
+// refused, since the issuer also signs tokens minted for other apps.
+func verifyJWT(ctx context.Context, token string) (jwtClaims, error) {
+	var claims jwtClaims
+	if appConfig.JWTAudience == "" {
+		return claims, errors.New("JWT auth needs JWT_AUDIENCE or OIDC_CLIENT_ID set")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return claims, fmt.Errorf("token header: %v", err)
+	}
+	if header.Alg != "RS256" {
+		return claims, fmt.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims, errors.New("malformed signature")
+	}
+	key, err := signingKey(ctx, header.Kid)
+	if err != nil {
+		return claims, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		return claims, errors.New("bad signature")
+	}
+
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return claims, fmt.Errorf("token claims: %v", err)
+	}
+	now := time.Now()
+	switch {
+	case claims.Issuer != appConfig.JWTIssuer:
+		return claims, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	case !claims.hasAudience(appConfig.JWTAudience):
+		return claims, errors.New("token is for a different audience")
+	case claims.Expires == 0 || now.After(time.Unix(claims.Expires, 0).Add(jwtClockLeeway)):
+		return claims, errors.New("token expired")
+	case claims.NotBefore != 0 && now.Add(jwtClockLeeway).Before(time.Unix(claims.NotBefore, 0)):
+		return claims, errors.New("token not yet valid")
+	}
+	return claims, nil
+}
+
+// authorizeAdmin decides whether verified claims belong to club staff:
+// an ADMIN_EMAILS entry, or a verified address in an ADMIN_DOMAINS domain.
+func authorizeAdmin(c jwtClaims) error {
+	email := strings.ToLower(c.Email)
+	if email != "" && c.EmailVerified && containsString(appConfig.AdminEmails, email) {
+		return nil
+	}
+	domain := strings.ToLower(c.HostedDomain)
+	if domain == "" && c.EmailVerified {
+		if _, d, ok := strings.Cut(email, "@"); ok {
+			domain = d
+		}
+	}
+	if domain != "" && containsString(appConfig.AdminDomains, domain) {
+		return nil
+	}
+	return fmt.Errorf("%s is not an admin", firstNonEmpty(c.Email, c.Subject))
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := oidcClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: HTTP %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
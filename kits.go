@@ -0,0 +1,81 @@
+package main
+
+import (
+	"log"
+	"strings"
+)
+
+/* ---------- Kit colours ---------- */
+
+// kitPair is the kit a team wears at home and away.
+type kitPair struct {
+	Home, Away string
+}
+
+// parseKit reads "white/navy" as home and away kits; one colour is worn
+// for both.
+func parseKit(s string) (kitPair, bool) {
+	home, away, ok := strings.Cut(s, "/")
+	k := kitPair{Home: strings.TrimSpace(home), Away: strings.TrimSpace(away)}
+	if !ok {
+		k.Away = k.Home
+	}
+	return k, k.Home != "" && k.Away != ""
+}
+
+// parseTeamKits reads TEAM_KITS, ";"-separated team=home/away entries such
+// as "2012B=red/black;2013G=white"; teams match within club team names.
+func parseTeamKits(s string) map[string]kitPair {
+	kits := map[string]kitPair{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		team, colours, ok := strings.Cut(part, "=")
+		k, kok := parseKit(colours)
+		if team = strings.TrimSpace(team); !ok || !kok || team == "" {
+			log.Printf("ignoring malformed TEAM_KITS entry %q (want 2012B=white/navy)", part)
+			continue
+		}
+		kits[team] = k
+	}
+	return kits
+}
+
+func clubKitFromEnv() kitPair {
+	v := strings.TrimSpace(stringFromEnv("KITS", ""))
+	if v == "" {
+		return kitPair{}
+	}
+	k, ok := parseKit(v)
+	if !ok {
+		log.Printf("invalid KITS=%q (want white/navy), ignoring", v)
+	}
+	return k
+}
+
+// kitFor returns the kit team wears at home or away: its TEAM_KITS entry,
+// the longest that matches, else the club's KITS, else "".
+func kitFor(team string, home bool) string {
+	k, best := appConfig.Kits, 0
+	for name, tk := range appConfig.TeamKits {
+		if len(name) > best && containsFold(team, name) {
+			k, best = tk, len(name)
+		}
+	}
+	if home {
+		return k.Home
+	}
+	return k.Away
+}
+
+// withKits sets KitColor on the club's games, in place.
+func withKits(games []Game) []Game {
+	for i, g := range games {
+		if side := clubSide(g); side != "" {
+			games[i].KitColor = kitFor(side, side == g.HomeTeam)
+		}
+	}
+	return games
+}
@@ -0,0 +1,38 @@
+package main
+
+import "context"
+
+/* ---------- Concurrency limits ---------- */
+
+// semaphore bounds how many callers may hold a slot at once.
+type semaphore chan struct{}
+
+func newSemaphore(n int) semaphore {
+	if n < 1 {
+		n = 1
+	}
+	return make(semaphore, n)
+}
+
+// acquire blocks until a slot is free or ctx is done.
+func (s semaphore) acquire(ctx context.Context) error {
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() { <-s }
+
+// inUse reports how many slots are currently held.
+func (s semaphore) inUse() int { return len(s) }
+
+var (
+	// fetchLimit caps simultaneous upstream page downloads.
+	fetchLimit = newSemaphore(appConfig.MaxFetches)
+	// renderLimit caps simultaneous headless-browser renders, which are far
+	// heavier than plain fetches and get their own budget.
+	renderLimit = newSemaphore(appConfig.MaxRenders)
+)
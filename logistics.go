@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+)
+
+/* ---------- Venue logistics ---------- */
+
+// venueLogistics is what parents need to know on arrival at a venue.
+type venueLogistics struct {
+	Parking       string `json:"parking,omitempty"`
+	Entry         string `json:"entry,omitempty"`         // gate or entrance to use
+	SpectatorSide string `json:"spectatorSide,omitempty"` // where spectators stand
+}
+
+func (l venueLogistics) lines() []string {
+	var out []string
+	for _, f := range []struct{ label, text string }{
+		{"Parking", l.Parking}, {"Entry", l.Entry}, {"Spectators", l.SpectatorSide},
+	} {
+		if f.text != "" {
+			out = append(out, f.label+": "+f.text)
+		}
+	}
+	return out
+}
+
+// logisticsIndex is VENUE_LOGISTICS by lower-cased venue name.
+var logisticsIndex = loadLogistics(appConfig.VenueLogistics)
+
+// loadLogistics reads a JSON file of logistics by venue name, e.g.
+//
+//	{"Golden Eagle Regional Park": {"parking": "Lot B off Baseline Rd",
+//	  "entry": "North gate", "spectatorSide": "East touchline"}}
+//
+// A venue name covers every field whose location starts with it. An
+// unreadable file is logged and ignored.
+func loadLogistics(path string) map[string]venueLogistics {
+	out := map[string]venueLogistics{}
+	if path == "" {
+		return out
+	}
+	raw, err := os.ReadFile(path)
+	var byName map[string]venueLogistics
+	if err == nil {
+		err = json.Unmarshal(raw, &byName)
+	}
+	if err != nil {
+		log.Printf("ignoring VENUE_LOGISTICS %s: %v", path, err)
+		return out
+	}
+	for name, l := range byName {
+		out[strings.ToLower(strings.TrimSpace(name))] = l
+	}
+	return out
+}
+
+// logisticsFor finds the logistics of the venue whose name is the longest
+// prefix of location, as venueSet.address does, or nil.
+func logisticsFor(location string) *venueLogistics {
+	loc := strings.ToLower(strings.TrimSpace(location))
+	best, found := 0, (*venueLogistics)(nil)
+	for key, l := range logisticsIndex {
+		if len(key) > best && venuePrefix(loc, key) {
+			l := l
+			best, found = len(key), &l
+		}
+	}
+	return found
+}
@@ -1,415 +1,1370 @@
-package main
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"log"
-	"net"
-	"net/http"
-	"os"
-	"regexp"
-	"strings"
-	"time"
-)
-
-/* ---------- Types ---------- */
-
-type Game struct {
-	HomeTeam    string `json:"homeTeam"`
-	AwayTeam    string `json:"awayTeam"`
-	Date        string `json:"date"`
-	Time        string `json:"time"`
-	Location    string `json:"location"`
-	Division    string `json:"division"`
-	Competition string `json:"competition"`
-}
-
-type ErrorResponse struct {
-	Error  string `json:"error"`
-	Detail string `json:"detail"`
-}
-
-type scheduleReq struct {
-	EventID string `json:"eventid"`
-	ClubID  string `json:"clubid"`
-}
-
-/* ---------- Helpers ---------- */
-
-func writeJSON(w http.ResponseWriter, status int, v any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(v)
-}
-
-func cors(w http.ResponseWriter, r *http.Request) bool {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Vary", "Origin")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusNoContent)
-		return true
-	}
-	return false
-}
-
-func getPSTLocation() *time.Location {
-	loc, err := time.LoadLocation("America/Los_Angeles")
-	if err != nil {
-		return time.FixedZone("PDT", -7*60*60) // fallback
-	}
-	return loc
-}
-
-func getNextWeekendDates() ([]string, []string) {
-	now := time.Now().In(getPSTLocation())
-	daysUntilSaturday := (6 - int(now.Weekday()) + 7) % 7
-	if daysUntilSaturday == 0 {
-		daysUntilSaturday = 7
-	}
-	nextSaturday := now.AddDate(0, 0, daysUntilSaturday)
-	nextSunday := nextSaturday.AddDate(0, 0, 1)
-
-	saturdayFormats := []string{
-		nextSaturday.Format("Jan 02, 2006"),
-		nextSaturday.Format("Jan 2, 2006"),
-		nextSaturday.Format("January 02, 2006"),
-		nextSaturday.Format("01/02/2006"),
-		nextSaturday.Format("Jan. 02, 2006"),
-	}
-	sundayFormats := []string{
-		nextSunday.Format("Jan 02, 2006"),
-		nextSunday.Format("Jan 2, 2006"),
-		nextSunday.Format("January 02, 2006"),
-		nextSunday.Format("01/02/2006"),
-		nextSunday.Format("Jan. 02, 2006"),
-	}
-
-	log.Printf("Weekend date patterns (PT): Sat %v | Sun %v", saturdayFormats, sundayFormats)
-	return saturdayFormats, sundayFormats
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-/* ---------- Scraper ---------- */
-
-func scrapeGotSportSchedule(eventID, clubID string) ([]Game, error) {
-	url := fmt.Sprintf("https://system.gotsport.com/org_event/events/%s/schedules?club=%s", eventID, clubID)
-	log.Printf("Fetching: %s", url)
-
-	client := &http.Client{
-		Timeout: 45 * time.Second,
-		Transport: &http.Transport{
-			Proxy:               http.ProxyFromEnvironment,
-			MaxIdleConns:        20,
-			MaxConnsPerHost:     20,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     30 * time.Second,
-			DialContext: (&net.Dialer{
-				Timeout:   15 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).DialContext,
-			TLSHandshakeTimeout: 10 * time.Second,
-		},
-	}
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
-	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RenoApexScraper/1.0)")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("http request failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read body failed: %v", err)
-	}
-	html := string(body)
-	log.Printf("HTML length: %d chars; sample: %s ...", len(html), html[:min(len(html), 500)])
-
-	games := parseWeekendGames(html, eventID)
-	if len(games) == 0 {
-		return nil, fmt.Errorf("no games found for event %s", eventID)
-	}
-	return games, nil
-}
-
-func parseWeekendGames(html, eventID string) []Game {
-	var games []Game
-	saturdayFormats, sundayFormats := getNextWeekendDates()
-	htmlLower := strings.ToLower(html)
-
-	var weekendSections []string
-	for _, sat := range saturdayFormats {
-		if strings.Contains(htmlLower, strings.ToLower(sat)) {
-			if s := extractSectionAroundDate(html, sat); s != "" {
-				weekendSections = append(weekendSections, s)
-			}
-		}
-	}
-	for _, sun := range sundayFormats {
-	if strings.Contains(htmlLower, strings.ToLower(sun)) {
-		if s := extractSectionAroundDate(html, sun); s != "" {
-			weekendSections = append(weekendSections, s)
-		}
-	}
-}
-	if len(weekendSections) == 0 {
-		weekendSections = append(weekendSections, html)
-	}
-
-	for _, section := range weekendSections {
-		sectionGames := findRenoApexGamesInSection(section, html)
-		games = append(games, sectionGames...)
-	}
-	log.Printf("Event %s: %d weekend Reno Apex home games", eventID, len(games))
-	return games
-}
-
-func extractSectionAroundDate(html, dateStr string) string {
-	idx := strings.Index(strings.ToLower(html), strings.ToLower(dateStr))
-	if idx == -1 {
-		return ""
-	}
-	start := idx - 5000
-	if start < 0 {
-		start = 0
-	}
-	end := idx + 10000
-	if end > len(html) {
-		end = len(html)
-	}
-	return html[start:end]
-}
-
-func findRenoApexGamesInSection(section, fullHTML string) []Game {
-	var games []Game
-
-	rowPattern := regexp.MustCompile(`(?is)<tr[^>]*>\s*((?:<td[^>]*>.*?</td>\s*){7})</tr>`)
-	rows := rowPattern.FindAllStringSubmatch(section, -1)
-	log.Printf("Found %d table rows in section", len(rows))
-
-	for i, match := range rows {
-		if len(match) < 2 {
-			continue
-		}
-		tdPattern := regexp.MustCompile(`(?is)<td[^>]*>(.*?)</td>`)
-		tds := tdPattern.FindAllStringSubmatch(match[1], -1)
-		if len(tds) < 7 {
-			log.Printf("Row %d has %d tds (expected 7)", i+1, len(tds))
-			continue
-		}
-
-		matchID := cleanText(tds[0][1])
-		dateTime := cleanText(tds[1][1])
-		homeTeam := cleanText(tds[2][1])
-		results := cleanText(tds[3][1])
-		awayTeam := cleanText(tds[4][1])
-		location := cleanText(tds[5][1])
-		division := cleanText(tds[6][1])
-
-		if strings.Contains(strings.ToLower(homeTeam), "reno apex") &&
-			results == "-" && isHomeGame(matchID, homeTeam, fullHTML) {
-
-			d, t := parseDateTime(dateTime)
-			game := Game{
-				HomeTeam:    homeTeam,
-				AwayTeam:    awayTeam,
-				Location:    location,
-				Division:    division,
-				Competition: division,
-				Date:        d,
-				Time:        t,
-			}
-			if game.Date != "" && game.Time != "TBD" && !isDuplicateGame(games, game) {
-				games = append(games, game)
-			}
-		}
-	}
-	return games
-}
-
-func isHomeGame(matchID, homeTeam, fullHTML string) bool {
-	p := regexp.MustCompile(`(?is)` + regexp.QuoteMeta(matchID) + `.*?` + regexp.QuoteMeta(homeTeam) + `\s*\(H\)`)
-	return p.MatchString(fullHTML)
-}
-
-func cleanText(s string) string {
-	re := regexp.MustCompile(`(?s)<.*?>`)
-	out := re.ReplaceAllString(s, "")
-	out = strings.TrimSpace(out)
-	out = strings.Trim(out, ".,;:-")
-	return out
-}
-
-func parseDateTime(dateTime string) (string, string) {
-	// example: "Aug 30, 2025 1:00PM PDT"
-	re := regexp.MustCompile(`(?i)([A-Za-z]+\.? \d{1,2}, \d{4})\s+([\d:]+[AP]M [A-Za-z]+)`)
-	m := re.FindStringSubmatch(dateTime)
-	if len(m) >= 3 {
-		dateStr := m[1]
-		timeStr := m[2]
-		if d, err := time.ParseInLocation("Jan 02, 2006", dateStr, getPSTLocation()); err == nil {
-			return d.Format("2006-01-02"), timeStr
-		}
-		if d, err := time.ParseInLocation("January 02, 2006", dateStr, getPSTLocation()); err == nil {
-			return d.Format("2006-01-02"), timeStr
-		}
-		if d, err := time.ParseInLocation("Jan. 02, 2006", dateStr, getPSTLocation()); err == nil {
-			return d.Format("2006-01-02"), timeStr
-		}
-	}
-	// Fallback: next Saturday (PT)
-	now := time.Now().In(getPSTLocation())
-	add := (6 - int(now.Weekday()) + 7) % 7
-	if add == 0 {
-		add = 7
-	}
-	return now.AddDate(0, 0, add).Format("2006-01-02"), "TBD"
-}
-
-func isDuplicateGame(existing []Game, g Game) bool {
-	for _, ex := range existing {
-		if ex.Date == g.Date &&
-			ex.Time == g.Time &&
-			strings.EqualFold(ex.HomeTeam, g.HomeTeam) &&
-			strings.EqualFold(ex.AwayTeam, g.AwayTeam) {
-			return true
-		}
-	}
-	return false
-}
-
-/* ---------- HTTP Handlers ---------- */
-
-func scheduleHandler(w http.ResponseWriter, r *http.Request) {
-	if cors(w, r) {
-		return
-	}
-	switch r.Method {
-	case http.MethodGet:
-		// /schedule?eventid=44145&clubid=12893
-		eventID := r.URL.Query().Get("eventid")
-		clubID := r.URL.Query().Get("clubid")
-		handleSchedule(w, r, eventID, clubID)
-
-	case http.MethodPost:
-		// JSON: {"eventid":"44145","clubid":"12893"}
-		var req scheduleReq
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			writeJSON(w, http.StatusBadRequest, ErrorResponse{
-				Error:  "invalid_request",
-				Detail: "Body must be JSON with eventid and clubid",
-			})
-			return
-		}
-		handleSchedule(w, r, req.EventID, req.ClubID)
-
-	default:
-		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{
-			Error:  "method_not_allowed",
-			Detail: "Use GET with query or POST with JSON",
-		})
-	}
-}
-
-func handleSchedule(w http.ResponseWriter, r *http.Request, eventID, clubID string) {
-	if eventID == "" || clubID == "" {
-		writeJSON(w, http.StatusBadRequest, ErrorResponse{
-			Error:  "missing_parameters",
-			Detail: "eventid and clubid are required",
-		})
-		return
-	}
-
-	var games []Game
-	var err error
-
-	if strings.EqualFold(eventID, "ecnl") {
-		games = []Game{} // TODO: implement ECNL if needed
-	} else {
-		games, err = scrapeGotSportSchedule(eventID, clubID)
-	}
-
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, ErrorResponse{
-			Error:  "scrape_failed",
-			Detail: err.Error(),
-		})
-		return
-	}
-	writeJSON(w, http.StatusOK, games)
-}
-
-func healthHandler(w http.ResponseWriter, r *http.Request) {
-	if cors(w, r) {
-		return
-	}
-	writeJSON(w, http.StatusOK, map[string]string{
-		"status":      "healthy",
-		"service":     "RenoApex GotSport Parser",
-		"version":     "13.0",
-		"timestamp":   time.Now().Format(time.RFC3339),
-		"description": "Table-based parsing with (H) check and robust HTTP/CORS support",
-	})
-}
-
-/* ---------- main ---------- */
-
-func main() {
-	// Honor PORT from Render and bind to 0.0.0.0
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-	mux := http.NewServeMux()
-	mux.HandleFunc("/schedule", scheduleHandler)
-	mux.HandleFunc("/health", healthHandler)
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if cors(w, r) {
-			return
-		}
-		fmt.Fprintln(w, "RenoApex GotSport Parser v13.0\n\nEndpoints:\n- GET/POST /schedule\n- /health")
-	})
-
-	srv := &http.Server{
-		Addr:         "0.0.0.0:" + port,
-		Handler:      logRequests(mux),
-		ReadTimeout:  20 * time.Second,
-		WriteTimeout: 120 * time.Second,
-		IdleTimeout:  60 * time.Second,
-		BaseContext: func(l net.Listener) context.Context { return context.Background() },
-	}
-
-	log.Printf("Starting server on %s", srv.Addr)
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("server error: %v", err)
-	}
-}
-
-func logRequests(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("%s %s ua=%q", r.Method, r.URL.String(), r.UserAgent())
-		next.ServeHTTP(w, r)
-	})
-}
-
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ---------- Types ---------- */
+
+type Game struct {
+	// ID identifies the fixture across scrapes; see gameID.
+	ID       string `json:"id"`
+	HomeTeam string `json:"homeTeam"`
+	AwayTeam string `json:"awayTeam"`
+	// HomeTeamID and AwayTeamID are GotSport's team IDs, when the page links
+	// the teams; unlike names they survive a season's renaming.
+	HomeTeamID string `json:"homeTeamId,omitempty"`
+	AwayTeamID string `json:"awayTeamId,omitempty"`
+	// HomeOrAway is "home" or "away" for the club's side, empty when neither
+	// team is the club's (bracket slots).
+	HomeOrAway string `json:"homeOrAway,omitempty"`
+	Date       string `json:"date"`
+	Time       string `json:"time"`
+	// EndTime ("3:04PM MST") and DurationMinutes estimate how long the game
+	// holds its field, from its age group; see gameDuration.
+	EndTime         string `json:"endTime,omitempty"`
+	DurationMinutes int    `json:"durationMinutes,omitempty"`
+	Location        string `json:"location"`
+	Division        string `json:"division"`
+	Competition     string `json:"competition"`
+	// OpponentClub is the other side's club without age, gender or level.
+	OpponentClub string `json:"opponentClub"`
+	// SourceURL is the game's own GotSport page, when the schedule links
+	// one.
+	SourceURL string `json:"sourceUrl,omitempty"`
+	// Address is the venue's street address, from the venues table.
+	Address string `json:"address"`
+	// AtHome is set when Location is one of the club's HOME_VENUES.
+	AtHome bool `json:"atHome"`
+	// Logistics are the venue's parking, entry and spectator notes, from
+	// VENUE_LOGISTICS.
+	Logistics *venueLogistics `json:"logistics,omitempty"`
+	// KitColor is the kit the club's team wears, from KITS and TEAM_KITS.
+	KitColor string `json:"kitColor,omitempty"`
+	// IsPast is set at response time once the kickoff is behind us.
+	IsPast bool `json:"isPast"`
+	// KickoffInMinutes (negative once started) and KickoffIn ("in 2 days")
+	// are set at response time when countdown=true.
+	KickoffInMinutes *int   `json:"kickoffInMinutes,omitempty"`
+	KickoffIn        string `json:"kickoffIn,omitempty"`
+	// Source is "manual" for games an admin added; scraped games leave it
+	// empty.
+	Source string `json:"source,omitempty"`
+	// Events lists every event a fixture appeared in; set only when several
+	// events are merged.
+	Events []string `json:"events,omitempty"`
+	// Status is set by a manual override: "cancelled", "postponed" and so
+	// on. ManualFields names every field an override changed, and
+	// ManualNote is the admin's explanation.
+	Status       string   `json:"status,omitempty"`
+	ManualFields []string `json:"manualFields,omitempty"`
+	ManualNote   string   `json:"manualNote,omitempty"`
+	// Annotations are admin notes by label ("snack": "Smith family"), set at
+	// response time when annotations=true.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Flags are scheduling problems found in the game, such as a blackout
+	// date; see flagChecks.
+	Flags []gameFlag `json:"flags,omitempty"`
+}
+
+type ErrorResponse struct {
+	Error  string `json:"error"`
+	Detail string `json:"detail"`
+}
+
+type scheduleReq struct {
+	EventID string `json:"eventid"`
+	ClubID  string `json:"clubid"`
+	Refresh bool   `json:"refresh"`
+	MaxAge  *int   `json:"maxAge"` // seconds; nil means the cache TTL
+	Format  string `json:"format"` // see outputFormats; empty negotiates via Accept
+	Alarm   string `json:"alarm"`  // ICS reminders before kickoff, e.g. "60m" or "1d,60m"
+	// UpcomingOnly drops games whose kickoff has passed; nil means UPCOMING_ONLY.
+	UpcomingOnly *bool `json:"upcomingOnly"`
+	// From and To (2006-01-02, inclusive) replace the next-weekend window;
+	// Division keeps games whose division contains it. See scheduleFilter.
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Division string `json:"division"`
+	// Team scrapes one team's schedule page and Venue a GotSport field ID's
+	// (or filters by location name); clubid is then optional.
+	Team  string `json:"team"`
+	Venue string `json:"venue"`
+	// TZ converts dates and times to an IANA zone; empty means EVENT_TZ.
+	TZ string `json:"tz"`
+	// Envelope wraps JSON output as {"timezone","games"}.
+	Envelope bool `json:"envelope"`
+	// Countdown adds kickoffInMinutes and kickoffIn to each game.
+	Countdown bool `json:"countdown"`
+	// HomeVenueOnly keeps games at the club's HOME_VENUES.
+	HomeVenueOnly bool `json:"homeVenueOnly"`
+	// Strict fails with 422 and the parse warnings when any club row could
+	// not be read, rather than leaving its game out.
+	Strict bool `json:"strict"`
+	// Annotations merges the admin annotation store into each game.
+	Annotations bool `json:"annotations"`
+	// TeamSlug keeps one team's games, by its /teams/directory slug.
+	TeamSlug string `json:"teamSlug"`
+	// TeamID keeps one team's games by GotSport team ID, which unlike the
+	// slug survives renaming. Games whose page doesn't link teams drop out.
+	TeamID string `json:"teamId"`
+	// Club scrapes another club's games, matched within team names; empty
+	// means CLUB_NAME.
+	Club string `json:"club"`
+	// Side keeps the club's "home" (the default), "away" or "all" games.
+	Side string `json:"side"`
+
+	// Callback requests JSONP output; query-only and off unless JSONP_ENABLED.
+	Callback string `json:"-"`
+}
+
+/* ---------- Helpers ---------- */
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func cors(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Vary", "Origin")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Expose-Headers", "Age, X-Cache, X-Parse-Warnings, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return true
+	}
+	return false
+}
+
+// clock returns the current time for schedule logic ("next weekend", past
+// games). Golden tests pin it to the date a fixture page was saved.
+var clock = time.Now
+
+// nextWeekend returns next Saturday and Sunday (event time) as 2006-01-02 dates.
+func nextWeekend() (string, string) {
+	now := clock().In(eventLocation())
+	daysUntilSaturday := (6 - int(now.Weekday()) + 7) % 7
+	if daysUntilSaturday == 0 {
+		daysUntilSaturday = 7
+	}
+	nextSaturday := now.AddDate(0, 0, daysUntilSaturday)
+	nextSunday := nextSaturday.AddDate(0, 0, 1)
+
+	sat, sun := nextSaturday.Format("2006-01-02"), nextSunday.Format("2006-01-02")
+	log.Printf("Next weekend (%s): %s / %s", appConfig.EventTZ, sat, sun)
+	return sat, sun
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+/* ---------- Scraper ---------- */
+
+var upstreamClient = &http.Client{
+	Timeout:   45 * time.Second,
+	Transport: newUpstreamTransport(),
+}
+
+// upstreamDialer is how upstream connections are dialed, TLS profiles
+// included.
+var upstreamDialer = &net.Dialer{
+	Timeout:   15 * time.Second,
+	KeepAlive: 30 * time.Second,
+}
+
+func newUpstreamTransport() *http.Transport {
+	return &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        20,
+		MaxConnsPerHost:     20,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     30 * time.Second,
+		DialContext:         upstreamDialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+}
+
+// scrapeGotSport fetches and parses one event, narrowed by f. With explain
+// set, the returned stats carry a per-row account of the parser's decisions.
+func scrapeGotSport(ctx context.Context, eventID, clubID string, f scheduleFilter, explain bool) ([]Game, *parseStats, error) {
+	// Team and venue pages are already compact; the XHR endpoint and
+	// print/export views are per club.
+	if appConfig.ScrapeMode == scrapeModeXHR && !f.ownPage() {
+		if games, stats, ok, err := scrapeGotSportXHR(ctx, eventID, clubID, f, explain); ok {
+			return games, stats, err
+		}
+	}
+	if appConfig.ScrapeView != scrapeViewPage && !f.ownPage() {
+		if games, stats, ok, err := scrapeGotSportView(ctx, eventID, clubID, f, explain); ok {
+			return games, stats, err
+		}
+	}
+
+	body, err := fetchPage(ctx, "gotsport", f.scheduleURL(eventID, clubID))
+	if err != nil {
+		return nil, nil, err
+	}
+	html := string(body)
+	if appConfig.ScrapeMode == scrapeModeXHR && f.plainPage() {
+		discoverXHREndpoint(eventID, clubID, html)
+	}
+	log.Printf("HTML length: %d chars; sample: %s ...", len(html), html[:min(len(html), 500)])
+
+	games, stats := parseSchedulePage(html, eventID, f, explain)
+	if len(games) == 0 && appConfig.RenderJS {
+		render, why := renderVerdict(html, stats)
+		log.Printf("render %s: %s", eventID, why)
+		if render {
+			// The page builds its schedule in the browser; parse what it
+			// renders.
+			if rendered, err := renderPage(ctx, f.scheduleURL(eventID, clubID)); err != nil {
+				log.Printf("render %s: %v", eventID, err)
+				why += "; render failed"
+			} else {
+				games, stats = parseSchedulePage(rendered, eventID, f, explain)
+				stats.Path = scrapePathRender
+			}
+		}
+		noteRender(ctx, why)
+	}
+	recordParseStats(stats)
+	if len(games) == 0 {
+		return nil, stats, classify(ErrParseEmpty, fmt.Errorf("no games found for event %s", eventID))
+	}
+	return games, stats, nil
+}
+
+// parseSchedulePage parses one schedule page for the club's games, narrowed
+// by f, timing the parse in the returned stats.
+func parseSchedulePage(html, eventID string, f scheduleFilter, explain bool) ([]Game, *parseStats) {
+	stats := newPageStats(eventID, len(html), f, explain)
+	start := time.Now()
+	games := parseWeekendGames(html, eventID, f, stats)
+	stats.TotalMs = float64(time.Since(start).Microseconds()) / 1000
+	stats.Games = len(games)
+	return games, stats
+}
+
+// fetchPage downloads url, waiting for a slot in fetchLimit first. source
+// names the upstream for health tracking and the circuit breaker.
+func fetchPage(ctx context.Context, source, url string) ([]byte, error) {
+	return fetchURL(ctx, source, url, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+}
+
+// fetchJSON is fetchPage for backend endpoints, asking for JSON the way the
+// page's own XHR calls do.
+func fetchJSON(ctx context.Context, source, url string) ([]byte, error) {
+	return fetchURL(ctx, source, url, "application/json, text/plain, */*")
+}
+
+func fetchURL(ctx context.Context, source, url, accept string) ([]byte, error) {
+	if err := checkCircuit(source); err != nil {
+		return nil, err
+	}
+	fetchWaiters.Add(1)
+	err := fetchLimit.acquire(ctx)
+	fetchWaiters.Add(-1)
+	if err != nil {
+		return nil, timeoutAware(err, fmt.Errorf("waiting for fetch slot: %v", err))
+	}
+	defer fetchLimit.release()
+	log.Printf("Fetching: %s", url)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RenoApexScraper/1.0)")
+	req.Header.Set("Accept", accept)
+	if strings.HasPrefix(accept, "application/json") {
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	}
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	setConditionalHeaders(req, url)
+
+	start := time.Now()
+	resp, err := upstreamClientFor(source).Do(req)
+	if err != nil {
+		err = timeoutAware(err, fmt.Errorf("http request failed: %v", err))
+		recordUpstream(source, 0, time.Since(start), err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	noteHTTPStatus(ctx, resp.StatusCode)
+
+	if resp.StatusCode == http.StatusNotModified {
+		body, ok := revalidatedBody(url)
+		if !ok {
+			err = fmt.Errorf("HTTP 304 for a page no longer kept")
+			recordUpstream(source, resp.StatusCode, time.Since(start), err)
+			return nil, err
+		}
+		recordUpstream(source, resp.StatusCode, time.Since(start), nil)
+		noteNotModified(ctx)
+		return body, nil
+	}
+	if resp.StatusCode != 200 {
+		err = fmt.Errorf("HTTP %d", resp.StatusCode)
+		if upstreamBlocked(resp.StatusCode) {
+			err = classify(ErrUpstreamBlocked, err)
+		}
+		recordUpstream(source, resp.StatusCode, time.Since(start), err)
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		err = timeoutAware(err, fmt.Errorf("read body failed: %v", err))
+	}
+	recordUpstream(source, resp.StatusCode, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	body = toUTF8(body, resp.Header.Get("Content-Type"))
+	rememberResponse(url, resp.Header, body)
+	return body, nil
+}
+
+var (
+	// markerElemPattern finds leaf elements whose text carries a "(H)" marker.
+	markerElemPattern = regexp.MustCompile(`(?is)<([a-z][a-z0-9]*)\b([^>]*)>([^<]*\(H\)[^<]*)<`)
+	dataMatchAttr     = regexp.MustCompile(`(?i)data-match(?:-id)?\s*=\s*["']?([\w-]+)`)
+	matchLabel        = regexp.MustCompile(`(?i)\bmatch\s*#?\s*([\w-]+)`)
+)
+
+// parseWeekendGames returns the club's upcoming home games for next weekend,
+// or for f's date range when it has one. If the page lists nothing on the
+// weekend at all, every upcoming home game is returned instead. Schedule data
+// embedded as JSON is preferred; the HTML tables are parsed only when the
+// page has none.
+func parseWeekendGames(html, eventID string, f scheduleFilter, stats *parseStats) []Game {
+	start := time.Now()
+	candidates, rowDates, ok := findEmbeddedGames(html, stats)
+	stats.timeStrategy("embedded_json", start)
+	stats.Path = scrapePathJSON
+	if !ok {
+		stats.Path = scrapePathTables
+		start = time.Now()
+		markers := indexHomeMarkers(html)
+		stats.timeStrategy("home_markers", start)
+
+		start = time.Now()
+		candidates, rowDates = findClubGames(html, markers, stats)
+		stats.timeStrategy("table_rows", start)
+	}
+	return weekendGames(candidates, rowDates, eventID, f, stats)
+}
+
+// weekendGames keeps candidates on next weekend, or all of them when no
+// row at all falls on it, then applies f. A ranged f replaces the weekend.
+func weekendGames(candidates []candidate, rowDates map[string]bool, eventID string, f scheduleFilter, stats *parseStats) []Game {
+	sat, sun := nextWeekend()
+	filter := !f.ranged() && !f.AllSides && (rowDates[sat] || rowDates[sun])
+	var games []Game
+	for _, c := range candidates {
+		if filter && c.game.Date != sat && c.game.Date != sun {
+			stats.rejectExplained(c.explain, "not on next weekend")
+			continue
+		}
+		if ok, reason := f.keep(c.game); !ok {
+			stats.rejectExplained(c.explain, reason)
+			continue
+		}
+		games = append(games, c.game)
+	}
+	log.Printf("Event %s: %d weekend %s home games", eventID, len(games), stats.club)
+	return games
+}
+
+// candidate is an accepted row; explain indexes its entry in stats.Explain.
+type candidate struct {
+	game    Game
+	explain int
+}
+
+// findClubGames extracts the club's unplayed home games from every schedule
+// row. Each field comes from the row's own cells, located by the table's
+// header row. A row without a date of its own takes the current date group:
+// the last date separator row above it or, before any, the date the table
+// is headed with. rowDates records the date of every row seen, club or not.
+func findClubGames(html string, markers map[string][]string, stats *parseStats) ([]candidate, map[string]bool) {
+	var games []candidate
+	rowDates := map[string]bool{}
+
+	rowNum := 0
+	for _, table := range pageTables(html) {
+		rows := tableRows(table.inner)
+		roles := detectColumnRoles(rows)
+		log.Printf("Found %d table rows (columns %v, from header: %t)", len(rows), roles.cols, roles.fromHeader)
+
+		groupDate := table.leadDate()
+		for _, row := range rows {
+			if row.group != "" {
+				if hasDate(row.group) {
+					groupDate = row.group
+				}
+				continue
+			}
+			if row.header {
+				continue
+			}
+			cells := row.cells
+			stats.RowMatches++
+			rowNum++
+			if len(cells) < roles.width() {
+				log.Printf("Row %d has %d tds (expected %d)", rowNum, len(cells), roles.width())
+				ex := rowExplain{Row: rowNum, Strategy: "table_rows", Reason: fmt.Sprintf("expected %d cells, found %d", roles.width(), len(cells))}
+				if text := strings.Join(cells, " "); isClubTeam(text, stats.club) {
+					ex.Fields = map[string]fieldSource{"row": {"tr", text}}
+					stats.warn(ex, "row", "club row dropped: "+ex.Reason)
+				}
+				stats.explainRow(ex)
+				continue
+			}
+			stats.CellRows++
+
+			get := func(role string) string {
+				if i, ok := roles.cols[role]; ok {
+					return cells[i]
+				}
+				return ""
+			}
+			ids := func(role string) []string {
+				if i, ok := roles.cols[role]; ok && i < len(row.teamIDs) {
+					return row.teamIDs[i]
+				}
+				return nil
+			}
+			from := func(names ...string) string {
+				var refs []string
+				for _, r := range names {
+					if i, ok := roles.cols[r]; ok {
+						refs = append(refs, fmt.Sprintf("td[%d]", i))
+					}
+				}
+				return strings.Join(refs, "+")
+			}
+			matchID := get("id")
+			dateTime, result, location := fixtureFields(get)
+			if groupDate != "" && !hasDate(dateTime) {
+				// A bare weekday ("Sat 9:00 AM") would spoil the full date.
+				dateTime = strings.TrimSpace(groupDate + " " + weekdayPrefix.ReplaceAllString(dateTime, ""))
+			}
+			homeCell, awayCell := get("home"), get("away")
+			division := get("division")
+
+			d, t := parseDateTime(dateTime)
+			if t != "TBD" {
+				rowDates[d] = true
+			}
+
+			ex := rowExplain{
+				Row:      rowNum,
+				Strategy: "table_rows",
+				Fields: map[string]fieldSource{
+					"matchId":  {from("id"), matchID},
+					"dateTime": {from("date", "time"), dateTime},
+					"homeTeam": {from("home"), homeCell},
+					"result":   {from("result", "homeGoal", "awayGoal"), result},
+					"awayTeam": {from("away"), awayCell},
+					"location": {from("location", "venue", "field"), location},
+					"division": {from("division"), division},
+					"date":     {"parsed from dateTime", d},
+					"time":     {"parsed from dateTime", t},
+				},
+			}
+
+			o := orientFixture(homeCell, awayCell)
+			homeID, awayID := o.teamIDs(ids("home"), ids("away"))
+			games = judgeFixture(rawFixture{
+				Home:      o.home,
+				Away:      o.away,
+				HomeID:    homeID,
+				AwayID:    awayID,
+				Link:      gamePageURL(row.link),
+				Date:      d,
+				Time:      t,
+				Result:    result,
+				Location:  location,
+				Division:  division,
+				Swapped:   o.swapped,
+				Via:       o.via,
+				Confirmed: roles.fromHeader || o.swapped || o.markedInRow || markedHome(markers[matchID], o.home),
+			}, ex, games, stats)
+		}
+	}
+	return games, rowDates
+}
+
+// fixtureFields assembles the kickoff, result and location of a row from
+// whichever columns it has: a combined date/time or separate ones, a result
+// or per-side scores, a location or venue plus field.
+func fixtureFields(get func(role string) string) (dateTime, result, location string) {
+	dateTime = strings.TrimSpace(get("date") + " " + get("time"))
+	result = get("result")
+	if hg, ag := get("homeGoal"), get("awayGoal"); hg != "" && ag != "" {
+		result = hg + " - " + ag
+	}
+	result = strings.Trim(result, " -")
+	location = get("location")
+	if location == "" {
+		location = strings.Trim(get("venue")+" - "+get("field"), " -")
+	}
+	return dateTime, result, location
+}
+
+// rawFixture is one schedule entry as read from the page, before the club
+// filter.
+type rawFixture struct {
+	Home, Away         string
+	HomeID, AwayID     string // GotSport team IDs, when the page links the teams
+	Link               string // the game's own page, when the row links one
+	Date, Time         string // "2006-01-02" and "3:04PM MST"; Time is "TBD" when unknown
+	Result             string // empty until the game is played
+	Location, Division string
+
+	Swapped   bool   // "@"/"at" notation reversed the listed order
+	Via       string // which notation did
+	Confirmed bool   // something beyond column order says which side is home
+}
+
+// judgeFixture applies the club, result, orientation and kickoff checks to
+// one fixture, recording the outcome in ex and appending accepted games.
+func judgeFixture(f rawFixture, ex rowExplain, games []candidate, stats *parseStats) []candidate {
+	if stats.keepFixtures {
+		stats.fixtures = append(stats.fixtures, f)
+	}
+	if f.Result != "" {
+		stats.noteResult(f)
+	}
+	home, away := isClubTeam(f.Home, stats.club), isClubTeam(f.Away, stats.club)
+	var onSide bool
+	switch {
+	case stats.allSides:
+		onSide = home || away || isBracketSlot(f.Home) || isBracketSlot(f.Away)
+	case stats.side == sideAway:
+		onSide = away
+	case stats.side == sideAll:
+		onSide = home || away
+	default:
+		onSide = home
+	}
+	switch {
+	case !onSide:
+		switch {
+		case stats.side == sideAway:
+			ex.Reason = "away team is not the club"
+		case stats.side == sideAll:
+			ex.Reason = "neither team is the club"
+		case away && f.Swapped:
+			ex.Reason = "club is the away side (" + f.Via + ")"
+		default:
+			ex.Reason = "home team is not the club"
+		}
+	case f.Result != "": // cleanText trims the "-" placeholder of unplayed games
+		ex.Reason = fmt.Sprintf("result already posted (%q)", f.Result)
+	case !f.Confirmed && !stats.allSides:
+		ex.Reason = "no Home column header, @ notation or (H) marker confirms the home side"
+		stats.warn(ex, "homeTeam", "home/away assignment is ambiguous: "+ex.Reason)
+	default:
+		stats.HomeMatches++
+
+		game := Game{
+			HomeTeam:     f.Home,
+			AwayTeam:     f.Away,
+			HomeTeamID:   f.HomeID,
+			AwayTeamID:   f.AwayID,
+			HomeOrAway:   homeOrAway(f.Home, f.Away, stats.club),
+			SourceURL:    f.Link,
+			Location:     f.Location,
+			Division:     f.Division,
+			Competition:  f.Division,
+			Date:         f.Date,
+			Time:         f.Time,
+			OpponentClub: opponentClub(f.Away),
+		}
+		if game.HomeOrAway == sideAway {
+			game.OpponentClub = opponentClub(f.Home)
+		}
+		game.ID = gameID(game)
+		switch {
+		case game.Date == "" || game.Time == "TBD":
+			ex.Reason = "kickoff date/time not parseable"
+			stats.warn(ex, "dateTime", ex.Reason)
+		case isDuplicateGame(gamesOf(games), game):
+			ex.Reason = "duplicate of an earlier row"
+		default:
+			ex.Accepted = true
+			games = append(games, candidate{game: game, explain: len(stats.Explain)})
+		}
+	}
+	stats.explainRow(ex)
+	return games
+}
+
+// columnRoles says which cell holds each fixture field, by scheduleColumns
+// role.
+type columnRoles struct {
+	cols       map[string]int
+	fromHeader bool // roles came from a header row rather than the default
+}
+
+// width is the number of cells a row needs to have every mapped column.
+func (r columnRoles) width() int {
+	n := 0
+	for _, i := range r.cols {
+		if i >= n {
+			n = i + 1
+		}
+	}
+	return n
+}
+
+// defaultColumns is GotSport's layout: Match # | Date | Home | Results |
+// Away | Location | Division.
+var defaultColumns = map[string]int{"id": 0, "date": 1, "home": 2, "result": 3, "away": 4, "location": 5, "division": 6}
+
+// detectColumnRoles maps a table's columns from its first header row.
+// Without a header naming both Home and Away columns, GotSport's default
+// layout is assumed.
+func detectColumnRoles(rows []tableRow) columnRoles {
+	roles := columnRoles{cols: defaultColumns}
+	var hdr []string
+	for _, r := range rows {
+		if r.header {
+			hdr = r.cells
+			break
+		}
+	}
+	if hdr == nil {
+		return roles
+	}
+	cols := map[string]int{}
+	for i, label := range hdr {
+		if role := columnRole(label); role != "" {
+			if _, dup := cols[role]; !dup {
+				cols[role] = i
+			}
+		}
+	}
+	_, home := cols["home"]
+	_, away := cols["away"]
+	if home && away {
+		return columnRoles{cols: cols, fromHeader: true}
+	}
+	return roles
+}
+
+// orientation is a fixture's home and away sides after reading markers.
+type orientation struct {
+	home, away  string
+	swapped     bool   // "@"/"at" notation put the teams the other way round
+	via         string // which notation decided it
+	markedInRow bool   // the home cell carried an explicit (H)
+}
+
+// teamIDs picks the home and away team IDs from the links in the home and
+// away cells, following the same notation orientFixture did.
+func (o orientation) teamIDs(homeCell, awayCell []string) (string, string) {
+	first := func(ids []string) string {
+		if len(ids) > 0 {
+			return ids[0]
+		}
+		return ""
+	}
+	switch o.via {
+	case "A @ B":
+		if len(homeCell) == 2 {
+			return homeCell[1], homeCell[0]
+		}
+		return "", ""
+	case "@ opponent":
+		return first(awayCell), first(homeCell)
+	}
+	return first(homeCell), first(awayCell)
+}
+
+var atPattern = regexp.MustCompile(`(?i)^(.+?)\s+(?:@|at)\s+(.+)$`)
+
+// orientFixture resolves which side is at home. "A @ B" in one cell means A
+// visits B; a team cell written "@ B" or "at B" means B hosts.
+func orientFixture(homeCell, awayCell string) orientation {
+	home, marked := stripHomeMarker(homeCell)
+	o := orientation{home: home, away: awayCell, markedInRow: marked}
+
+	if m := atPattern.FindStringSubmatch(home); m != nil && awayCell == "" {
+		return orientation{home: m[2], away: m[1], swapped: true, via: "A @ B"}
+	}
+	if rest, ok := cutAtPrefix(awayCell); ok {
+		return orientation{home: rest, away: home, swapped: true, via: "@ opponent"}
+	}
+	if rest, ok := cutAtPrefix(home); ok {
+		o.home = rest // "@ Host" in the home column is redundant but consistent
+	}
+	return o
+}
+
+// cutAtPrefix strips a leading "@" or "at " from a team name.
+func cutAtPrefix(s string) (string, bool) {
+	switch {
+	case strings.HasPrefix(s, "@"):
+		return strings.TrimSpace(s[1:]), true
+	case len(s) > 3 && strings.EqualFold(s[:3], "at "):
+		return strings.TrimSpace(s[3:]), true
+	}
+	return s, false
+}
+
+func gamesOf(cs []candidate) []Game {
+	out := make([]Game, len(cs))
+	for i, c := range cs {
+		out[i] = c.game
+	}
+	return out
+}
+
+// indexHomeMarkers scans the page once for elements carrying "(H)" markers
+// and files their text under the match ID they belong to, taken from a
+// data-match attribute or a "Match 123" label.
+func indexHomeMarkers(html string) map[string][]string {
+	out := map[string][]string{}
+	for _, m := range markerElemPattern.FindAllStringSubmatch(html, -1) {
+		attrs, text := m[2], m[3]
+		var id string
+		if a := dataMatchAttr.FindStringSubmatch(attrs); a != nil {
+			id = a[1]
+		} else if l := matchLabel.FindStringSubmatch(text); l != nil {
+			id = l[1]
+		}
+		if id != "" {
+			out[id] = append(out[id], text)
+		}
+	}
+	return out
+}
+
+// stripHomeMarker removes a trailing "(H)" from a team cell, reporting
+// whether it was there.
+func stripHomeMarker(team string) (string, bool) {
+	if t := strings.TrimSpace(strings.TrimSuffix(team, "(H)")); t != team {
+		return t, true
+	}
+	return team, false
+}
+
+// markedHome reports whether any of texts tags team with "(H)".
+func markedHome(texts []string, team string) bool {
+	needle := strings.ToLower(team)
+	for _, text := range texts {
+		lower := strings.ToLower(text)
+		for i := strings.Index(lower, needle); i >= 0; {
+			rest := strings.TrimSpace(lower[i+len(needle):])
+			if strings.HasPrefix(rest, "(h)") {
+				return true
+			}
+			next := strings.Index(lower[i+1:], needle)
+			if next < 0 {
+				break
+			}
+			i += 1 + next
+		}
+	}
+	return false
+}
+
+var tagPattern = regexp.MustCompile(`(?s)<.*?>`)
+
+// cleanText reduces a table cell to its normalized text, trimming the
+// punctuation GotSport pads empty cells with.
+func cleanText(s string) string {
+	out := normalizeText(tagPattern.ReplaceAllString(s, ""))
+	out = strings.Trim(out, ".,;:-")
+	return out
+}
+
+func isDuplicateGame(existing []Game, g Game) bool {
+	for _, ex := range existing {
+		if ex.Date == g.Date &&
+			ex.Time == g.Time &&
+			strings.EqualFold(ex.HomeTeam, g.HomeTeam) &&
+			strings.EqualFold(ex.AwayTeam, g.AwayTeam) {
+			return true
+		}
+	}
+	return false
+}
+
+/* ---------- HTTP Handlers ---------- */
+
+func (s *Server) scheduleHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		// /schedule?eventid=44145&clubid=12893[&refresh=true][&maxAge=300]
+		req, err := scheduleReqFromQuery(r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		s.handleSchedule(w, r, req)
+
+	case http.MethodPost:
+		// JSON: {"eventid":"44145","clubid":"12893","refresh":false,"maxAge":300}
+		var req scheduleReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:  "invalid_request",
+				Detail: "Body must be JSON with eventid and clubid",
+			})
+			return
+		}
+		s.handleSchedule(w, r, req)
+
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{
+			Error:  "method_not_allowed",
+			Detail: "Use GET with query or POST with JSON",
+		})
+	}
+}
+
+func scheduleReqFromQuery(r *http.Request) (scheduleReq, error) {
+	q := r.URL.Query()
+	req := scheduleReq{
+		EventID: q.Get("eventid"),
+		ClubID:  q.Get("clubid"),
+		Format:  q.Get("format"),
+		Alarm:   q.Get("alarm"),
+
+		From:     q.Get("from"),
+		To:       q.Get("to"),
+		Division: q.Get("division"),
+		Team:     q.Get("team"),
+		Venue:    q.Get("venue"),
+		TZ:       q.Get("tz"),
+		TeamSlug: q.Get("teamSlug"),
+		TeamID:   q.Get("teamId"),
+		Club:     q.Get("club"),
+		Side:     q.Get("side"),
+
+		Callback: q.Get("callback"),
+	}
+	if v := q.Get("refresh"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return req, badParamsf("refresh must be true or false")
+		}
+		req.Refresh = b
+	}
+	if v := q.Get("maxAge"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return req, badParamsf("maxAge must be a number of seconds")
+		}
+		req.MaxAge = &n
+	}
+	if v := q.Get("upcomingOnly"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return req, badParamsf("upcomingOnly must be true or false")
+		}
+		req.UpcomingOnly = &b
+	}
+	if v := q.Get("envelope"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return req, badParamsf("envelope must be true or false")
+		}
+		req.Envelope = b
+	}
+	if v := q.Get("countdown"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return req, badParamsf("countdown must be true or false")
+		}
+		req.Countdown = b
+	}
+	if v := q.Get("homeVenueOnly"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return req, badParamsf("homeVenueOnly must be true or false")
+		}
+		req.HomeVenueOnly = b
+	}
+	if v := q.Get("strict"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return req, badParamsf("strict must be true or false")
+		}
+		req.Strict = b
+	}
+	if v := q.Get("annotations"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return req, badParamsf("annotations must be true or false")
+		}
+		req.Annotations = b
+	}
+	return req, nil
+}
+
+func (s *Server) handleSchedule(w http.ResponseWriter, r *http.Request, req scheduleReq) {
+	eventID, clubID := req.EventID, req.ClubID
+	if eventID == "" || clubID == "" && req.Team == "" && req.Venue == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "missing_parameters",
+			Detail: "eventid and clubid (or team or venue) are required",
+		})
+		return
+	}
+	if req.MaxAge != nil && *req.MaxAge < 0 {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "invalid_parameters",
+			Detail: "maxAge must not be negative",
+		})
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+	format, err := negotiateFormat(r, req.Format)
+	var notAcceptable errNotAcceptable
+	if errors.As(err, &notAcceptable) {
+		writeJSON(w, http.StatusNotAcceptable, ErrorResponse{
+			Error:  "not_acceptable",
+			Detail: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "invalid_parameters",
+			Detail: err.Error(),
+		})
+		return
+	}
+	req.Format = format
+	if req.Callback != "" {
+		if !appConfig.JSONPEnabled {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:  "jsonp_disabled",
+				Detail: "callback is not enabled on this server",
+			})
+			return
+		}
+		if !jsonpCallback.MatchString(req.Callback) || len(req.Callback) > 64 {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:  "invalid_parameters",
+				Detail: "callback must be a JavaScript identifier",
+			})
+			return
+		}
+	}
+	alarms, err := parseAlarms(req.Alarm)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "invalid_parameters",
+			Detail: err.Error(),
+		})
+		return
+	}
+	if req.TZ == "" {
+		req.TZ = appConfig.EventTZ
+	}
+	loc, err := time.LoadLocation(req.TZ)
+	if err != nil || req.TZ == "Local" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "invalid_parameters",
+			Detail: "tz must be an IANA zone like America/New_York",
+		})
+		return
+	}
+	if req.HomeVenueOnly && len(appConfig.HomeVenues) == 0 {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "invalid_parameters",
+			Detail: "homeVenueOnly needs HOME_VENUES configured on the server",
+		})
+		return
+	}
+	if req.TeamID != "" && !digitsOnly.MatchString(req.TeamID) {
+		writeJSON(w, http.StatusBadRequest, errorResponse(badParamsf("teamId must be a numeric GotSport team ID")))
+		return
+	}
+	filter := scheduleFilter{From: req.From, To: req.To, Division: strings.TrimSpace(req.Division), Team: strings.TrimSpace(req.Team), Venue: strings.TrimSpace(req.Venue)}
+	if club := strings.TrimSpace(req.Club); !strings.EqualFold(club, appConfig.ClubName) {
+		filter.Club = club // the configured club keeps the shared cache entry
+	}
+	if side := strings.ToLower(strings.TrimSpace(req.Side)); side != sideHome {
+		filter.Side = side // as does the default side
+	}
+	if err := filter.validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	maxAge := s.cache.maxAge()
+	if req.MaxAge != nil {
+		maxAge = time.Duration(*req.MaxAge) * time.Second
+	}
+	var res scheduleResult
+	ids := splitEventIDs(eventID)
+	if len(ids) > 1 {
+		res, err = s.getMergedSchedule(r.Context(), ids, clubID, filter, req.Refresh, maxAge)
+	} else {
+		res, err = s.getSchedule(r.Context(), eventID, clubID, filter, req.Refresh, maxAge)
+	}
+	var unparsed *parseWarningsError
+	if req.Strict && errors.As(res.Cause, &unparsed) {
+		rejectUnparsed(w, unparsed.warnings)
+		return
+	}
+	if errors.Is(err, errScrapeQueueFull) {
+		rejectOverloaded(w, http.StatusTooManyRequests, "scrape_queue",
+			"Scrape queue is full and no older copy is available; retry shortly")
+		return
+	}
+	if err != nil && req.Envelope && req.Format == "json" && req.Callback == "" {
+		w.Header().Set("X-Data-Tier", string(tierEmpty))
+		e := errorResponse(err)
+		writeJSON(w, http.StatusInternalServerError, scheduleEnvelope{
+			Timezone: req.TZ,
+			Games:    []Game{},
+			Tier:     tierEmpty,
+			Error:    &e,
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("X-Data-Tier", string(tierEmpty))
+		writeJSON(w, http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	warnings := scheduleWarnings(ids, clubID, filter)
+	if req.Strict && len(warnings) > 0 {
+		rejectUnparsed(w, warnings)
+		return
+	}
+	upcomingOnly := appConfig.UpcomingOnly
+	if req.UpcomingOnly != nil {
+		upcomingOnly = *req.UpcomingOnly
+	}
+	now := s.clock.Now()
+	games := markPast(res.Games, now, upcomingOnly)
+	if req.HomeVenueOnly {
+		games = atHomeOnly(games)
+	}
+	if req.TeamSlug != "" {
+		games = teamGames(games, req.TeamSlug)
+	}
+	if req.TeamID != "" {
+		games = teamGamesByID(games, req.TeamID)
+	}
+	if req.Countdown {
+		games = withCountdown(games, now, loc)
+	}
+	if req.Annotations {
+		games = s.withAnnotations(games)
+	}
+	if req.Format != "ics" && req.TZ != appConfig.EventTZ {
+		games = inTimezone(games, loc) // calendars carry absolute instants
+	}
+
+	res.Games = games
+	setCacheHeaders(w, res)
+	if len(warnings) > 0 {
+		w.Header().Set("X-Parse-Warnings", strconv.Itoa(len(warnings)))
+	}
+	writeSchedule(w, req, res, alarms, warnings)
+}
+
+// markPast flags games that kicked off before now, dropping them instead
+// when upcomingOnly is set. Games without a parseable kickoff are kept.
+// It edits games in place; getSchedule already returns a copy.
+func markPast(games []Game, now time.Time, upcomingOnly bool) []Game {
+	out := games[:0]
+	for _, g := range games {
+		if start, ok := gameKickoff(g); ok && start.Before(now) {
+			if upcomingOnly {
+				continue
+			}
+			g.IsPast = true
+		}
+		out = append(out, g)
+	}
+	return out
+}
+
+var errScrapeQueueFull = errors.New("scrape queue is full")
+
+// getSchedule returns the schedule from the best tier that can serve it;
+// see resolveSchedule. Each filter is cached separately. The error is set
+// only when no tier could, and the club's manual games are added to
+// whatever did.
+func (s *Server) getSchedule(ctx context.Context, eventID, clubID string, f scheduleFilter, refresh bool, maxAge time.Duration) (scheduleResult, error) {
+	res := s.resolveSchedule(ctx, eventID, clubID, f, refresh, maxAge)
+	incCounter("gotsport_schedule_tier_total", "tier", string(res.Tier))
+	if res.Tier == tierEmpty {
+		return res, res.Cause
+	}
+	if res.Cause != nil {
+		log.Printf("serving %s/%s from %s: %v", eventID, clubID, res.Tier, res.Cause)
+	}
+	res.Games = s.presentGames(withManualGames(res.Games, s.manualGamesFor(eventID, clubID, f)))
+	return res, nil
+}
+
+// presentGames turns scraped games into what public outputs show: overrides
+// applied, hidden games dropped, then addresses, end times, kits and flags
+// filled in.
+// withOverrides copies first, so cached slices are never modified.
+func (s *Server) presentGames(games []Game) []Game {
+	return withFlags(withKits(withEndTimes(s.withVenues(s.withoutHidden(s.withOverrides(games))))))
+}
+
+// setCacheHeaders reports res's age and tier; X-Cache is HIT when the
+// in-memory cache served it.
+func setCacheHeaders(w http.ResponseWriter, res scheduleResult) {
+	w.Header().Set("X-Data-Tier", string(res.Tier))
+	if res.Tier == tierCache {
+		w.Header().Set("X-Cache", "HIT")
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+	w.Header().Set("Age", strconv.Itoa(int(res.Age.Seconds())))
+}
+
+// writeSchedule renders games in req.Format, already resolved by negotiateFormat.
+// warnings only appear in the JSON envelope; callers set X-Parse-Warnings.
+// res carries the tier and any degradation cause for the envelope.
+func writeSchedule(w http.ResponseWriter, req scheduleReq, res scheduleResult, alarms []time.Duration, warnings []parseWarning) {
+	games := res.Games
+	switch req.Format {
+	case "ics":
+		writeICS(w, games, alarms)
+		return
+	case "pdf":
+		writePDF(w, games)
+		return
+	case "xlsx":
+		writeXLSX(w, games)
+		return
+	case "msgpack":
+		writeMsgpack(w, http.StatusOK, games)
+		return
+	case "csv":
+		writeCSV(w, games)
+		return
+	case "xml":
+		writeXML(w, http.StatusOK, "games", "game", games)
+		return
+	case "rss":
+		writeRSS(w, req, games)
+		return
+	case "print":
+		writePrintHTML(w, games)
+		return
+	}
+	var body any = games
+	if req.Envelope {
+		tz := req.TZ
+		if tz == "" {
+			tz = appConfig.EventTZ
+		}
+		env := scheduleEnvelope{Timezone: tz, Games: games, Tier: res.Tier, Warnings: warnings}
+		if res.Cause != nil {
+			e := errorResponse(res.Cause)
+			env.Error = &e
+		}
+		body = env
+	}
+	if req.Callback != "" {
+		writeJSONP(w, http.StatusOK, req.Callback, body)
+		return
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// scheduleEnvelope is the envelope=true JSON shape.
+type scheduleEnvelope struct {
+	Timezone string `json:"timezone"`
+	Games    []Game `json:"games"`
+	// Tier says which source served Games; see dataTier.
+	Tier dataTier `json:"tier"`
+	// Error is why the scrape failed when a later tier served Games.
+	Error *ErrorResponse `json:"error,omitempty"`
+	// Warnings note club rows left out of Games; see parseWarning.
+	Warnings []parseWarning `json:"warnings,omitempty"`
+}
+
+// loadSchedule performs a live scrape and stores the result in the cache.
+// Identical calls are coalesced into one upstream scrape. Only unfiltered
+// schedules are diffed into the change log and kept in game_history. The
+// tier is tierRevalidated when upstream answered every fetch with 304.
+func (s *Server) loadSchedule(ctx context.Context, eventID, clubID string, f scheduleFilter) ([]Game, dataTier, error) {
+	key := cacheKey(eventID, clubID) + f.cacheSuffix()
+	return s.flights.do(key, appConfig.CoalesceWindow, func() ([]Game, dataTier, error) {
+		sctx, cancel := detachedScrapeContext(ctx)
+		defer cancel()
+
+		sctx, trace := withScrapeTrace(sctx)
+		start := time.Now()
+		games, stats, err := s.scraper.Scrape(sctx, eventID, clubID, f)
+		recordScrape(scrapeAttempt{
+			EventID:    eventID,
+			ClubID:     clubID,
+			Source:     sourceOf(eventID),
+			At:         start,
+			DurationMs: time.Since(start).Milliseconds(),
+			HTTPStatus: trace.HTTPStatus,
+			Games:      len(games),
+			Render:     trace.Render,
+		}, err)
+		if err != nil {
+			if stats != nil && len(stats.Warnings) > 0 {
+				err = &parseWarningsError{err: err, warnings: stats.Warnings}
+			}
+			return nil, tierFresh, err
+		}
+		if appConfig.EnrichDetails {
+			enrichFromGamePages(sctx, games, s.clock.Now())
+		}
+		if before, _, ok := s.cache.peek(key); ok && f.empty() {
+			// Flag a copy, so changes report problems and kits in the new
+			// schedule.
+			d := diffSchedules(before, withFlags(withKits(append([]Game(nil), games...))))
+			recordChange(eventID, clubID, d)
+			notifications.notifyChange(eventID, clubID, d)
+			s.notifyWebhooks(eventID, clubID, d)
+		}
+		s.cache.set(key, games)
+		if f.empty() {
+			s.storeGames(eventID, clubID, scrapeSource(eventID, stats), games)
+		}
+		s.recordTeamNames(games)
+		if stats != nil {
+			recordWarnings(key, stats.Warnings)
+			if f.empty() {
+				s.recordResults(eventID, clubID, stats.Results)
+			}
+		}
+		if trace.Fetches > 0 && trace.NotModified == trace.Fetches {
+			return games, tierRevalidated, nil
+		}
+		return games, tierFresh, nil
+	})
+}
+
+// healthHandler reports the service healthy only while the parser passes
+// its self-test; a failing parser answers 503.
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	body := map[string]string{
+		"status":      "healthy",
+		"service":     "RenoApex GotSport Parser",
+		"version":     "13.0",
+		"timestamp":   time.Now().Format(time.RFC3339),
+		"description": "Table-based parsing with (H) check and robust HTTP/CORS support",
+	}
+	selfTest, failed := selfTestHealth()
+	for k, v := range selfTest {
+		body[k] = v
+	}
+	if failed {
+		body["status"] = "unhealthy"
+		writeJSON(w, http.StatusServiceUnavailable, body)
+		return
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+/* ---------- main ---------- */
+
+func main() {
+	if handled, code := runCLI(os.Args[1:]); handled {
+		os.Exit(code)
+	}
+
+	// Honor PORT from Render and bind to 0.0.0.0
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	var err error
+	if db, err = openStore(appConfig.DatabaseURL, appConfig.DBPath); err != nil {
+		log.Fatalf("datastore: %v", err)
+	}
+	var cache Cache = defaultCache
+	if appConfig.RedisURL != "" {
+		if cache, err = newRedisCache(appConfig.RedisURL, defaultCache.ttl); err != nil {
+			log.Fatalf("cache: %v", err)
+		}
+	}
+	defaultServer = NewServer(gotsportScraper{}, cache, db, packageClock)
+	srv := &http.Server{
+		Addr:         "0.0.0.0:" + port,
+		Handler:      defaultServer.Handler(),
+		ReadTimeout:  20 * time.Second,
+		WriteTimeout: 120 * time.Second,
+		IdleTimeout:  60 * time.Second,
+		BaseContext:  func(l net.Listener) context.Context { return context.Background() },
+	}
+
+	if err := defaultServer.seedVenues(appConfig.Venues); err != nil {
+		log.Fatalf("venues: %v", err)
+	}
+	if err := defaultServer.loadIndexes(); err != nil {
+		log.Fatalf("datastore: %v", err)
+	}
+	if notifications, err = loadDispatcher(appConfig.NotifyConfig); err != nil {
+		log.Fatalf("notifications: %v", err)
+	}
+	go runJobWorker(context.Background())
+	if appConfig.SelfTestInterval > 0 {
+		go runSelfTests(context.Background(), appConfig.SelfTestInterval)
+	}
+	scheduleRetention(time.Now())
+	if notifications != nil && appConfig.ReminderAt > 0 {
+		scheduleReminders(nextReminder(time.Now()))
+	}
+	if appConfig.ScrapeCron != nil {
+		scheduleScrapeCron(appConfig.ScrapeCron.next(time.Now()))
+	}
+	go runUsageWriter(context.Background())
+
+	if appConfig.WarmCache {
+		warmCache(appConfig.Events, appConfig.WarmInterval)
+	}
+
+	log.Printf("Starting server on %s", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("server error: %v", err)
+	}
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("%s %s ua=%q", r.Method, loggedURL(r.URL), r.UserAgent())
+		next.ServeHTTP(w, r)
+	})
+}
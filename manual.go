@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ---------- Manual games ---------- */
+
+// manualGame is a fixture arranged outside GotSport, such as a friendly,
+// added by an admin so the club's schedule is complete. It appears in the
+// club's schedules for EventID or, when that is empty, for every event.
+type manualGame struct {
+	ID        string    `json:"id"`
+	ClubID    string    `json:"clubId"`
+	EventID   string    `json:"eventId,omitempty"`
+	HomeTeam  string    `json:"homeTeam"`
+	AwayTeam  string    `json:"awayTeam"`
+	Date      string    `json:"date"` // 2006-01-02
+	Time      string    `json:"time"` // "3:04PM", in EVENT_TZ
+	Location  string    `json:"location"`
+	Division  string    `json:"division,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// normalize trims m and checks its fields, returning the problem in terms of
+// the API.
+func (m *manualGame) normalize() string {
+	for _, s := range []*string{&m.ClubID, &m.EventID, &m.HomeTeam, &m.AwayTeam, &m.Date, &m.Location, &m.Division} {
+		*s = strings.TrimSpace(*s)
+	}
+	m.Time = strings.ToUpper(strings.ReplaceAll(m.Time, " ", ""))
+	if m.ClubID == "" || m.HomeTeam == "" || m.AwayTeam == "" || m.Date == "" || m.Time == "" {
+		return "clubId, homeTeam, awayTeam, date and time are required"
+	}
+	if _, err := time.Parse("2006-01-02", m.Date); err != nil {
+		return "date must be like 2025-08-30"
+	}
+	t, err := time.Parse("3:04PM", m.Time)
+	if err != nil {
+		return "time must be like 9:30AM"
+	}
+	m.Time = t.Format("3:04PM")
+	return ""
+}
+
+// game returns m as served in club's schedule, flagged with Source "manual".
+func (m manualGame) game(club string) Game {
+	date, clock := parseDateTime(m.Date + " " + m.Time)
+	g := Game{
+		ID:           m.ID,
+		HomeTeam:     m.HomeTeam,
+		AwayTeam:     m.AwayTeam,
+		HomeOrAway:   homeOrAway(m.HomeTeam, m.AwayTeam, club),
+		Date:         date,
+		Time:         clock,
+		Location:     m.Location,
+		Division:     m.Division,
+		Competition:  m.Division,
+		OpponentClub: opponentClub(m.AwayTeam),
+		Source:       "manual",
+	}
+	if g.HomeOrAway == sideAway {
+		g.OpponentClub = opponentClub(m.HomeTeam)
+	}
+	return g
+}
+
+// manualSet is an in-memory copy of the manual_games table.
+type manualSet struct {
+	mu   sync.RWMutex
+	byID map[string]manualGame
+}
+
+func (s *manualSet) replace(ms []manualGame) {
+	m := make(map[string]manualGame, len(ms))
+	for _, g := range ms {
+		m[g.ID] = g
+	}
+	s.mu.Lock()
+	s.byID = m
+	s.mu.Unlock()
+}
+
+// list returns the manual games for clubID, or all of them when it is empty.
+func (s *manualSet) list(clubID string) []manualGame {
+	s.mu.RLock()
+	out := []manualGame{}
+	for _, g := range s.byID {
+		if clubID == "" || g.ClubID == clubID {
+			out = append(out, g)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Date != out[j].Date {
+			return out[i].Date < out[j].Date
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// manualGamesFor returns the manual games that belong in the club schedule
+// for eventID under f: next weekend's by default, or those f keeps when it
+// is ranged, on the sides f asks for. Team and venue pages are GotSport's
+// own, so they get none.
+func (s *Server) manualGamesFor(eventID, clubID string, f scheduleFilter) []Game {
+	if clubID == "" || f.ownPage() {
+		return nil
+	}
+	sat, sun := nextWeekend()
+	var out []Game
+	for _, m := range s.manual.list(clubID) {
+		if m.EventID != "" && m.EventID != eventID {
+			continue
+		}
+		g := m.game(firstNonEmpty(f.Club, appConfig.ClubName))
+		if !f.ranged() && g.Date != sat && g.Date != sun || !f.keepsSide(g.HomeOrAway) {
+			continue
+		}
+		if ok, _ := f.keep(g); ok {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// withManualGames returns scraped followed by extra in a new slice, so the
+// cached slice is never appended to.
+func withManualGames(scraped, extra []Game) []Game {
+	if len(extra) == 0 {
+		return scraped
+	}
+	out := make([]Game, 0, len(scraped)+len(extra))
+	return append(append(out, scraped...), extra...)
+}
+
+func (s *Server) reloadManualGames() error {
+	rows, err := s.store.Query(`SELECT id, club_id, event_id, home_team, away_team, date, time, location, division, created_at FROM manual_games`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var ms []manualGame
+	for rows.Next() {
+		var m manualGame
+		var created int64
+		if err := rows.Scan(&m.ID, &m.ClubID, &m.EventID, &m.HomeTeam, &m.AwayTeam, &m.Date, &m.Time, &m.Location, &m.Division, &created); err != nil {
+			return err
+		}
+		m.CreatedAt = time.Unix(created, 0).UTC()
+		ms = append(ms, m)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.manual.replace(ms)
+	return nil
+}
+
+// adminGamesHandler lists (GET [?clubid=]), adds (POST JSON {clubId,
+// eventId, homeTeam, awayTeam, date, time, location, division}) and removes
+// (DELETE ?id=) manual games. A game's ID is derived like a scraped one's,
+// so annotations, overrides and hiding work on it too.
+func (s *Server) adminGamesHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	if !requireAdmin(w, r) || !requireSameOrigin(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.manual.list(strings.TrimSpace(r.URL.Query().Get("clubid"))))
+
+	case http.MethodPost:
+		var m manualGame
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Detail: "Invalid JSON body"})
+			return
+		}
+		if problem := m.normalize(); problem != "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_parameters", Detail: problem})
+			return
+		}
+		m.ID = gameID(m.game(appConfig.ClubName))
+		m.CreatedAt = time.Now().UTC().Truncate(time.Second)
+		_, err := s.store.Exec(`INSERT INTO manual_games (id, club_id, event_id, home_team, away_team, date, time, location, division, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+			m.ID, m.ClubID, m.EventID, m.HomeTeam, m.AwayTeam, m.Date, m.Time, m.Location, m.Division, m.CreatedAt.Unix())
+		if !s.manualGamesChanged(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusCreated, m)
+
+	case http.MethodDelete:
+		id := strings.TrimSpace(r.URL.Query().Get("id"))
+		if id == "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "id is required"})
+			return
+		}
+		res, err := s.store.Exec(`DELETE FROM manual_games WHERE id = ?`, id)
+		if err == nil {
+			if n, _ := res.RowsAffected(); n == 0 {
+				writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "No manual game " + id})
+				return
+			}
+		}
+		if !s.manualGamesChanged(w, err) {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{
+			Error:  "method_not_allowed",
+			Detail: "Use GET, POST or DELETE",
+		})
+	}
+}
+
+// manualGamesChanged reloads the index after a write, reporting any failure.
+func (s *Server) manualGamesChanged(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		err = s.reloadManualGames()
+	}
+	if err != nil {
+		log.Printf("manual games: %v", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+/* ---------- Multi-event merge ---------- */
+
+// eventGames is one event's schedule, input to mergeSchedules.
+type eventGames struct {
+	EventID string
+	Games   []Game
+}
+
+// fixtureKickoffKey identifies a fixture across events: the same two teams
+// kicking off at the same time are one game however many events list it.
+func fixtureKickoffKey(g Game) string {
+	return strings.ToLower(g.HomeTeam) + "|" + strings.ToLower(g.AwayTeam) + "|" + g.Date + "|" + g.Time
+}
+
+// mergeSchedules concatenates schedules in order, collapsing cross-listed
+// fixtures into their first occurrence and recording every event in Events.
+// Teams listed under different names are given one name first; see
+// linkTeams.
+func mergeSchedules(sets []eventGames) []Game {
+	var merged []Game
+	links := linkTeams(sets)
+	seen := map[string]int{} // fixture key -> index in merged
+	for _, set := range sets {
+		for _, g := range set.Games {
+			g = links.apply(g)
+			key := fixtureKickoffKey(g)
+			if i, ok := seen[key]; ok {
+				if !containsString(merged[i].Events, set.EventID) {
+					merged[i].Events = append(merged[i].Events, set.EventID)
+				}
+				continue
+			}
+			g.Events = []string{set.EventID}
+			seen[key] = len(merged)
+			merged = append(merged, g)
+		}
+	}
+	return merged
+}
+
+// getMergedSchedule is getSchedule over several events. The reported age is
+// the oldest of the parts and the tier the worst; Cause is the first part's
+// that has one.
+func (s *Server) getMergedSchedule(ctx context.Context, eventIDs []string, clubID string, f scheduleFilter, refresh bool, maxAge time.Duration) (scheduleResult, error) {
+	sets := make([]eventGames, 0, len(eventIDs))
+	merged := scheduleResult{Tier: tierFresh}
+	for _, id := range eventIDs {
+		res, err := s.getSchedule(ctx, id, clubID, f, refresh, maxAge)
+		if err != nil {
+			return res, err
+		}
+		sets = append(sets, eventGames{EventID: id, Games: res.Games})
+		merged.Age = max(merged.Age, res.Age)
+		merged.Tier = worseTier(merged.Tier, res.Tier)
+		if merged.Cause == nil {
+			merged.Cause = res.Cause
+		}
+	}
+	merged.Games = mergeSchedules(sets)
+	return merged, nil
+}
+
+// splitEventIDs reads a comma-separated eventid parameter, dropping blanks
+// and repeats.
+func splitEventIDs(s string) []string {
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" && !containsString(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
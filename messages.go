@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+/* ---------- Message templates ---------- */
+
+// The kinds of message channels send, each with its own default template.
+const (
+	messageChange   = "change"   // one scrape's schedule changes
+	messageDigest   = "digest"   // the changes over a period
+	messageReminder = "reminder" // a game day's games
+	messageResult   = "result"   // a newly posted final score
+)
+
+var messageKinds = []string{messageChange, messageDigest, messageReminder, messageResult}
+
+// notification is what message templates render. Which fields are set
+// depends on Kind: Diff for a change, Since and Changes for a digest, Date,
+// Group and Games for a reminder, Result for a result.
+type notification struct {
+	Kind    string           `json:"kind"`
+	EventID string           `json:"eventid"`
+	ClubID  string           `json:"clubid"`
+	At      time.Time        `json:"at"`
+	Diff    scheduleDiff     `json:"diff"`
+	Since   time.Time        `json:"since"`
+	Changes []scheduleChange `json:"changes"` // oldest first
+	Date    string           `json:"date"`
+	Group   string           `json:"group"` // the club team or field a reminder covers
+	Games   []Game           `json:"games"`
+	Result  postedResult     `json:"result"`
+}
+
+// messageTemplate is a text/template pair over a notification. Besides the
+// standard functions, templates may call describe (a scheduleDiff as lines),
+// join, upper and lower.
+type messageTemplate struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+var defaultMessageTemplates = map[string]messageTemplate{
+	messageChange: {
+		Subject: "Schedule change for event {{.EventID}}",
+		Body:    "Schedule change for event {{.EventID}}, club {{.ClubID}}:\n{{range describe .Diff}}{{.}}\n{{end}}",
+	},
+	messageDigest: {
+		Subject: "{{len .Changes}} schedule changes for event {{.EventID}}",
+		Body: "Schedule changes for event {{.EventID}}, club {{.ClubID}} since {{.Since.Format \"Mon Jan 2\"}}:\n" +
+			"{{range .Changes}}{{.At.Format \"Mon Jan 2 3:04PM\"}}\n{{range describe .Diff}}  {{.}}\n{{end}}{{end}}",
+	},
+	messageReminder: {
+		Subject: "Game day {{.Date}}: {{.Group}}",
+		Body:    "Today's games for {{.Group}} ({{.Date}}):\n{{range .Games}}{{.Time}}  {{.HomeTeam}} vs {{.AwayTeam}} @ {{.Location}}{{with .KitColor}} ({{.}} kit){{end}}\n{{end}}",
+	},
+	messageResult: {
+		Subject: "{{.Result.HomeTeam}} {{.Result.Score}} {{.Result.AwayTeam}}",
+		Body:    "Final: {{.Result.HomeTeam}} {{.Result.Score}} {{.Result.AwayTeam}} ({{.Result.Division}}, {{.Result.Date}})\n",
+	},
+}
+
+var messageFuncs = template.FuncMap{
+	"describe": func(d scheduleDiff) []string { return d.describe() },
+	"join":     strings.Join,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+}
+
+// compiledTemplate is a parsed messageTemplate.
+type compiledTemplate struct {
+	subject, body *template.Template
+}
+
+// compileTemplate parses t and renders it against kind's sample data, so a
+// template naming a field that kind lacks fails at load rather than at send.
+func compileTemplate(kind string, t messageTemplate) (*compiledTemplate, error) {
+	if !slices.Contains(messageKinds, kind) {
+		return nil, badParamsf("unknown message kind %q (want one of %s)", kind, strings.Join(messageKinds, ", "))
+	}
+	def := defaultMessageTemplates[kind]
+	subject, err := template.New(kind + " subject").Funcs(messageFuncs).Parse(firstNonEmpty(t.Subject, def.Subject))
+	if err != nil {
+		return nil, badParamsf("%v", err)
+	}
+	body, err := template.New(kind).Funcs(messageFuncs).Parse(firstNonEmpty(t.Body, def.Body))
+	if err != nil {
+		return nil, badParamsf("%v", err)
+	}
+	c := &compiledTemplate{subject: subject, body: body}
+	if _, err := c.render(sampleNotification(kind)); err != nil {
+		return nil, badParamsf("%v", err)
+	}
+	return c, nil
+}
+
+func (c *compiledTemplate) render(n notification) (message, error) {
+	var subject, body strings.Builder
+	if err := c.subject.Execute(&subject, n); err != nil {
+		return message{}, err
+	}
+	if err := c.body.Execute(&body, n); err != nil {
+		return message{}, err
+	}
+	return message{Subject: strings.TrimSpace(subject.String()), Text: body.String()}, nil
+}
+
+// messageTemplates are one channel's templates, keyed by templateKey.
+type messageTemplates map[string]*compiledTemplate
+
+// templateKey keys a kind's template for one tenant (club ID), or for every
+// tenant when tenant is empty.
+func templateKey(tenant, kind string) string { return tenant + "/" + kind }
+
+// compileTemplates compiles a channel's templates and its tenants'.
+func compileTemplates(channel map[string]messageTemplate, tenants map[string]map[string]messageTemplate) (messageTemplates, error) {
+	out := messageTemplates{}
+	add := func(tenant string, ts map[string]messageTemplate) error {
+		for kind, t := range ts {
+			c, err := compileTemplate(kind, t)
+			if err != nil {
+				if tenant != "" {
+					return fmt.Errorf("tenant %s: %s template: %v", tenant, kind, err)
+				}
+				return fmt.Errorf("%s template: %v", kind, err)
+			}
+			out[templateKey(tenant, kind)] = c
+		}
+		return nil
+	}
+	if err := add("", channel); err != nil {
+		return nil, err
+	}
+	for tenant, ts := range tenants {
+		if err := add(tenant, ts); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// lookup returns the template for n's kind and tenant, falling back to the
+// channel's and then the default.
+func (ts messageTemplates) lookup(n notification) (*compiledTemplate, error) {
+	if c := ts[templateKey(n.ClubID, n.Kind)]; c != nil {
+		return c, nil
+	}
+	if c := ts[templateKey("", n.Kind)]; c != nil {
+		return c, nil
+	}
+	return compileTemplate(n.Kind, messageTemplate{})
+}
+
+func (ts messageTemplates) render(n notification) (message, error) {
+	c, err := ts.lookup(n)
+	if err != nil {
+		return message{}, err
+	}
+	return c.render(n)
+}
+
+// sampleNotification is made-up data of each kind for checking and
+// previewing templates.
+func sampleNotification(kind string) notification {
+	at := time.Date(2025, 8, 28, 18, 30, 0, 0, time.UTC)
+	home := Game{ID: "sample-1", Date: "2025-08-30", Time: "9:00AM", HomeTeam: "Reno Apex 2012B", AwayTeam: "Placer United 2012B", Location: "Golden Eagle Regional Park - Field 3", Division: "U13 Boys Premier"}
+	away := Game{ID: "sample-2", Date: "2025-08-30", Time: "2:30PM", HomeTeam: "Sacramento United 2012B", AwayTeam: "Reno Apex 2012B", Location: "Cherry Island Soccer Complex - Field 7", Division: "U13 Boys Premier"}
+	moved := home
+	moved.Time, moved.Location = "10:30AM", "Golden Eagle Regional Park - Field 5"
+	diff := scheduleDiff{Added: []Game{away}, Changed: []gameChange{{Before: home, After: moved, Fields: []string{"time", "location"}}}}
+	n := notification{Kind: kind, EventID: "44145", ClubID: "12893", At: at}
+	switch kind {
+	case messageChange:
+		n.Diff = diff
+	case messageDigest:
+		n.Since = at.AddDate(0, 0, -7)
+		n.Changes = []scheduleChange{{EventID: n.EventID, ClubID: n.ClubID, At: at.Add(-26 * time.Hour), Diff: scheduleDiff{Added: []Game{away}}},
+			{EventID: n.EventID, ClubID: n.ClubID, At: at, Diff: scheduleDiff{Changed: diff.Changed}}}
+	case messageReminder:
+		n.Date, n.Group = home.Date, "Reno Apex 2012B"
+		n.Games = []Game{moved, away}
+	case messageResult:
+		n.Result = postedResult{GameID: home.ID, Date: home.Date, HomeTeam: home.HomeTeam, AwayTeam: home.AwayTeam, Result: "3 - 1", Division: home.Division}
+	}
+	return n
+}
+
+// previewRequest asks /admin/notify/preview to render a message. Template
+// is rendered if given, else Channel's template for Tenant, else the
+// default; Data replaces the sample notification.
+type previewRequest struct {
+	Kind     string           `json:"kind"`
+	Channel  string           `json:"channel"`
+	Tenant   string           `json:"tenant"`
+	Template *messageTemplate `json:"template"`
+	Data     *notification    `json:"data"`
+}
+
+// adminNotifyPreviewHandler renders a message template against sample or
+// given data and returns the message it would send. POST a previewRequest.
+func adminNotifyPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method_not_allowed", Detail: "Use POST"})
+		return
+	}
+	var req previewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Detail: "Invalid JSON body"})
+		return
+	}
+	req.Kind = firstNonEmpty(req.Kind, messageChange)
+	n := sampleNotification(req.Kind)
+	if req.Data != nil {
+		n = *req.Data
+	}
+	n.Kind = req.Kind
+	if req.Tenant != "" {
+		n.ClubID = req.Tenant
+	}
+
+	var (
+		m   message
+		err error
+	)
+	switch {
+	case req.Template != nil:
+		var c *compiledTemplate
+		if c, err = compileTemplate(req.Kind, *req.Template); err == nil {
+			m, err = c.render(n)
+		}
+	case req.Channel != "":
+		c := notifications.channel(req.Channel)
+		if c == nil {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "No notification channel named " + req.Channel})
+			return
+		}
+		m, err = c.templates.render(n)
+	default:
+		m, err = messageTemplates{}.render(n)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(classify(ErrBadParams, err)))
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
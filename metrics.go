@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/* ---------- Metrics ---------- */
+
+// A small Prometheus text-format registry; label values are stored as a
+// joined key so callers can pass label pairs inline.
+
+type metricKind string
+
+const (
+	kindCounter   metricKind = "counter"
+	kindGauge     metricKind = "gauge"
+	kindHistogram metricKind = "histogram"
+)
+
+type metricFamily struct {
+	kind   metricKind
+	help   string
+	values map[string]float64 // key: rendered label set, e.g. `reason="inflight"`
+
+	buckets []float64             // histogram upper bounds, ascending
+	hists   map[string]*histogram // histogram series by label set
+}
+
+type histogram struct {
+	counts []uint64 // cumulative per bucket
+	count  uint64
+	sum    float64
+}
+
+var (
+	// secondsBuckets suit parse and fetch durations.
+	secondsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+	// bytesBuckets suit upstream page sizes.
+	bytesBuckets = []float64{16 << 10, 64 << 10, 256 << 10, 1 << 20, 2 << 20, 5 << 20, 10 << 20}
+)
+
+var (
+	metricsMu sync.Mutex
+	metrics   = map[string]*metricFamily{}
+)
+
+func describeMetric(name string, kind metricKind, help string) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if _, ok := metrics[name]; !ok {
+		metrics[name] = &metricFamily{kind: kind, help: help, values: map[string]float64{}}
+	}
+}
+
+// labelKey renders label pairs ("k1", "v1", "k2", "v2") in Prometheus syntax.
+func labelKey(labels []string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%q", labels[i], labels[i+1])
+	}
+	return b.String()
+}
+
+func describeHistogram(name, help string, buckets []float64) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if _, ok := metrics[name]; !ok {
+		metrics[name] = &metricFamily{kind: kindHistogram, help: help, buckets: buckets, hists: map[string]*histogram{}}
+	}
+}
+
+// observe records v in a histogram registered with describeHistogram.
+func observe(name string, v float64, labels ...string) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	f, ok := metrics[name]
+	if !ok || f.kind != kindHistogram {
+		return
+	}
+	key := labelKey(labels)
+	h, ok := f.hists[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(f.buckets))}
+		f.hists[key] = h
+	}
+	for i, ub := range f.buckets {
+		if v <= ub {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+func family(name string, kind metricKind) *metricFamily {
+	f, ok := metrics[name]
+	if !ok {
+		f = &metricFamily{kind: kind, values: map[string]float64{}}
+		metrics[name] = f
+	}
+	return f
+}
+
+func incCounter(name string, labels ...string) {
+	addCounter(name, 1, labels...)
+}
+
+func addCounter(name string, v float64, labels ...string) {
+	metricsMu.Lock()
+	family(name, kindCounter).values[labelKey(labels)] += v
+	metricsMu.Unlock()
+}
+
+func setGauge(name string, v float64, labels ...string) {
+	metricsMu.Lock()
+	family(name, kindGauge).values[labelKey(labels)] = v
+	metricsMu.Unlock()
+}
+
+// metricValue returns the current value of one series, mainly for /stats.
+func metricValue(name string, labels ...string) float64 {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if f, ok := metrics[name]; ok {
+		return f.values[labelKey(labels)]
+	}
+	return 0
+}
+
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	for _, name := range names {
+		f := metrics[name]
+		if f.help != "" {
+			fmt.Fprintf(w, "# HELP %s %s\n", name, f.help)
+		}
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, f.kind)
+		if f.kind == kindHistogram {
+			writeHistograms(w, name, f)
+			continue
+		}
+		keys := make([]string, 0, len(f.values))
+		for k := range f.values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if k == "" {
+				fmt.Fprintf(w, "%s %g\n", name, f.values[k])
+			} else {
+				fmt.Fprintf(w, "%s{%s} %g\n", name, k, f.values[k])
+			}
+		}
+	}
+}
+
+func writeHistograms(w http.ResponseWriter, name string, f *metricFamily) {
+	keys := make([]string, 0, len(f.hists))
+	for k := range f.hists {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		h := f.hists[k]
+		sep := ""
+		if k != "" {
+			sep = ","
+		}
+		for i, ub := range f.buckets {
+			fmt.Fprintf(w, "%s_bucket{%s%sle=\"%g\"} %d\n", name, k, sep, ub, h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, k, sep, h.count)
+		if k == "" {
+			fmt.Fprintf(w, "%s_sum %g\n%s_count %d\n", name, h.sum, name, h.count)
+		} else {
+			fmt.Fprintf(w, "%s_sum{%s} %g\n%s_count{%s} %d\n", name, k, h.sum, name, k, h.count)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+)
+
+/* ---------- MessagePack output ---------- */
+
+// encodeMsgpack encodes v as MessagePack. v goes through encoding/json first
+// so field names and omitempty follow the same struct tags as JSON output.
+func encodeMsgpack(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := packValue(&buf, generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func packValue(b *bytes.Buffer, v any) error {
+	switch x := v.(type) {
+	case nil:
+		b.WriteByte(0xc0)
+	case bool:
+		if x {
+			b.WriteByte(0xc3)
+		} else {
+			b.WriteByte(0xc2)
+		}
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			packInt(b, i)
+			return nil
+		}
+		f, err := x.Float64()
+		if err != nil {
+			return err
+		}
+		b.WriteByte(0xcb)
+		_ = binary.Write(b, binary.BigEndian, math.Float64bits(f))
+	case string:
+		packString(b, x)
+	case []any:
+		packLen(b, len(x), 0x90, 0xdc, 0xdd)
+		for _, e := range x {
+			if err := packValue(b, e); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		packLen(b, len(keys), 0x80, 0xde, 0xdf)
+		for _, k := range keys {
+			packString(b, k)
+			if err := packValue(b, x[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+	return nil
+}
+
+func packInt(b *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i < 128:
+		b.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		b.WriteByte(byte(int8(i)))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		b.WriteByte(0xd2)
+		_ = binary.Write(b, binary.BigEndian, int32(i))
+	default:
+		b.WriteByte(0xd3)
+		_ = binary.Write(b, binary.BigEndian, i)
+	}
+}
+
+func packString(b *bytes.Buffer, s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		b.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		b.WriteByte(0xd9)
+		b.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		b.WriteByte(0xda)
+		_ = binary.Write(b, binary.BigEndian, uint16(n))
+	default:
+		b.WriteByte(0xdb)
+		_ = binary.Write(b, binary.BigEndian, uint32(n))
+	}
+	b.WriteString(s)
+}
+
+// packLen writes an array or map header: fix form below 16, else 16/32-bit.
+func packLen(b *bytes.Buffer, n int, fix, c16, c32 byte) {
+	switch {
+	case n < 16:
+		b.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		b.WriteByte(c16)
+		_ = binary.Write(b, binary.BigEndian, uint16(n))
+	default:
+		b.WriteByte(c32)
+		_ = binary.Write(b, binary.BigEndian, uint32(n))
+	}
+}
+
+func writeMsgpack(w http.ResponseWriter, status int, v any) {
+	body, err := encodeMsgpack(v)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "encode_failed", Detail: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/msgpack")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
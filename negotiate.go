@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/* ---------- Content negotiation ---------- */
+
+// outputFormat is one schedule representation, selectable by format= or by
+// the Accept header when negotiable.
+type outputFormat struct {
+	Name       string
+	MediaTypes []string // first is canonical
+	Negotiable bool     // selectable through Accept
+}
+
+var outputFormats = []outputFormat{
+	{Name: "json", MediaTypes: []string{"application/json"}, Negotiable: true},
+	{Name: "ics", MediaTypes: []string{"text/calendar"}, Negotiable: true},
+	{Name: "csv", MediaTypes: []string{"text/csv"}, Negotiable: true},
+	{Name: "xml", MediaTypes: []string{"application/xml", "text/xml"}, Negotiable: true},
+	{Name: "rss", MediaTypes: []string{"application/rss+xml"}, Negotiable: true},
+	{Name: "msgpack", MediaTypes: []string{"application/msgpack", "application/x-msgpack"}, Negotiable: true},
+	{Name: "pdf", MediaTypes: []string{"application/pdf"}, Negotiable: true},
+	{Name: "xlsx", MediaTypes: []string{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}, Negotiable: true},
+	// Browsers send text/html first, so the print view is never negotiated;
+	// /schedule in a browser tab keeps returning JSON.
+	{Name: "print", MediaTypes: []string{"text/html"}},
+}
+
+// errNotAcceptable means the Accept header names nothing we can produce.
+type errNotAcceptable struct{ accept string }
+
+func (e errNotAcceptable) Error() string {
+	return fmt.Sprintf("none of %q can be produced; supported: %s", e.accept, supportedMediaTypes())
+}
+
+func supportedMediaTypes() string {
+	var types []string
+	for _, f := range outputFormats {
+		if f.Negotiable {
+			types = append(types, f.MediaTypes[0])
+		}
+	}
+	return strings.Join(types, ", ")
+}
+
+func formatByName(name string) (outputFormat, bool) {
+	for _, f := range outputFormats {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return outputFormat{}, false
+}
+
+// negotiateFormat picks the output format: an explicit format= wins, then the
+// best Accept match, then JSON.
+func negotiateFormat(r *http.Request, explicit string) (string, error) {
+	if explicit != "" {
+		name := strings.ToLower(explicit)
+		if _, ok := formatByName(name); !ok {
+			names := make([]string, len(outputFormats))
+			for i, f := range outputFormats {
+				names[i] = f.Name
+			}
+			return "", fmt.Errorf("format must be one of %s", strings.Join(names, ", "))
+		}
+		return name, nil
+	}
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return "json", nil
+	}
+	ranges := parseAccept(accept)
+	// A browser tab prefers text/html and lists application/xml after it;
+	// keep serving it JSON rather than XML.
+	if len(ranges) > 0 && (ranges[0] == "text/html" || ranges[0] == "application/xhtml+xml") {
+		return "json", nil
+	}
+	for _, mt := range ranges {
+		if mt == "*/*" || mt == "application/*" {
+			return "json", nil
+		}
+		for _, f := range outputFormats {
+			if !f.Negotiable {
+				continue
+			}
+			for _, t := range f.MediaTypes {
+				if t == mt || (strings.HasSuffix(mt, "/*") && strings.HasPrefix(t, strings.TrimSuffix(mt, "*"))) {
+					return f.Name, nil
+				}
+			}
+		}
+	}
+	return "", errNotAcceptable{accept}
+}
+
+// parseAccept returns the media ranges of an Accept header, best first,
+// dropping any with q=0.
+func parseAccept(h string) []string {
+	type rng struct {
+		mt string
+		q  float64
+		i  int
+	}
+	var ranges []rng
+	for i, part := range strings.Split(h, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, rng{mt, q, i})
+		}
+	}
+	sort.SliceStable(ranges, func(a, b int) bool { return ranges[a].q > ranges[b].q })
+	out := make([]string, len(ranges))
+	for i, r := range ranges {
+		out[i] = r.mt
+	}
+	return out
+}
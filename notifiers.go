@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/* ---------- Notification channels ---------- */
+
+var notifyClient = &http.Client{Timeout: 10 * time.Second}
+
+// discordMaxContent is the longest message Discord accepts.
+const discordMaxContent = 2000
+
+func init() {
+	registerNotifier("slack", func(s map[string]string) (Notifier, error) {
+		return newWebhookNotifier(s, func(m message) any { return map[string]string{"text": m.Text} })
+	})
+	registerNotifier("discord", func(s map[string]string) (Notifier, error) {
+		return newWebhookNotifier(s, func(m message) any {
+			return map[string]string{"content": truncateRunes(m.Text, discordMaxContent)}
+		})
+	})
+	registerNotifier("webhook", func(s map[string]string) (Notifier, error) {
+		return newWebhookNotifier(s, func(m message) any { return m })
+	})
+	registerNotifier("email", newEmailNotifier)
+	registerNotifier("sms", newSMSNotifier)
+}
+
+// requireSettings fails unless every named setting is non-empty.
+func requireSettings(s map[string]string, names ...string) error {
+	var missing []string
+	for _, n := range names {
+		if s[n] == "" {
+			missing = append(missing, n)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing settings: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// notifyDo sends req, failing on a non-2xx response.
+func notifyDo(req *http.Request) error {
+	resp, err := notifyClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// webhookNotifier posts each message as JSON to a URL (setting "webhook"),
+// shaped by payload: Slack and Discord incoming webhooks, or the message
+// itself for a plain webhook.
+type webhookNotifier struct {
+	url     string
+	payload func(message) any
+}
+
+func newWebhookNotifier(s map[string]string, payload func(message) any) (Notifier, error) {
+	if err := requireSettings(s, "webhook"); err != nil {
+		return nil, err
+	}
+	return webhookNotifier{url: s["webhook"], payload: payload}, nil
+}
+
+func (w webhookNotifier) Send(ctx context.Context, m message) error {
+	body, err := json.Marshal(w.payload(m))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return notifyDo(req)
+}
+
+// emailNotifier sends plain-text mail over SMTP. Settings: addr (host:port),
+// from, to (comma-separated), and optionally username and password for
+// PLAIN auth.
+type emailNotifier struct {
+	addr, from string
+	to         []string
+	auth       smtp.Auth
+}
+
+func newEmailNotifier(s map[string]string) (Notifier, error) {
+	if err := requireSettings(s, "addr", "from", "to"); err != nil {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(s["addr"])
+	if err != nil {
+		return nil, fmt.Errorf("addr: %v", err)
+	}
+	e := emailNotifier{addr: s["addr"], from: s["from"], to: splitList(s["to"])}
+	if s["username"] != "" {
+		e.auth = smtp.PlainAuth("", s["username"], s["password"], host)
+	}
+	return e, nil
+}
+
+// Send ignores ctx, which net/smtp does not take; the job timeout still
+// bounds the worker.
+func (e emailNotifier) Send(_ context.Context, m message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(m.Text, "\n", "\r\n"))
+	return smtp.SendMail(e.addr, e.auth, e.from, e.to, []byte(b.String()))
+}
+
+// smsNotifier texts each recipient through Twilio's Messages API. Settings:
+// accountSid, authToken, from, and to (comma-separated numbers). A retry
+// after a partial failure texts every recipient again.
+type smsNotifier struct {
+	accountSID, authToken, from string
+	to                          []string
+}
+
+func newSMSNotifier(s map[string]string) (Notifier, error) {
+	if err := requireSettings(s, "accountSid", "authToken", "from", "to"); err != nil {
+		return nil, err
+	}
+	return smsNotifier{accountSID: s["accountSid"], authToken: s["authToken"], from: s["from"], to: splitList(s["to"])}, nil
+}
+
+func (n smsNotifier) Send(ctx context.Context, m message) error {
+	endpoint := "https://api.twilio.com/2010-04-01/Accounts/" + url.PathEscape(n.accountSID) + "/Messages.json"
+	for _, to := range n.to {
+		form := url.Values{"From": {n.from}, "To": {to}, "Body": {m.Text}}
+		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(n.accountSID, n.authToken)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := notifyDo(req); err != nil {
+			return fmt.Errorf("%s: %v", to, err)
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma-separated setting, dropping blanks.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// truncateRunes cuts s to at most n runes, marking the cut with an ellipsis.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ---------- Notifications ---------- */
+
+// Notifier delivers a rendered message to one channel. Each channel kind
+// (slack, discord, email, sms, webhook) is a plugin registered with
+// registerNotifier.
+type Notifier interface {
+	Send(ctx context.Context, m message) error
+}
+
+// message is a notification rendered for one channel.
+type message struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+// notifierPlugin builds a Notifier from a channel's settings, failing when
+// one it needs is missing.
+type notifierPlugin func(settings map[string]string) (Notifier, error)
+
+var (
+	notifierPluginsMu sync.RWMutex
+	notifierPlugins   = map[string]notifierPlugin{}
+)
+
+func registerNotifier(kind string, p notifierPlugin) {
+	notifierPluginsMu.Lock()
+	notifierPlugins[kind] = p
+	notifierPluginsMu.Unlock()
+}
+
+func lookupNotifier(kind string) notifierPlugin {
+	notifierPluginsMu.RLock()
+	defer notifierPluginsMu.RUnlock()
+	return notifierPlugins[kind]
+}
+
+// notifyConfig is the NOTIFY_CONFIG file:
+//
+//	{
+//	  "channels": [
+//	    {"name": "coaches", "kind": "slack", "settings": {"webhook": "$SLACK_WEBHOOK"},
+//	     "templates": {"change": {"body": "*Event {{.EventID}}*\n{{join (describe .Diff) \"\\n\"}}"}}},
+//	    {"name": "parents", "kind": "sms", "settings": {"accountSid": "...", "authToken": "$TWILIO_TOKEN", "from": "+17755550100", "to": "+17755550101"}}
+//	  ],
+//	  "routes": [
+//	    {"channels": ["coaches"]},
+//	    {"channels": ["parents"], "teams": ["2012B"], "changes": ["time", "location"]},
+//	    {"channels": ["parents"], "messages": ["reminder"], "teams": ["2012B"]}
+//	  ]
+//	}
+//
+// Setting values have $VARS expanded from the environment, so secrets need
+// not live in the file.
+type notifyConfig struct {
+	Channels []channelConfig `json:"channels"`
+	Routes   []notifyRoute   `json:"routes"`
+}
+
+// channelConfig is one destination. Templates override the default message
+// for each kind (change, digest, reminder), and Tenants override them again
+// for one club ID's messages; see messageTemplate.
+type channelConfig struct {
+	Name      string                                `json:"name"`
+	Kind      string                                `json:"kind"`
+	Settings  map[string]string                     `json:"settings"`
+	Templates map[string]messageTemplate            `json:"templates"`
+	Tenants   map[string]map[string]messageTemplate `json:"tenants"`
+	// MaxAttempts bounds delivery retries (default 5).
+	MaxAttempts int `json:"maxAttempts"`
+}
+
+// notifyRoute sends the messages it matches to its channels. Each list is
+// optional and narrows the route; teams and divisions match by substring,
+// ignoring case.
+type notifyRoute struct {
+	Channels []string `json:"channels"`
+	// Messages are the kinds of message the route carries (default change).
+	Messages  []string `json:"messages"`
+	Events    []string `json:"events"`
+	Teams     []string `json:"teams"`
+	Divisions []string `json:"divisions"`
+	// Changes are added, removed, or a moved game's date, time or location;
+	// they narrow change messages only.
+	Changes []string `json:"changes"`
+}
+
+var notifyChangeKinds = []string{"added", "removed", "date", "time", "location"}
+
+const (
+	jobKindNotify         = "notify"
+	defaultNotifyAttempts = 5
+)
+
+// notifyChannel is a configured channel ready to send.
+type notifyChannel struct {
+	channelConfig
+	notifier  Notifier
+	templates messageTemplates
+}
+
+// dispatcher routes schedule changes to channels and queues their delivery.
+type dispatcher struct {
+	channels []*notifyChannel
+	routes   []notifyRoute
+}
+
+// notifications is nil unless NOTIFY_CONFIG is set.
+var notifications *dispatcher
+
+func init() {
+	describeMetric("gotsport_notifications_total", kindCounter, "Notification delivery attempts, by channel and outcome (sent, failed or dropped).")
+	registerJobHandler(jobKindNotify, func(ctx context.Context, payload json.RawMessage) error {
+		var job notifyJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return err
+		}
+		c := notifications.channel(job.Channel)
+		if c == nil {
+			// The channel was removed from the config since; retrying won't help.
+			log.Printf("notify: dropping message for unknown channel %q", job.Channel)
+			incCounter("gotsport_notifications_total", "channel", job.Channel, "outcome", "dropped")
+			return nil
+		}
+		if err := c.notifier.Send(ctx, job.Message); err != nil {
+			incCounter("gotsport_notifications_total", "channel", c.Name, "outcome", "failed")
+			return fmt.Errorf("notify %s: %v", c.Name, err)
+		}
+		incCounter("gotsport_notifications_total", "channel", c.Name, "outcome", "sent")
+		return nil
+	})
+}
+
+// notifyJob is one queued delivery. The message is rendered when queued, so
+// a retry sends what the first attempt would have.
+type notifyJob struct {
+	Channel string  `json:"channel"`
+	Message message `json:"message"`
+}
+
+// loadDispatcher reads the NOTIFY_CONFIG file at path; an empty path
+// disables notifications.
+func loadDispatcher(path string) (*dispatcher, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg notifyConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return newDispatcher(cfg)
+}
+
+func newDispatcher(cfg notifyConfig) (*dispatcher, error) {
+	d := &dispatcher{routes: cfg.Routes}
+	for _, cc := range cfg.Channels {
+		if cc.Name == "" || d.channel(cc.Name) != nil {
+			return nil, fmt.Errorf("channel names must be set and unique, got %q", cc.Name)
+		}
+		plugin := lookupNotifier(cc.Kind)
+		if plugin == nil {
+			return nil, fmt.Errorf("channel %s: unknown kind %q", cc.Name, cc.Kind)
+		}
+		settings := map[string]string{}
+		for k, v := range cc.Settings {
+			settings[k] = os.ExpandEnv(v)
+		}
+		n, err := plugin(settings)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %v", cc.Name, err)
+		}
+		c := &notifyChannel{channelConfig: cc, notifier: n}
+		if c.MaxAttempts <= 0 {
+			c.MaxAttempts = defaultNotifyAttempts
+		}
+		if c.templates, err = compileTemplates(cc.Templates, cc.Tenants); err != nil {
+			return nil, fmt.Errorf("channel %s: %v", cc.Name, err)
+		}
+		d.channels = append(d.channels, c)
+	}
+	for i, r := range cfg.Routes {
+		if len(r.Channels) == 0 {
+			return nil, fmt.Errorf("route %d: no channels", i+1)
+		}
+		for _, name := range r.Channels {
+			if d.channel(name) == nil {
+				return nil, fmt.Errorf("route %d: unknown channel %q", i+1, name)
+			}
+		}
+		for _, kind := range r.Messages {
+			if !slices.Contains(messageKinds, kind) {
+				return nil, fmt.Errorf("route %d: unknown message kind %q (want one of %s)", i+1, kind, strings.Join(messageKinds, ", "))
+			}
+		}
+		for _, c := range r.Changes {
+			if !slices.Contains(notifyChangeKinds, c) {
+				return nil, fmt.Errorf("route %d: unknown change %q (want one of %s)", i+1, c, strings.Join(notifyChangeKinds, ", "))
+			}
+		}
+	}
+	return d, nil
+}
+
+func (d *dispatcher) channel(name string) *notifyChannel {
+	if d == nil {
+		return nil
+	}
+	for _, c := range d.channels {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// carries reports whether the route sends kind messages.
+func (r notifyRoute) carries(kind string) bool {
+	if len(r.Messages) == 0 {
+		return kind == messageChange
+	}
+	return slices.Contains(r.Messages, kind)
+}
+
+// matches reports whether the route covers g in eventID.
+func (r notifyRoute) matches(eventID string, g Game) bool {
+	if len(r.Events) > 0 && !slices.Contains(r.Events, eventID) {
+		return false
+	}
+	if len(r.Teams) > 0 && !slices.ContainsFunc(r.Teams, func(t string) bool {
+		return containsFold(g.HomeTeam, t) || containsFold(g.AwayTeam, t)
+	}) {
+		return false
+	}
+	if len(r.Divisions) > 0 && !slices.ContainsFunc(r.Divisions, func(div string) bool { return containsFold(g.Division, div) }) {
+		return false
+	}
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// wants reports whether some route sends channel kind messages about g in
+// eventID. For a change, changes are what happened to g: added, removed,
+// or the fields that moved.
+func (d *dispatcher) wants(channel, kind, eventID string, g Game, changes ...string) bool {
+	for _, r := range d.routes {
+		if !slices.Contains(r.Channels, channel) || !r.carries(kind) || !r.matches(eventID, g) {
+			continue
+		}
+		if kind == messageChange && len(r.Changes) > 0 &&
+			!slices.ContainsFunc(changes, func(c string) bool { return slices.Contains(r.Changes, c) }) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// routed narrows diff to what any route sends to channel.
+func (d *dispatcher) routed(channel, eventID string, diff scheduleDiff) scheduleDiff {
+	var out scheduleDiff
+	for _, g := range diff.Added {
+		if d.wants(channel, messageChange, eventID, g, "added") {
+			out.Added = append(out.Added, g)
+		}
+	}
+	for _, g := range diff.Removed {
+		if d.wants(channel, messageChange, eventID, g, "removed") {
+			out.Removed = append(out.Removed, g)
+		}
+	}
+	for _, c := range diff.Changed {
+		if d.wants(channel, messageChange, eventID, c.After, c.Fields...) || d.wants(channel, messageChange, eventID, c.Before, c.Fields...) {
+			out.Changed = append(out.Changed, c)
+		}
+	}
+	return out
+}
+
+// notifyChange queues a message to every channel some route sends part of
+// diff to.
+func (d *dispatcher) notifyChange(eventID, clubID string, diff scheduleDiff) {
+	if d == nil || diff.empty() {
+		return
+	}
+	at := time.Now()
+	for _, c := range d.channels {
+		part := d.routed(c.Name, eventID, diff)
+		if part.empty() {
+			continue
+		}
+		d.send(c, notification{Kind: messageChange, EventID: eventID, ClubID: clubID, At: at, Diff: part})
+	}
+}
+
+// send renders n for c and queues its delivery on the job queue, which
+// retries failures with backoff up to the channel's MaxAttempts.
+func (d *dispatcher) send(c *notifyChannel, n notification) {
+	m, err := c.templates.render(n)
+	if err != nil {
+		log.Printf("notify %s: %s template: %v", c.Name, n.Kind, err)
+		incCounter("gotsport_notifications_total", "channel", c.Name, "outcome", "dropped")
+		return
+	}
+	if err := enqueueJob(jobKindNotify, notifyJob{Channel: c.Name, Message: m}, jobOptions{MaxAttempts: c.MaxAttempts}); err != nil {
+		log.Printf("notify %s: %v", c.Name, err)
+	}
+}
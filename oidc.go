@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/* ---------- OIDC login ---------- */
+
+const oidcStateCookie = "oidc_state"
+
+// oidcLoginEnabled reports whether browser sign-in is configured.
+func oidcLoginEnabled() bool {
+	return appConfig.JWTIssuer != "" && appConfig.OIDCClientID != "" &&
+		appConfig.OIDCClientSecret != "" && appConfig.OIDCRedirectURL != ""
+}
+
+// safeNext keeps post-login redirects on admin pages of this host.
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/admin/") || strings.HasPrefix(next, "//") {
+		return "/admin/"
+	}
+	return next
+}
+
+// adminLoginHandler starts the authorization-code flow at the identity
+// provider, e.g. Google Workspace.
+func adminLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if !oidcLoginEnabled() {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "OIDC sign-in is not configured"})
+		return
+	}
+	p, err := provider(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: "oidc_unavailable", Detail: err.Error()})
+		return
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "internal", Detail: err.Error()})
+		return
+	}
+	state := hex.EncodeToString(buf)
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state + "|" + safeNext(r.URL.Query().Get("next")),
+		Path:     "/admin/",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	q := url.Values{
+		"response_type": {"code"},
+		"client_id":     {appConfig.OIDCClientID},
+		"redirect_uri":  {appConfig.OIDCRedirectURL},
+		"scope":         {"openid email profile"},
+		"state":         {state},
+	}
+	if len(appConfig.AdminDomains) == 1 {
+		q.Set("hd", appConfig.AdminDomains[0]) // Google: preselect the Workspace account
+	}
+	http.Redirect(w, r, p.AuthEndpoint+"?"+q.Encode(), http.StatusFound)
+}
+
+// adminCallbackHandler exchanges the authorization code for an ID token and
+// keeps it as the session cookie once it passes admin checks.
+func adminCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	if !oidcLoginEnabled() {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "OIDC sign-in is not configured"})
+		return
+	}
+	c, err := r.Cookie(oidcStateCookie)
+	state, next, _ := strings.Cut(valueOf(c, err), "|")
+	if state == "" || r.URL.Query().Get("state") != state {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Detail: "Sign-in state mismatch; start again at /admin/login"})
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/admin/", MaxAge: -1})
+	if msg := r.URL.Query().Get("error"); msg != "" {
+		writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "unauthorized", Detail: "Sign-in failed: " + msg})
+		return
+	}
+
+	p, err := provider(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: "oidc_unavailable", Detail: err.Error()})
+		return
+	}
+	resp, err := oidcClient.PostForm(p.TokenEndpoint, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {r.URL.Query().Get("code")},
+		"redirect_uri":  {appConfig.OIDCRedirectURL},
+		"client_id":     {appConfig.OIDCClientID},
+		"client_secret": {appConfig.OIDCClientSecret},
+	})
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: "oidc_unavailable", Detail: err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+	var tok struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil || tok.IDToken == "" {
+		writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: "oidc_unavailable", Detail: "Token exchange failed: " + firstNonEmpty(tok.Error, resp.Status)})
+		return
+	}
+	claims, err := verifyJWT(r.Context(), tok.IDToken)
+	if err == nil {
+		err = authorizeAdmin(claims)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusForbidden, ErrorResponse{Error: "forbidden", Detail: err.Error()})
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcSessionName,
+		Value:    tok.IDToken,
+		Path:     "/",
+		Expires:  time.Unix(claims.Expires, 0),
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, safeNext(next), http.StatusSeeOther)
+}
+
+// adminLogoutHandler drops the session cookie.
+func adminLogoutHandler(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: oidcSessionName, Path: "/", MaxAge: -1})
+	http.Redirect(w, r, "/status", http.StatusSeeOther)
+}
+
+func valueOf(c *http.Cookie, err error) string {
+	if err != nil {
+		return ""
+	}
+	return c.Value
+}
+
+// isHTTPS reports whether the client connection is TLS, directly or at a
+// trusted proxy.
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || (appConfig.TrustProxy && r.Header.Get("X-Forwarded-Proto") == "https")
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ---------- Manual overrides ---------- */
+
+// gameOverride corrects a scraped game until upstream catches up. Empty
+// fields leave the scraped value alone.
+type gameOverride struct {
+	GameID    string    `json:"gameId"`
+	Location  string    `json:"location,omitempty"`
+	Date      string    `json:"date,omitempty"` // 2006-01-02
+	Time      string    `json:"time,omitempty"` // "3:04PM", in EVENT_TZ
+	Status    string    `json:"status,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// gameStatuses are the values an override may set Status to.
+var gameStatuses = []string{"scheduled", "rescheduled", "postponed", "cancelled"}
+
+// overrideSet is an in-memory copy of the game_overrides table.
+type overrideSet struct {
+	mu   sync.RWMutex
+	byID map[string]gameOverride
+}
+
+func (s *overrideSet) replace(overrides []gameOverride) {
+	m := make(map[string]gameOverride, len(overrides))
+	for _, o := range overrides {
+		m[o.GameID] = o
+	}
+	s.mu.Lock()
+	s.byID = m
+	s.mu.Unlock()
+}
+
+func (s *overrideSet) list() []gameOverride {
+	s.mu.RLock()
+	out := make([]gameOverride, 0, len(s.byID))
+	for _, o := range s.byID {
+		out = append(out, o)
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].GameID < out[j].GameID })
+	return out
+}
+
+// normalize trims o and checks its fields, returning the problem in terms of
+// the API.
+func (o *gameOverride) normalize() string {
+	o.GameID, o.Location, o.Note = strings.TrimSpace(o.GameID), strings.TrimSpace(o.Location), strings.TrimSpace(o.Note)
+	o.Date, o.Status = strings.TrimSpace(o.Date), strings.ToLower(strings.TrimSpace(o.Status))
+	o.Time = strings.ToUpper(strings.ReplaceAll(o.Time, " ", ""))
+	if o.GameID == "" {
+		return "gameId is required"
+	}
+	if o.Location == "" && o.Date == "" && o.Time == "" && o.Status == "" && o.Note == "" {
+		return "set at least one of location, date, time, status or note"
+	}
+	if o.Date != "" {
+		if _, err := time.Parse("2006-01-02", o.Date); err != nil {
+			return "date must be like 2025-08-30"
+		}
+	}
+	if o.Time != "" {
+		t, err := time.Parse("3:04PM", o.Time)
+		if err != nil {
+			return "time must be like 9:30AM"
+		}
+		o.Time = t.Format("3:04PM")
+	}
+	if o.Status != "" && !containsString(gameStatuses, o.Status) {
+		return "status must be one of " + strings.Join(gameStatuses, ", ")
+	}
+	return ""
+}
+
+// apply returns g with the override's fields in place, listing each one in
+// ManualFields. Time is recomputed in EVENT_TZ so a moved date picks up the
+// right zone abbreviation.
+func (o gameOverride) apply(g Game) Game {
+	if o.Location != "" {
+		g.Location = o.Location
+		g.ManualFields = append(g.ManualFields, "location")
+	}
+	if o.Date != "" {
+		g.Date = o.Date
+		g.ManualFields = append(g.ManualFields, "date")
+	}
+	if o.Date != "" || o.Time != "" {
+		clock, _, _ := strings.Cut(g.Time, " ")
+		if o.Time != "" {
+			clock = o.Time
+			g.ManualFields = append(g.ManualFields, "time")
+		}
+		if t, err := time.ParseInLocation("2006-01-02 3:04PM", g.Date+" "+clock, eventLocation()); err == nil {
+			g.Time = t.Format("3:04PM MST")
+		}
+	}
+	if o.Status != "" {
+		g.Status = o.Status
+		g.ManualFields = append(g.ManualFields, "status")
+	}
+	g.ManualNote = o.Note
+	return g
+}
+
+// withOverrides returns a copy of games with any overrides applied; cached
+// slices are never modified. It runs before withVenues and withEndTimes so
+// a moved game gets the new venue's address and end time.
+func (s *Server) withOverrides(games []Game) []Game {
+	out := make([]Game, len(games))
+	s.overrides.mu.RLock()
+	defer s.overrides.mu.RUnlock()
+	for i, g := range games {
+		if o, ok := s.overrides.byID[g.ID]; ok {
+			g = o.apply(g)
+		}
+		out[i] = g
+	}
+	return out
+}
+
+func (s *Server) reloadOverrides() error {
+	rows, err := s.store.Query(`SELECT game_id, location, date, time, status, note, updated_at FROM game_overrides`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var overrides []gameOverride
+	for rows.Next() {
+		var o gameOverride
+		var updated int64
+		if err := rows.Scan(&o.GameID, &o.Location, &o.Date, &o.Time, &o.Status, &o.Note, &updated); err != nil {
+			return err
+		}
+		o.UpdatedAt = time.Unix(updated, 0).UTC()
+		overrides = append(overrides, o)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.overrides.replace(overrides)
+	return nil
+}
+
+// adminOverridesHandler lists (GET), sets (PUT/POST JSON {gameId, location,
+// date, time, status, note}) and removes (DELETE ?gameid=) overrides. A PUT
+// replaces the game's whole override.
+func (s *Server) adminOverridesHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	if !requireAdmin(w, r) || !requireSameOrigin(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.overrides.list())
+
+	case http.MethodPut, http.MethodPost:
+		var o gameOverride
+		if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Detail: "Invalid JSON body"})
+			return
+		}
+		if problem := o.normalize(); problem != "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_parameters", Detail: problem})
+			return
+		}
+		o.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+		_, err := s.store.Exec(`INSERT INTO game_overrides (game_id, location, date, time, status, note, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (game_id) DO UPDATE SET location = excluded.location, date = excluded.date, time = excluded.time,
+				status = excluded.status, note = excluded.note, updated_at = excluded.updated_at`,
+			o.GameID, o.Location, o.Date, o.Time, o.Status, o.Note, o.UpdatedAt.Unix())
+		if !s.overridesChanged(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusOK, o)
+
+	case http.MethodDelete:
+		gameID := strings.TrimSpace(r.URL.Query().Get("gameid"))
+		if gameID == "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "gameid is required"})
+			return
+		}
+		res, err := s.store.Exec(`DELETE FROM game_overrides WHERE game_id = ?`, gameID)
+		if err == nil {
+			if n, _ := res.RowsAffected(); n == 0 {
+				writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "No override for game " + gameID})
+				return
+			}
+		}
+		if !s.overridesChanged(w, err) {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{
+			Error:  "method_not_allowed",
+			Detail: "Use GET, PUT, POST or DELETE",
+		})
+	}
+}
+
+// overridesChanged reloads the index after a write, reporting any failure.
+func (s *Server) overridesChanged(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		err = s.reloadOverrides()
+	}
+	if err != nil {
+		log.Printf("overrides: %v", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+/* ---------- PDF output ---------- */
+
+// A minimal PDF 1.4 writer using the standard Helvetica fonts, which every
+// viewer provides, so nothing needs embedding.
+
+const (
+	pdfPageW   = 612 // US Letter, points
+	pdfPageH   = 792
+	pdfMargin  = 54
+	pdfLeading = 14
+)
+
+type pdfWriter struct {
+	pages []string
+	cur   strings.Builder
+	y     float64
+}
+
+func newPDFWriter() *pdfWriter {
+	p := &pdfWriter{}
+	p.y = pdfPageH - pdfMargin
+	return p
+}
+
+// text writes one line at the current position using font F1 (regular) or
+// F2 (bold), starting a new page when the current one is full.
+func (p *pdfWriter) text(font string, size float64, indent float64, s string) {
+	if p.y < pdfMargin+size {
+		p.newPage()
+	}
+	fmt.Fprintf(&p.cur, "BT /%s %g Tf %g %g Td (%s) Tj ET\n", font, size, pdfMargin+indent, p.y, pdfEscape(s))
+	p.y -= pdfLeading * size / 10
+}
+
+func (p *pdfWriter) gap(points float64) { p.y -= points }
+
+func (p *pdfWriter) newPage() {
+	p.pages = append(p.pages, p.cur.String())
+	p.cur.Reset()
+	p.y = pdfPageH - pdfMargin
+}
+
+func (p *pdfWriter) bytes() []byte {
+	if p.cur.Len() > 0 || len(p.pages) == 0 {
+		p.newPage()
+	}
+	var buf bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// Objects 1-4 are fixed; each page then takes a page and a content object.
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range p.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageW, pdfPageH, 6+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfEscape escapes string delimiters and maps text to Latin-1, which is
+// close enough to WinAnsiEncoding for team and venue names.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '–' || r == '—':
+			b.WriteByte('-')
+		case r == '‘' || r == '’':
+			b.WriteByte('\'')
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteRune(r)
+		case r < 0x100:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func writePDF(w http.ResponseWriter, games []Game) {
+	p := newPDFWriter()
+	p.text("F2", 16, 0, "Game Schedule")
+	groups := groupByDateAndField(games)
+	if len(groups) == 0 {
+		p.gap(8)
+		p.text("F1", 11, 0, "No games scheduled.")
+	}
+	for _, dg := range groups {
+		p.gap(10)
+		p.text("F2", 13, 0, dg.Date.Format("Monday, January 2, 2006"))
+		for _, fg := range dg.Fields {
+			p.gap(4)
+			p.text("F2", 11, 12, fg.Field)
+			for _, g := range fg.Games {
+				line := fmt.Sprintf("%-8s  %s vs %s", g.Time, g.HomeTeam, g.AwayTeam)
+				if g.Division != "" {
+					line += "  (" + g.Division + ")"
+				}
+				p.text("F1", 10, 24, line)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="schedule.pdf"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(p.bytes())
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+/* ---------- Field permits ---------- */
+
+// fieldPermit is a field the club holds a permit for and when it may use it.
+type fieldPermit struct {
+	Field string   // a field, "Golden Eagle Regional Park - Field 3", or a whole complex
+	Days  []string // "sat", "sun" or 2006-01-02 dates; empty for every day
+	From  time.Duration
+	To    time.Duration // since midnight; From == To == 0 for all day
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseFieldPermits reads FIELD_PERMITS, ";"-separated entries of the form
+// field[@days][ hh:mm-hh:mm], days being comma-separated weekdays or dates,
+// e.g. "Golden Eagle - Field 1@Sat,Sun 08:00-18:00;Golden Eagle - Field 2@2025-10-11".
+// A field may have several entries for several windows.
+func parseFieldPermits(s string) []fieldPermit {
+	var out []fieldPermit
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		p, ok := parseFieldPermit(part)
+		if !ok {
+			log.Printf("ignoring malformed FIELD_PERMITS entry %q (want Field@Sat,Sun 08:00-18:00)", part)
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func parseFieldPermit(s string) (fieldPermit, bool) {
+	var p fieldPermit
+	field, rest, hasDays := strings.Cut(s, "@")
+	if !hasDays {
+		// Without days a trailing window belongs to the field.
+		if i := strings.LastIndex(s, " "); i > 0 && strings.Contains(s[i:], ":") && strings.Contains(s[i:], "-") {
+			field, rest = s[:i], s[i:]
+		} else {
+			field, rest = s, ""
+		}
+	}
+	p.Field = strings.TrimSpace(field)
+	if p.Field == "" {
+		return p, false
+	}
+	f := strings.Fields(rest)
+	if hasDays && len(f) > 0 {
+		for _, d := range strings.Split(f[0], ",") {
+			d = strings.ToLower(strings.TrimSpace(d))
+			if _, err := time.Parse("2006-01-02", d); err != nil {
+				if len(d) > 3 {
+					d = d[:3] // "saturday"
+				}
+				if _, ok := weekdayNames[d]; !ok {
+					return p, false
+				}
+			}
+			p.Days = append(p.Days, d)
+		}
+		f = f[1:]
+	}
+	switch len(f) {
+	case 0:
+	case 1:
+		from, to, ok := strings.Cut(f[0], "-")
+		a, err1 := time.Parse("15:04", from)
+		b, err2 := time.Parse("15:04", to)
+		if !ok || err1 != nil || err2 != nil || !b.After(a) {
+			return p, false
+		}
+		p.From = time.Duration(a.Hour())*time.Hour + time.Duration(a.Minute())*time.Minute
+		p.To = time.Duration(b.Hour())*time.Hour + time.Duration(b.Minute())*time.Minute
+	default:
+		return p, false
+	}
+	return p, true
+}
+
+// covers reports whether the permit is for location, a field of it or the
+// complex it is on.
+func (p fieldPermit) covers(location string) bool {
+	return strings.EqualFold(p.Field, strings.TrimSpace(location)) || strings.EqualFold(p.Field, venueName(location))
+}
+
+// allows reports whether the permit lets a game run from start to end.
+func (p fieldPermit) allows(start, end time.Time) bool {
+	if len(p.Days) > 0 {
+		date, day := start.Format("2006-01-02"), strings.ToLower(start.Weekday().String()[:3])
+		if !containsString(p.Days, date) && !containsString(p.Days, day) {
+			return false
+		}
+	}
+	if p.From == 0 && p.To == 0 {
+		return true
+	}
+	midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	return !start.Before(midnight.Add(p.From)) && !end.After(midnight.Add(p.To))
+}
+
+func init() {
+	registerFlagCheck(flagUnpermittedFields)
+}
+
+// flagUnpermittedFields checks the club's home games against
+// appConfig.FieldPermits: a field with no permit is flagged
+// "unpermitted_field", and one whose permits don't cover the game's day and
+// time "outside_permit". Nothing is flagged until permits are configured.
+func flagUnpermittedFields(games []Game) {
+	if len(appConfig.FieldPermits) == 0 {
+		return
+	}
+	for i := range games {
+		g := &games[i]
+		side := clubSide(*g)
+		if side == "" || side != g.HomeTeam || g.Location == "" || g.Location == "TBD" || g.Status == "cancelled" {
+			continue
+		}
+		var held []fieldPermit
+		for _, p := range appConfig.FieldPermits {
+			if p.covers(g.Location) {
+				held = append(held, p)
+			}
+		}
+		if len(held) == 0 {
+			g.flag("unpermitted_field", fmt.Sprintf("home game on %s, which the club has no permit for", g.Location))
+			continue
+		}
+		start, ok := gameKickoff(*g)
+		if !ok {
+			continue
+		}
+		end := start.Add(gameDuration(*g, start))
+		allowed := false
+		for _, p := range held {
+			if p.allows(start, end) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			g.flag("outside_permit", fmt.Sprintf("home game on %s at %s is outside the club's permitted times for that field", g.Location, start.Format("Mon 3:04PM")))
+		}
+	}
+}
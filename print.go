@@ -0,0 +1,59 @@
+package main
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+)
+
+/* ---------- Printable HTML ---------- */
+
+var printTmpl = template.Must(template.New("print").Parse(`<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8">
+<title>Game Schedule</title>
+<style>
+@page{margin:12mm}
+body{font:11pt/1.35 Helvetica,Arial,sans-serif;color:#000;margin:0}
+h1{font-size:16pt;margin:0 0 8pt}
+h2{font-size:13pt;margin:14pt 0 4pt;border-bottom:1.5pt solid #000}
+h3{font-size:11pt;margin:8pt 0 2pt}
+section{break-inside:avoid}
+section+section{break-before:page}
+table{width:100%;border-collapse:collapse;margin-bottom:4pt}
+td{padding:2pt 4pt;border-bottom:.5pt solid #999;vertical-align:top}
+td.t{width:6em;white-space:nowrap;font-weight:bold}
+td.d{width:28%;color:#333}
+</style></head>
+<body>
+<h1>Game Schedule</h1>
+{{range .}}<section>
+<h2>{{.Date.Format "Monday, January 2, 2006"}}</h2>
+{{range .Fields}}<h3>{{.Field}}</h3>
+<table>{{range .Games}}
+<tr><td class="t">{{.Time}}</td><td>{{.HomeTeam}} vs {{.AwayTeam}}</td><td class="d">{{.Division}}</td></tr>{{end}}
+</table>
+{{end}}</section>
+{{else}}<p>No games scheduled.</p>
+{{end}}</body></html>
+`))
+
+// printHandler serves /schedule/print with the same parameters as /schedule.
+func (s *Server) printHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	req, err := scheduleReqFromQuery(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	req.Format = "print"
+	s.handleSchedule(w, r, req)
+}
+
+func writePrintHTML(w http.ResponseWriter, games []Game) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := printTmpl.Execute(w, groupByDateAndField(games)); err != nil {
+		log.Printf("print render: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+/* ---------- Panic recovery ---------- */
+
+func init() {
+	describeMetric("gotsport_panics_total", kindCounter, "Panics recovered, by where they happened (http, scrape or job).")
+}
+
+// problemDetails is an RFC 9457 application/problem+json body. Error
+// repeats the code ErrorResponse would carry, for clients that read that.
+type problemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Error    string `json:"error"`
+}
+
+// logPanic logs a recovered panic with its stack and counts it.
+func logPanic(where, what string, p any) {
+	incCounter("gotsport_panics_total", "where", where)
+	log.Printf("panic in %s: %v\n%s", what, p, debug.Stack())
+}
+
+// recoverPanics turns a panicking handler into a 500 problem+json response,
+// so one bad page cannot take the whole server down. The stack is logged
+// with the request that caused it. http.ErrAbortHandler is passed on, as
+// net/http uses it to abort a response on purpose.
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pw := &panicWriter{ResponseWriter: w}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(p)
+			}
+			logPanic("http", fmt.Sprintf("%s %s client=%s ua=%q", r.Method, loggedURL(r.URL), clientID(r), r.UserAgent()), p)
+			if pw.wroteHeader {
+				return // too late for an error response; the client sees a truncated body
+			}
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(problemDetails{
+				Type:     "about:blank",
+				Title:    http.StatusText(http.StatusInternalServerError),
+				Status:   http.StatusInternalServerError,
+				Detail:   "The server hit an unexpected error handling this request",
+				Instance: r.URL.Path,
+				Error:    "internal",
+			})
+		}()
+		next.ServeHTTP(pw, r)
+	})
+}
+
+// panicWriter notes whether the response has started, and passes Flush on
+// so streaming handlers keep working behind it.
+type panicWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (p *panicWriter) WriteHeader(code int) {
+	p.wroteHeader = true
+	p.ResponseWriter.WriteHeader(code)
+}
+
+func (p *panicWriter) Write(b []byte) (int, error) {
+	p.wroteHeader = true
+	return p.ResponseWriter.Write(b)
+}
+
+func (p *panicWriter) Flush() {
+	if f, ok := p.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (p *panicWriter) Unwrap() http.ResponseWriter { return p.ResponseWriter }
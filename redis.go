@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ---------- Redis cache ---------- */
+
+const (
+	// redisKeyPrefix namespaces the service's keys in a shared Redis.
+	redisKeyPrefix = "gotsport:schedule:"
+	// redisStaleFor is how long past its TTL an entry is kept, so every
+	// instance can still serve it while GotSport is down.
+	redisStaleFor = 24 * time.Hour
+	redisTimeout  = 2 * time.Second
+)
+
+func init() {
+	describeMetric("gotsport_redis_errors_total", kindCounter, "Redis cache commands that failed and were treated as misses.")
+}
+
+// redisEntry is a cacheEntry as stored in Redis.
+type redisEntry struct {
+	Games     []Game    `json:"games"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// redisCache is a Cache shared by every instance pointed at one Redis
+// (REDIS_URL). A failing Redis reads as a miss, so requests scrape rather
+// than fail.
+type redisCache struct {
+	ttl  time.Duration
+	addr string
+	tls  bool
+	auth []string // AUTH arguments, when the URL has credentials
+	db   int
+
+	mu   sync.Mutex // one command at a time on conn
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// newRedisCache connects to the Redis at rawURL, redis://[user:pass@]host[:port][/db]
+// or rediss:// for TLS.
+func newRedisCache(rawURL string, ttl time.Duration) (*redisCache, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+		return nil, fmt.Errorf("REDIS_URL must look like redis://host:6379/0")
+	}
+	c := &redisCache{ttl: ttl, addr: u.Host, tls: u.Scheme == "rediss"}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if pass, ok := u.User.Password(); ok {
+		c.auth = []string{pass}
+		if user := u.User.Username(); user != "" {
+			c.auth = []string{user, pass}
+		}
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("REDIS_URL database %q is not a number", db)
+		}
+	}
+	if _, err := c.do("PING"); err != nil {
+		return nil, fmt.Errorf("redis %s: %v", c.addr, err)
+	}
+	return c, nil
+}
+
+func (c *redisCache) maxAge() time.Duration { return c.ttl }
+
+func (c *redisCache) get(key string, maxAge time.Duration) ([]Game, time.Duration, bool) {
+	games, fetchedAt, ok := c.peek(key)
+	if !ok {
+		return nil, 0, false
+	}
+	age := time.Since(fetchedAt)
+	if age > maxAge {
+		return nil, age, false
+	}
+	return games, age, true
+}
+
+func (c *redisCache) peek(key string) ([]Game, time.Time, bool) {
+	reply, err := c.do("GET", redisKeyPrefix+key)
+	if err != nil {
+		c.failed("GET", err)
+		return nil, time.Time{}, false
+	}
+	data, _ := reply.([]byte)
+	if data == nil {
+		return nil, time.Time{}, false
+	}
+	var e redisEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		c.failed("GET", err)
+		return nil, time.Time{}, false
+	}
+	return e.Games, e.FetchedAt, true
+}
+
+func (c *redisCache) set(key string, games []Game) {
+	data, err := json.Marshal(redisEntry{Games: games, FetchedAt: time.Now()})
+	if err != nil {
+		c.failed("SET", err)
+		return
+	}
+	px := strconv.FormatInt((c.ttl + redisStaleFor).Milliseconds(), 10)
+	if _, err := c.do("SET", redisKeyPrefix+key, string(data), "PX", px); err != nil {
+		c.failed("SET", err)
+	}
+}
+
+func (c *redisCache) failed(cmd string, err error) {
+	log.Printf("redis cache %s: %v", cmd, err)
+	incCounter("gotsport_redis_errors_total")
+}
+
+// do runs one command, connecting first if need be. Any error drops the
+// connection, so the next command starts on a fresh one.
+func (c *redisCache) do(args ...string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := c.roundTrip(args)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		c.conn.Close()
+		c.conn = nil
+	}
+	return reply, err
+}
+
+func (c *redisCache) connect() error {
+	dialer := &net.Dialer{Timeout: redisTimeout}
+	var conn net.Conn
+	var err error
+	if c.tls {
+		host, _, _ := net.SplitHostPort(c.addr)
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.addr, &tls.Config{ServerName: host})
+	} else {
+		conn, err = dialer.Dial("tcp", c.addr)
+	}
+	if err != nil {
+		return err
+	}
+	c.conn, c.rd = conn, bufio.NewReader(conn)
+	setup := [][]string{}
+	if c.auth != nil {
+		setup = append(setup, append([]string{"AUTH"}, c.auth...))
+	}
+	if c.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	for _, args := range setup {
+		if _, err := c.roundTrip(args); err != nil {
+			conn.Close()
+			c.conn = nil
+			return fmt.Errorf("%s: %v", args[0], err)
+		}
+	}
+	return nil
+}
+
+// roundTrip writes args as a RESP array and reads the reply.
+func (c *redisCache) roundTrip(args []string) (any, error) {
+	c.conn.SetDeadline(time.Now().Add(redisTimeout))
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return readRESP(c.rd)
+}
+
+// redisError is an error reply; the connection stays usable after one.
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+// readRESP reads one reply: a status or integer as a string, a bulk string
+// as []byte (nil for a missing key) or an array as []any.
+func readRESP(rd *bufio.Reader) (any, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty reply")
+	}
+	switch body := line[1:]; line[0] {
+	case '+', ':':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		out := make([]any, n)
+		for i := range out {
+			if out[i], err = readRESP(rd); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unexpected reply %q", line)
+}
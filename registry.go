@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/* ---------- Team registry ---------- */
+
+// teamName is one name a team has been listed under.
+type teamName struct {
+	Name      string    `json:"name"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// registeredTeam is a GotSport team and every name it has been seen under,
+// most recent first.
+type registeredTeam struct {
+	ID    string     `json:"id"`
+	Name  string     `json:"name"` // the latest
+	Names []teamName `json:"names"`
+}
+
+// recordTeamNames notes the name each linked team in games is listed under,
+// so a team keeps one identity however it is renamed.
+func (s *Server) recordTeamNames(games []Game) {
+	if s.store == nil {
+		return // one-shot CLI runs keep no history
+	}
+	now := time.Now().UnixMilli()
+	seen := map[string]bool{}
+	for _, g := range games {
+		for _, side := range [][2]string{{g.HomeTeamID, g.HomeTeam}, {g.AwayTeamID, g.AwayTeam}} {
+			id, name := side[0], side[1]
+			if id == "" || name == "" || seen[id+"|"+name] {
+				continue
+			}
+			seen[id+"|"+name] = true
+			if _, err := s.store.Exec(`INSERT INTO team_names (team_id, name, first_seen, last_seen) VALUES (?, ?, ?, ?)
+				ON CONFLICT (team_id, name) DO UPDATE SET last_seen = excluded.last_seen`, id, name, now, now); err != nil {
+				log.Printf("team registry: %v", err)
+				return
+			}
+		}
+	}
+}
+
+// registeredTeams lists the teams recorded, or just id when it is set.
+func (s *Server) registeredTeams(ctx context.Context, id string) ([]registeredTeam, error) {
+	rows, err := s.store.QueryContext(ctx, `SELECT team_id, name, first_seen, last_seen FROM team_names
+		WHERE (? = '' OR team_id = ?) ORDER BY team_id, last_seen DESC, name`, id, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	teams := []registeredTeam{}
+	for rows.Next() {
+		var tid string
+		var n teamName
+		var first, last int64
+		if err := rows.Scan(&tid, &n.Name, &first, &last); err != nil {
+			return nil, err
+		}
+		n.FirstSeen, n.LastSeen = time.UnixMilli(first).UTC(), time.UnixMilli(last).UTC()
+		if len(teams) == 0 || teams[len(teams)-1].ID != tid {
+			teams = append(teams, registeredTeam{ID: tid, Name: n.Name})
+		}
+		t := &teams[len(teams)-1]
+		t.Names = append(t.Names, n)
+	}
+	return teams, rows.Err()
+}
+
+// teamRegistryHandler serves /teams/registry[?id=]: every GotSport team ID
+// seen in a scraped schedule with the names it has been listed under.
+func (s *Server) teamRegistryHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	id := strings.TrimSpace(r.URL.Query().Get("id"))
+	if id != "" && !digitsOnly.MatchString(id) {
+		writeJSON(w, http.StatusBadRequest, errorResponse(badParamsf("id must be a numeric GotSport team ID")))
+		return
+	}
+	if s.store == nil {
+		writeJSON(w, http.StatusOK, []registeredTeam{})
+		return
+	}
+	teams, err := s.registeredTeams(r.Context(), id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return
+	}
+	if id != "" && len(teams) == 0 {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "No team with ID " + id})
+		return
+	}
+	writeJSON(w, http.StatusOK, teams)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* ---------- Game-day reminders ---------- */
+
+const jobKindReminders = "reminders"
+
+const defaultReminderAt = 7 * time.Hour
+
+func reminderAtFromEnv() time.Duration {
+	v := strings.TrimSpace(os.Getenv("REMINDER_AT"))
+	switch v {
+	case "":
+		return defaultReminderAt
+	case "off":
+		return 0
+	}
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		log.Printf("invalid REMINDER_AT=%q (want 07:00 or off), using 07:00", v)
+		return defaultReminderAt
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
+
+func reminderGroupFromEnv() string {
+	switch v := strings.ToLower(stringFromEnv("REMINDER_GROUP", "team")); v {
+	case "team", "field":
+		return v
+	default:
+		log.Printf("invalid REMINDER_GROUP=%q (want team or field), using team", v)
+		return "team"
+	}
+}
+
+func init() {
+	registerJobHandler(jobKindReminders, func(ctx context.Context, _ json.RawMessage) error {
+		now := time.Now()
+		// A minute on, so the next pass is tomorrow's even when this one
+		// started right on time.
+		defer scheduleReminders(nextReminder(now.Add(time.Minute)))
+		day := now.In(eventLocation()).Format("2006-01-02")
+		for _, ref := range appConfig.Events {
+			games, ok := defaultServer.cachedSchedule(ctx, ref.EventID, ref.ClubID)
+			if !ok {
+				log.Printf("reminders: no cached schedule for %s/%s", ref.EventID, ref.ClubID)
+				continue
+			}
+			notifications.notifyReminders(ref.EventID, ref.ClubID, day, gamesOn(games, day), appConfig.ReminderGroup)
+		}
+		return nil
+	})
+}
+
+// nextReminder is the first reminder time at or after t. It is built from
+// the wall clock, so it stays at the same local time across DST changes.
+func nextReminder(t time.Time) time.Time {
+	t = t.In(eventLocation())
+	hour, minute := int(appConfig.ReminderAt/time.Hour), int(appConfig.ReminderAt%time.Hour/time.Minute)
+	at := time.Date(t.Year(), t.Month(), t.Day(), hour, minute, 0, 0, t.Location())
+	if at.Before(t) {
+		at = time.Date(t.Year(), t.Month(), t.Day()+1, hour, minute, 0, 0, t.Location())
+	}
+	return at
+}
+
+// scheduleReminders queues the reminder pass at at. Like retention, each
+// pass queues the next, and the dedupe key is per day.
+func scheduleReminders(at time.Time) {
+	key := jobKindReminders + "@" + at.In(eventLocation()).Format("2006-01-02")
+	if err := enqueueJob(jobKindReminders, struct{}{}, jobOptions{DedupeKey: key, RunAt: at, MaxAttempts: 1}); err != nil {
+		log.Printf("reminders: %v", err)
+	}
+}
+
+// cachedSchedule is the club's schedule as last scraped, from the cache or
+// else the datastore, as /schedule would present it. It never scrapes.
+func (s *Server) cachedSchedule(ctx context.Context, eventID, clubID string) ([]Game, bool) {
+	games, _, ok := s.cache.peek(cacheKey(eventID, clubID))
+	if !ok {
+		games, _, ok = s.persistedGames(ctx, eventID, clubID, scheduleFilter{})
+	}
+	if !ok {
+		return nil, false
+	}
+	return s.presentGames(withManualGames(games, s.manualGamesFor(eventID, clubID, scheduleFilter{}))), true
+}
+
+// gamesOn returns the games on day (2006-01-02), in kickoff order.
+func gamesOn(games []Game, day string) []Game {
+	var out []Game
+	for _, g := range games {
+		if g.Date == day {
+			out = append(out, g)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, _ := gameKickoff(out[i])
+		b, _ := gameKickoff(out[j])
+		return a.Before(b)
+	})
+	return out
+}
+
+// reminderGroups splits a day's games by club team or by field, in order of
+// each group's first game. Games with no club side group under their home
+// team.
+func reminderGroups(games []Game, by string) ([]string, map[string][]Game) {
+	var names []string
+	groups := map[string][]Game{}
+	for _, g := range games {
+		name := g.Location
+		if by != "field" {
+			name = firstNonEmpty(clubSide(g), g.HomeTeam)
+		}
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], g)
+	}
+	return names, groups
+}
+
+// notifyReminders queues one reminder per club team or field playing on
+// day to every channel a reminder route sends that group's games to.
+func (d *dispatcher) notifyReminders(eventID, clubID, day string, games []Game, by string) {
+	if d == nil || len(games) == 0 {
+		return
+	}
+	at := time.Now()
+	for _, c := range d.channels {
+		var routed []Game
+		for _, g := range games {
+			if d.wants(c.Name, messageReminder, eventID, g) {
+				routed = append(routed, g)
+			}
+		}
+		names, groups := reminderGroups(routed, by)
+		for _, name := range names {
+			d.send(c, notification{Kind: messageReminder, EventID: eventID, ClubID: clubID, At: at, Date: day, Group: name, Games: groups[name]})
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+/* ---------- Headless rendering ---------- */
+
+// chromeCandidates are the browser binaries looked for on PATH when
+// CHROME_PATH is unset.
+var chromeCandidates = []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable"}
+
+// renderMinBytes is the size from which a page that yields no fixtures is
+// taken to hold a schedule its scripts draw.
+const renderMinBytes = 20 << 10
+
+var (
+	// scheduleContainerPattern finds an empty element named for the
+	// schedule, which the page's scripts fill in.
+	scheduleContainerPattern = regexp.MustCompile(`(?is)<(div|section|tbody|ul|main)\b[^>]*\b(?:id|class)\s*=\s*["'][^"']*(?:schedule|matches|games|fixtures)[^"']*["'][^>]*>\s*</(?:div|section|tbody|ul|main)>`)
+	scriptBlockPattern       = regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script>|<style\b[^>]*>.*?</style>`)
+	bodyPattern              = regexp.MustCompile(`(?is)<body\b[^>]*>(.*)</body>`)
+)
+
+// renderMemoryPoll is how often a render's memory is checked against
+// RENDER_MAX_MEMORY.
+const renderMemoryPoll = 500 * time.Millisecond
+
+func init() {
+	describeMetric("gotsport_render_decisions_total", kindCounter, "Pages with no games checked for headless rendering, by decision (render or skip).")
+	describeMetric("gotsport_renders_total", kindCounter, "Headless-browser page renders, by outcome (ok, failed, timeout or memory).")
+}
+
+// renderPoolStats is the headless renderer's slice of /stats.
+type renderPoolStats struct {
+	Size       int     `json:"size"` // MAX_RENDERS
+	Active     int     `json:"active"`
+	Waiting    int64   `json:"waiting"`
+	Browsers   int     `json:"browsers"` // running, busy or idle
+	Recycled   int     `json:"recycled"` // closed after RENDER_RECYCLE renders or a render gone wrong
+	Renders    int     `json:"renders"`
+	Failed     int     `json:"failed"`
+	TimedOut   int     `json:"timedOut"`
+	OverMemory int     `json:"overMemory"` // killed for passing RENDER_MAX_MEMORY
+	AvgMs      float64 `json:"avgMs"`
+	PeakMB     int64   `json:"peakMB"` // the most one browser's processes used during a render
+}
+
+var (
+	renderWaiters atomic.Int64 // renders waiting for a renderLimit slot
+	renderPoolMu  sync.Mutex
+	renderPool    renderPoolStats
+	renderTotalMs float64
+	renderIdle    []*renderBrowser // browsers between renders
+)
+
+// noteRenderDone adds one finished render to the pool stats.
+func noteRenderDone(outcome string, took time.Duration, peak int64) {
+	incCounter("gotsport_renders_total", "outcome", outcome)
+	renderPoolMu.Lock()
+	defer renderPoolMu.Unlock()
+	renderPool.Renders++
+	switch outcome {
+	case "failed":
+		renderPool.Failed++
+	case "timeout":
+		renderPool.TimedOut++
+	case "memory":
+		renderPool.OverMemory++
+	}
+	renderTotalMs += float64(took.Milliseconds())
+	renderPool.AvgMs = renderTotalMs / float64(renderPool.Renders)
+	renderPool.PeakMB = max(renderPool.PeakMB, peak>>20)
+}
+
+func renderPoolSnapshot() renderPoolStats {
+	renderPoolMu.Lock()
+	st := renderPool
+	renderPoolMu.Unlock()
+	st.Size, st.Active, st.Waiting = cap(renderLimit), renderLimit.inUse(), renderWaiters.Load()
+	return st
+}
+
+// chromeBinary is CHROME_PATH or the first candidate found on PATH.
+func chromeBinary() (string, error) {
+	if appConfig.ChromePath != "" {
+		return appConfig.ChromePath, nil
+	}
+	for _, name := range chromeCandidates {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no headless browser found; install Chromium or set CHROME_PATH")
+}
+
+// renderBrowser is a headless Chrome driven over the DevTools protocol.
+type renderBrowser struct {
+	ctx     context.Context // the browser's chromedp context; tabs open under it
+	cancel  context.CancelFunc
+	pid     int // leads the browser's process group
+	renders int
+}
+
+// startRenderBrowser launches a browser with a throwaway profile, which
+// chromedp removes when it exits. Chrome's sandbox stays on unless
+// RENDER_NO_SANDBOX is set.
+func startRenderBrowser() (*renderBrowser, error) {
+	chrome, err := chromeBinary()
+	if err != nil {
+		return nil, err
+	}
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.ExecPath(chrome),
+		chromedp.Flag("headless", "new"),
+		chromedp.DisableGPU,
+		chromedp.UserAgent("Mozilla/5.0 (compatible; RenoApexScraper/1.0)"),
+		// Set either way: chromedp drops the sandbox for root unless told.
+		chromedp.Flag("no-sandbox", appConfig.RenderNoSandbox),
+		chromedp.ModifyCmdFunc(isolateRender),
+	)
+	actx, acancel := chromedp.NewExecAllocator(context.Background(), opts...)
+	bctx, bcancel := chromedp.NewContext(actx)
+	b := &renderBrowser{ctx: bctx, cancel: func() { bcancel(); acancel() }}
+	if err := chromedp.Run(bctx); err != nil {
+		b.cancel()
+		return nil, fmt.Errorf("starting browser: %s", truncateRunes(strings.TrimSpace(err.Error()), 200))
+	}
+	b.pid = chromedp.FromContext(bctx).Browser.Process().Pid
+	return b, nil
+}
+
+// close kills the browser and every process it started.
+func (b *renderBrowser) close() { b.cancel() }
+
+// takeRenderBrowser returns an idle browser from the pool, or starts one.
+// Callers hold a renderLimit slot, so there are never more than
+// MAX_RENDERS browsers.
+func takeRenderBrowser() (*renderBrowser, error) {
+	renderPoolMu.Lock()
+	for len(renderIdle) > 0 {
+		b := renderIdle[len(renderIdle)-1]
+		renderIdle = renderIdle[:len(renderIdle)-1]
+		if b.ctx.Err() == nil {
+			renderPoolMu.Unlock()
+			return b, nil
+		}
+		// It died while idle.
+		renderPool.Browsers--
+		renderPool.Recycled++
+	}
+	renderPoolMu.Unlock()
+
+	b, err := startRenderBrowser()
+	if err != nil {
+		return nil, err
+	}
+	renderPoolMu.Lock()
+	renderPool.Browsers++
+	renderPoolMu.Unlock()
+	return b, nil
+}
+
+// putRenderBrowser returns b to the pool after a render, or closes it when
+// the render went wrong or b has served RENDER_RECYCLE renders.
+func putRenderBrowser(b *renderBrowser, healthy bool) {
+	b.renders++
+	keep := healthy && b.ctx.Err() == nil &&
+		(appConfig.RenderRecycle <= 0 || b.renders < appConfig.RenderRecycle)
+	renderPoolMu.Lock()
+	if keep {
+		renderIdle = append(renderIdle, b)
+	} else {
+		renderPool.Browsers--
+		renderPool.Recycled++
+	}
+	renderPoolMu.Unlock()
+	if !keep {
+		b.close()
+	}
+}
+
+// render loads url in a new tab, in a browser context of its own so no
+// cookies or storage carry over, and returns the DOM once the page has
+// used budget of virtual time. Virtual time stands still while requests
+// are pending, so the page's XHRs land before the DOM is read.
+func (b *renderBrowser) render(ctx context.Context, url string, budget time.Duration) (string, error) {
+	tab, closeTab := chromedp.NewContext(b.ctx, chromedp.WithNewBrowserContext())
+	defer closeTab()
+	tab, cancel := context.WithCancel(tab)
+	defer cancel()
+	defer context.AfterFunc(ctx, cancel)()
+
+	settled := make(chan struct{})
+	var once sync.Once
+	chromedp.ListenTarget(tab, func(ev interface{}) {
+		if _, ok := ev.(*emulation.EventVirtualTimeBudgetExpired); ok {
+			once.Do(func() { close(settled) })
+		}
+	})
+	var html string
+	err := chromedp.Run(tab,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			_, err := emulation.SetVirtualTimePolicy(emulation.VirtualTimePolicyPauseIfNetworkFetchesPending).
+				WithBudget(float64(budget.Milliseconds())).Do(ctx)
+			if err != nil {
+				return err
+			}
+			_, _, errText, err := page.Navigate(url).Do(ctx)
+			if err == nil && errText != "" {
+				err = errors.New(errText)
+			}
+			return err
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			select {
+			case <-settled:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}),
+		chromedp.Evaluate(`document.documentElement.outerHTML`, &html),
+	)
+	return html, err
+}
+
+// renderPage loads url in headless Chrome, lets its scripts run, and
+// returns the resulting DOM as HTML. It waits for a slot in renderLimit and
+// respects the upstream circuit breaker like a plain fetch. Renders share a
+// pool of up to MAX_RENDERS browsers, one render per browser at a time and
+// each in a browser context of its own, so nothing leaks from one page to
+// the next. A browser is replaced after RENDER_RECYCLE renders; it and
+// every process it started are killed once a render passes RENDER_TIMEOUT
+// or RENDER_MAX_MEMORY.
+func renderPage(ctx context.Context, url string) (string, error) {
+	if err := checkCircuit("gotsport"); err != nil {
+		return "", err
+	}
+	if _, err := chromeBinary(); err != nil {
+		return "", err
+	}
+	renderWaiters.Add(1)
+	err := renderLimit.acquire(ctx)
+	renderWaiters.Add(-1)
+	if err != nil {
+		return "", timeoutAware(err, fmt.Errorf("waiting for render slot: %v", err))
+	}
+	defer renderLimit.release()
+
+	start := time.Now()
+	b, err := takeRenderBrowser()
+	if err != nil {
+		noteRenderDone("failed", 0, 0)
+		return "", fmt.Errorf("render failed: %v", err)
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, appConfig.RenderTimeout)
+	defer cancel()
+	var overMemory atomic.Bool
+	var peak atomic.Int64
+	done := make(chan struct{})
+	go func() {
+		tick := time.NewTicker(renderMemoryPoll)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+			}
+			rss := renderMemory(b.pid)
+			if rss > peak.Load() {
+				peak.Store(rss)
+			}
+			if limit := appConfig.RenderMaxMemory; limit > 0 && rss > limit {
+				overMemory.Store(true)
+				cancel()
+				return
+			}
+		}
+	}()
+	// Leave a third of the timeout for loading and reading the DOM.
+	html, err := b.render(ctx, url, appConfig.RenderTimeout*2/3)
+	close(done)
+	took := time.Since(start)
+	// A browser whose render ran out of time or memory may be wedged on the
+	// page; start the next render on a new one.
+	putRenderBrowser(b, !overMemory.Load() && (err == nil || ctx.Err() == nil || parent.Err() != nil))
+
+	switch {
+	case overMemory.Load():
+		noteRenderDone("memory", took, peak.Load())
+		log.Printf("render %s: killed at %d MB", url, peak.Load()>>20)
+		return "", fmt.Errorf("render used over %d MB and was stopped", appConfig.RenderMaxMemory>>20)
+	case err != nil && ctx.Err() != nil && parent.Err() == nil:
+		noteRenderDone("timeout", took, peak.Load())
+		return "", timeoutAware(ctx.Err(), fmt.Errorf("render timed out after %s", appConfig.RenderTimeout))
+	case err != nil && ctx.Err() != nil:
+		noteRenderDone("failed", took, peak.Load())
+		return "", timeoutAware(ctx.Err(), fmt.Errorf("render cancelled: %v", ctx.Err()))
+	case err != nil:
+		noteRenderDone("failed", took, peak.Load())
+		return "", fmt.Errorf("render failed: %v", err)
+	}
+	noteRenderDone("ok", took, peak.Load())
+	return html, nil
+}
+
+// renderVerdict decides whether rendering a page that yielded no games
+// could help, and says why. Rendering only adds what the page's scripts
+// draw, so a page already listing fixtures (none of them the club's), or
+// one with tables and no sign of a script-built schedule, is left alone.
+func renderVerdict(html string, stats *parseStats) (bool, string) {
+	render, why := false, ""
+	body := html
+	if m := bodyPattern.FindStringSubmatch(html); m != nil {
+		body = m[1]
+	}
+	scripts := len(body)
+	body = scriptBlockPattern.ReplaceAllString(body, "")
+	scripts -= len(body)
+	text := cleanText(body)
+
+	switch {
+	case stats.CellRows > 0 || stats.JSONMatches > 0:
+		why = fmt.Sprintf("skipped: page lists %d fixtures, none of them the club's", stats.CellRows+stats.JSONMatches)
+	case scheduleContainerPattern.MatchString(body):
+		render, why = true, "rendered: empty schedule container"
+	case scripts > 0 && len(text) < 200 && scripts > len(text):
+		render, why = true, fmt.Sprintf("rendered: script-only body (%d bytes of script, %d of text)", scripts, len(text))
+	case !hasTables(html) && len(html) >= renderMinBytes:
+		render, why = true, fmt.Sprintf("rendered: %d-byte page with no tables yields no fixtures", len(html))
+	case hasTables(html):
+		why = "skipped: page has tables but no schedule rows"
+	default:
+		why = fmt.Sprintf("skipped: %d-byte page shows no sign of a script-built schedule", len(html))
+	}
+	decision := "skip"
+	if render {
+		decision = "render"
+	}
+	incCounter("gotsport_render_decisions_total", "decision", decision)
+	return render, why
+}
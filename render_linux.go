@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// isolateRender starts the browser in a process group of its own, so
+// cancelling the render kills its renderer and GPU helpers too, and has it
+// killed should this process die first.
+func isolateRender(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGKILL}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// renderMemory is the resident memory, in bytes, of every process in the
+// browser's process group.
+func renderMemory(pgid int) int64 {
+	stats, _ := filepath.Glob("/proc/[0-9]*/stat")
+	page := int64(os.Getpagesize())
+	var total int64
+	for _, path := range stats {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // the process has exited
+		}
+		// The command name may hold spaces; fields resume after its ")".
+		end := strings.LastIndexByte(string(data), ')')
+		if end < 0 {
+			continue
+		}
+		fields := strings.Fields(string(data[end+1:]))
+		// fields[2] is the process group and fields[21] the resident pages.
+		if len(fields) < 22 || fields[2] != strconv.Itoa(pgid) {
+			continue
+		}
+		if pages, err := strconv.ParseInt(fields[21], 10, 64); err == nil {
+			total += pages * page
+		}
+	}
+	return total
+}
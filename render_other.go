@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+import "os/exec"
+
+// isolateRender is a no-op where process groups aren't managed; the
+// browser alone is killed on cancellation.
+func isolateRender(cmd *exec.Cmd) {}
+
+// renderMemory reports 0 where /proc is unavailable, which leaves
+// RENDER_MAX_MEMORY unenforced.
+func renderMemory(pid int) int64 { return 0 }
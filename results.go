@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* ---------- Posted results ---------- */
+
+// resultNotifyWindow is how recent a game must be for its newly seen result
+// to be announced. Older ones are only recorded, so the first scrape of a
+// schedule doesn't announce a season's worth of scores.
+const resultNotifyWindow = 3 * 24 * time.Hour
+
+// postedResult is a final score on one of the club's fixtures.
+type postedResult struct {
+	GameID   string `json:"id"`
+	Date     string `json:"date"`
+	HomeTeam string `json:"homeTeam"`
+	AwayTeam string `json:"awayTeam"`
+	Result   string `json:"result"` // as the page prints it, e.g. "3 - 1"
+	Division string `json:"division"`
+}
+
+var scoreDash = regexp.MustCompile(`\s*-\s*`)
+
+// Score is the result written the usual way, "3–1".
+func (r postedResult) Score() string {
+	return scoreDash.ReplaceAllString(r.Result, "–")
+}
+
+func (r postedResult) game() Game {
+	return Game{ID: r.GameID, HomeTeam: r.HomeTeam, AwayTeam: r.AwayTeam, Date: r.Date, Division: r.Division}
+}
+
+// noteResult records f's result. Results between other clubs are kept too,
+// for scouting; only the club's are announced.
+func (s *parseStats) noteResult(f rawFixture) {
+	id := gameID(Game{HomeTeam: f.Home, AwayTeam: f.Away, Date: f.Date, Time: f.Time})
+	for _, r := range s.Results {
+		if r.GameID == id {
+			return // another strategy saw the same row
+		}
+	}
+	s.Results = append(s.Results, postedResult{GameID: id, Date: f.Date, HomeTeam: f.Home, AwayTeam: f.Away, Result: f.Result, Division: f.Division})
+}
+
+// recordResults stores the results a scrape found and notifies the club's
+// that were not seen before, for games within resultNotifyWindow.
+func (s *Server) recordResults(eventID, clubID string, results []postedResult) {
+	if s.store == nil {
+		return // one-shot CLI runs keep no history
+	}
+	now := time.Now()
+	cutoff := now.Add(-resultNotifyWindow).In(eventLocation()).Format("2006-01-02")
+	for _, r := range results {
+		data, err := json.Marshal(r)
+		if err != nil {
+			log.Printf("posted results: %v", err)
+			return
+		}
+		res, err := s.store.Exec(`INSERT INTO posted_results (game_id, event_id, club_id, result, posted_at, data)
+			VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (game_id, event_id, club_id) DO NOTHING`,
+			r.GameID, eventID, clubID, r.Result, now.UnixMilli(), string(data))
+		if err != nil {
+			log.Printf("posted results: %v", err)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 1 && r.Date >= cutoff && clubSide(r.game()) != "" {
+			notifications.notifyResult(eventID, clubID, r)
+		}
+	}
+}
+
+// notifyResult queues a message about r to every channel a result route
+// sends its teams to.
+func (d *dispatcher) notifyResult(eventID, clubID string, r postedResult) {
+	if d == nil {
+		return
+	}
+	at := time.Now()
+	for _, c := range d.channels {
+		if d.wants(c.Name, messageResult, eventID, r.game()) {
+			d.send(c, notification{Kind: messageResult, EventID: eventID, ClubID: clubID, At: at, Result: r})
+		}
+	}
+}
+
+// resultsHandler serves /results?eventid=[&division=][&team=]: the final
+// scores recorded for the event, newest first. division and team keep the
+// results whose division, or either side, contains them.
+func (s *Server) resultsHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	eventID := strings.TrimSpace(q.Get("eventid"))
+	if eventID == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "eventid is required"})
+		return
+	}
+	var results []postedResult
+	if s.store != nil {
+		var err error
+		if results, err = s.storedResults(r.Context(), eventID); err != nil {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, filterResults(results, strings.TrimSpace(q.Get("division")), strings.TrimSpace(q.Get("team"))))
+}
+
+// filterResults keeps the results in a division containing division with a
+// side containing team, either of which may be empty, newest first.
+func filterResults(results []postedResult, division, team string) []postedResult {
+	out := []postedResult{}
+	for _, r := range results {
+		if division != "" && !containsFold(r.Division, division) {
+			continue
+		}
+		if team != "" && !containsFold(r.HomeTeam, team) && !containsFold(r.AwayTeam, team) {
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Date != out[j].Date {
+			return out[i].Date > out[j].Date
+		}
+		return out[i].HomeTeam < out[j].HomeTeam
+	})
+	return out
+}
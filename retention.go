@@ -0,0 +1,190 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ---------- Retention and archival ---------- */
+
+const (
+	jobKindRetention  = "retention"
+	retentionInterval = 24 * time.Hour
+)
+
+// retentionPolicy says which column ages a table's rows.
+type retentionPolicy struct {
+	Table  string
+	Column string
+	Millis bool   // Column is Unix milliseconds rather than seconds
+	Where  string // extra condition on rows that may expire
+}
+
+// retentionPolicies are the tables RETENTION can name. Unfinished jobs
+// never expire.
+var retentionPolicies = []retentionPolicy{
+	{Table: "scrape_log", Column: "at", Millis: true},
+	{Table: "api_audit", Column: "at"},
+	{Table: "jobs", Column: "updated_at", Millis: true, Where: "status IN ('done', 'failed')"},
+	{Table: "game_history", Column: "last_seen", Millis: true},
+}
+
+// defaultRetention keeps logs for a season's worth of troubleshooting and
+// games for two seasons.
+var defaultRetention = map[string]time.Duration{
+	"scrape_log":   90 * 24 * time.Hour,
+	"api_audit":    90 * 24 * time.Hour,
+	"jobs":         30 * 24 * time.Hour,
+	"game_history": 730 * 24 * time.Hour,
+}
+
+// parseRetention reads RETENTION entries such as "scrape_log=30d,
+// game_history=0"; a duration takes d for days as well as Go units, and 0
+// keeps rows forever. Unlisted tables keep the defaults.
+func parseRetention(s string) map[string]time.Duration {
+	out := make(map[string]time.Duration, len(defaultRetention))
+	for table, d := range defaultRetention {
+		out[table] = d
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		table, v, ok := strings.Cut(part, "=")
+		table, v = strings.ToLower(strings.TrimSpace(table)), strings.TrimSpace(v)
+		d, err := time.ParseDuration(v)
+		if days, found := strings.CutSuffix(v, "d"); found {
+			n, nerr := strconv.Atoi(days)
+			d, err = time.Duration(n)*24*time.Hour, nerr
+		}
+		if v == "0" {
+			d, err = 0, nil
+		}
+		if _, known := defaultRetention[table]; !ok || !known || err != nil || d < 0 {
+			log.Printf("ignoring malformed RETENTION entry %q (want scrape_log=90d)", part)
+			continue
+		}
+		out[table] = d
+	}
+	return out
+}
+
+func init() {
+	describeMetric("gotsport_archived_rows_total", kindCounter, "Rows archived and deleted by the retention job, by table.")
+	registerJobHandler(jobKindRetention, func(ctx context.Context, _ json.RawMessage) error {
+		defer scheduleRetention(time.Now().Add(retentionInterval))
+		for _, p := range retentionPolicies {
+			keep := appConfig.Retention[p.Table]
+			if keep <= 0 {
+				continue
+			}
+			n, err := archiveExpired(ctx, p, time.Now().Add(-keep), appConfig.ArchiveDir)
+			if err != nil {
+				return fmt.Errorf("%s: %v", p.Table, err)
+			}
+			if n > 0 {
+				log.Printf("retention: archived %d %s rows", n, p.Table)
+				addCounter("gotsport_archived_rows_total", float64(n), "table", p.Table)
+			}
+		}
+		return nil
+	})
+}
+
+// scheduleRetention queues a retention pass. Each pass queues the one after
+// it; the dedupe key is per day because the running pass would otherwise
+// block its successor, and so restarts don't pile up passes.
+func scheduleRetention(at time.Time) {
+	key := jobKindRetention + "@" + at.UTC().Format("2006-01-02")
+	if err := enqueueJob(jobKindRetention, struct{}{}, jobOptions{DedupeKey: key, RunAt: at, MaxAttempts: 3}); err != nil {
+		log.Printf("retention: %v", err)
+	}
+}
+
+// archiveExpired writes p's rows older than cutoff to a gzipped NDJSON file
+// in dir, one object per row, then deletes them. Nothing is deleted unless
+// the file was written completely.
+func archiveExpired(ctx context.Context, p retentionPolicy, cutoff time.Time, dir string) (int, error) {
+	limit := cutoff.Unix()
+	if p.Millis {
+		limit = cutoff.UnixMilli()
+	}
+	where := p.Column + " < ?"
+	if p.Where != "" {
+		where += " AND " + p.Where
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT * FROM `+p.Table+` WHERE `+where, limit)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	var f *os.File
+	var zw *gzip.Writer
+	var enc *json.Encoder
+	n := 0
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return 0, err
+		}
+		if f == nil {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return 0, err
+			}
+			name := filepath.Join(dir, fmt.Sprintf("%s-%s.ndjson.gz", p.Table, time.Now().UTC().Format("20060102T150405Z")))
+			if f, err = os.Create(name); err != nil {
+				return 0, err
+			}
+			defer f.Close()
+			zw = gzip.NewWriter(f)
+			enc = json.NewEncoder(zw)
+		}
+		row := make(map[string]any, len(cols))
+		for i, c := range cols {
+			if b, ok := vals[i].([]byte); ok {
+				vals[i] = string(b)
+			}
+			row[c] = vals[i]
+		}
+		if err := enc.Encode(row); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+	if n == 0 {
+		return 0, nil
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM `+p.Table+` WHERE `+where, limit); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
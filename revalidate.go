@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+/* ---------- Conditional revalidation ---------- */
+
+const (
+	// maxRevalidateBody is the largest page kept for revalidation.
+	maxRevalidateBody = 8 << 20
+	// maxRevalidateEntries bounds how many pages are kept at once.
+	maxRevalidateEntries = 256
+)
+
+// revalidation is the last 200 response for one upstream URL that carried a
+// validator. A 304 on the next request means body is still current, so the
+// scrape re-parses it without downloading the page again; parsing is never
+// skipped, because which games are next weekend's depends on today's date.
+type revalidation struct {
+	etag         string
+	lastModified string
+	body         []byte // as returned by fetchURL, already UTF-8
+}
+
+var (
+	revalidateMu  sync.Mutex
+	revalidations = map[string]revalidation{} // by URL
+)
+
+// setConditionalHeaders asks upstream to answer 304 if url has not changed
+// since it was last fetched.
+func setConditionalHeaders(req *http.Request, url string) {
+	revalidateMu.Lock()
+	rv, ok := revalidations[url]
+	revalidateMu.Unlock()
+	if !ok {
+		return
+	}
+	if rv.etag != "" {
+		req.Header.Set("If-None-Match", rv.etag)
+	}
+	if rv.lastModified != "" {
+		req.Header.Set("If-Modified-Since", rv.lastModified)
+	}
+}
+
+// rememberResponse keeps body for revalidating url, when upstream sent a
+// validator for it.
+func rememberResponse(url string, h http.Header, body []byte) {
+	rv := revalidation{etag: h.Get("ETag"), lastModified: h.Get("Last-Modified"), body: body}
+	revalidateMu.Lock()
+	defer revalidateMu.Unlock()
+	if rv.etag == "" && rv.lastModified == "" || len(body) > maxRevalidateBody {
+		delete(revalidations, url)
+		return
+	}
+	if _, ok := revalidations[url]; !ok && len(revalidations) >= maxRevalidateEntries {
+		for k := range revalidations { // evict an arbitrary page
+			delete(revalidations, k)
+			break
+		}
+	}
+	revalidations[url] = rv
+}
+
+// revalidatedBody returns the body kept for url after a 304.
+func revalidatedBody(url string) ([]byte, bool) {
+	revalidateMu.Lock()
+	rv, ok := revalidations[url]
+	revalidateMu.Unlock()
+	return rv.body, ok
+}
+
+// noteNotModified records on the scrape in ctx that a fetch was answered
+// from a revalidated body.
+func noteNotModified(ctx context.Context) {
+	if t, ok := ctx.Value(scrapeTraceKey{}).(*scrapeTrace); ok {
+		t.NotModified++
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/* ---------- RSS output ---------- */
+
+// rssFeed is an RSS 2.0 document with one item per game, for feed readers
+// that follow a team but don't take calendars.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// writeRSS renders games as a feed linked to req's GotSport schedule. Each
+// item's guid is the game's calendar UID, so readers see a rescheduled game
+// as the same item.
+func writeRSS(w http.ResponseWriter, req scheduleReq, games []Game) {
+	events := splitEventIDs(req.EventID)
+	feed := rssFeed{Version: "2.0", Channel: rssChannel{
+		Title:         appConfig.ClubName + " schedule",
+		Description:   "Games for club " + req.ClubID + " in event " + strings.Join(events, ", "),
+		LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+	}}
+	if len(events) > 0 {
+		feed.Channel.Link = scheduleFilter{}.scheduleURL(events[0], req.ClubID)
+	}
+	for _, g := range games {
+		title := g.Date + " " + g.Time + ": " + g.HomeTeam + " vs " + g.AwayTeam
+		if g.Status != "" {
+			title = strings.ToUpper(g.Status[:1]) + g.Status[1:] + ": " + title
+		}
+		lines := []string{}
+		if g.Location != "" {
+			lines = append(lines, gameLocation(g))
+		}
+		if desc := gameDescription(g); desc != "" {
+			lines = append(lines, desc)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       title,
+			Link:        g.SourceURL,
+			Description: strings.Join(lines, "\n"),
+			GUID:        rssGUID{Value: gameUID(g)},
+		})
+	}
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "encode_failed", Detail: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* ---------- JSON Schema ---------- */
+
+// apiSchemas lists the documents served under /schema/. Each is generated
+// from the Go type, so the schema can't drift from what we actually encode.
+var apiSchemas = map[string]func() map[string]any{
+	"game.json":  func() map[string]any { return schemaDocument("game.json", "Game", reflect.TypeOf(Game{})) },
+	"error.json": func() map[string]any { return schemaDocument("error.json", "Error", reflect.TypeOf(ErrorResponse{})) },
+	"envelope.json": func() map[string]any {
+		return schemaDocument("envelope.json", "Schedule envelope", reflect.TypeOf(scheduleEnvelope{}))
+	},
+	"strict-error.json": func() map[string]any {
+		return schemaDocument("strict-error.json", "Strict mode error", reflect.TypeOf(strictErrorResponse{}))
+	},
+	"problem.json": func() map[string]any {
+		return schemaDocument("problem.json", "Problem details", reflect.TypeOf(problemDetails{}))
+	},
+	"schedule.json": func() map[string]any {
+		return map[string]any{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id":     "/schema/schedule.json",
+			"title":   "Schedule",
+			"type":    "array",
+			"items":   map[string]any{"$ref": "/schema/game.json"},
+		}
+	},
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func schemaDocument(id, title string, t reflect.Type) map[string]any {
+	s := schemaFor(t)
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["$id"] = "/schema/" + id
+	s["title"] = title
+	return s
+}
+
+// schemaFor maps a Go type to a JSON Schema fragment following encoding/json
+// rules: json tags name properties, omitempty fields are optional, and an
+// untagged embedded struct's properties are its parent's.
+func schemaFor(t reflect.Type) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		return map[string]any{"anyOf": []any{schemaFor(t.Elem()), map[string]any{"type": "null"}}}
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		props := map[string]any{}
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+				embedded := schemaFor(f.Type)
+				for n, p := range embedded["properties"].(map[string]any) {
+					props[n] = p
+				}
+				if r, ok := embedded["required"].([]string); ok {
+					required = append(required, r...)
+				}
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			props[name] = schemaFor(f.Type)
+			if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+				required = append(required, name)
+			}
+		}
+		s := map[string]any{"type": "object", "properties": props}
+		if len(required) > 0 {
+			sort.Strings(required)
+			s["required"] = required
+		}
+		return s
+	}
+	return map[string]any{}
+}
+
+// schemaHandler serves /schema/ (an index) and /schema/<name>.json.
+func schemaHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/schema/")
+	if name == "" {
+		names := make([]string, 0, len(apiSchemas))
+		for n := range apiSchemas {
+			names = append(names, "/schema/"+n)
+		}
+		sort.Strings(names)
+		writeJSON(w, http.StatusOK, map[string]any{"schemas": names})
+		return
+	}
+	build, ok := apiSchemas[name]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:  "not_found",
+			Detail: "unknown schema " + name,
+		})
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("Content-Type", "application/schema+json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(build())
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/* ---------- Opponent scouting ---------- */
+
+// maxScoutResults caps the recent results in a scouting packet.
+const maxScoutResults = 10
+
+// scoutResult is one of the opponent's games, from its side.
+type scoutResult struct {
+	Date     string `json:"date"`
+	Opponent string `json:"opponent"`
+	Home     bool   `json:"home"`
+	Score    string `json:"score"`             // the opponent's goals first, "3–1"
+	Outcome  string `json:"outcome,omitempty"` // W, D or L; empty when the score can't be read
+	Division string `json:"division,omitempty"`
+}
+
+// scoutRecord totals the results a packet is built from.
+type scoutRecord struct {
+	Played       int `json:"played"`
+	Won          int `json:"won"`
+	Drawn        int `json:"drawn"`
+	Lost         int `json:"lost"`
+	GoalsFor     int `json:"goalsFor"`
+	GoalsAgainst int `json:"goalsAgainst"`
+}
+
+func (r *scoutRecord) add(gf, ga int) string {
+	r.Played++
+	r.GoalsFor += gf
+	r.GoalsAgainst += ga
+	switch {
+	case gf > ga:
+		r.Won++
+		return "W"
+	case gf < ga:
+		r.Lost++
+		return "L"
+	default:
+		r.Drawn++
+		return "D"
+	}
+}
+
+func (r scoutRecord) points() int { return 3*r.Won + r.Drawn }
+
+// scoutStanding is the opponent's place in its division table, worked out
+// from the results this service has seen rather than the league's table.
+type scoutStanding struct {
+	Division string `json:"division"`
+	Position int    `json:"position"`
+	Teams    int    `json:"teams"`
+	Points   int    `json:"points"`
+	Played   int    `json:"played"`
+}
+
+// scoutVenue is a field the opponent has been scheduled at.
+type scoutVenue struct {
+	Location string `json:"location"`
+	Games    int    `json:"games"`
+}
+
+// scoutPacket is what /scout returns.
+type scoutPacket struct {
+	Opponent string         `json:"opponent"`
+	Record   scoutRecord    `json:"record"`
+	Results  []scoutResult  `json:"results"` // newest first
+	Standing *scoutStanding `json:"standing,omitempty"`
+	Venues   []scoutVenue   `json:"venues"` // most used first
+}
+
+// goals reads a result such as "3 - 1" as home and away goals.
+func goals(result string) (int, int, bool) {
+	parts := scoreDash.Split(strings.TrimSpace(result), -1)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	h, err1 := strconv.Atoi(parts[0])
+	a, err2 := strconv.Atoi(parts[1])
+	return h, a, err1 == nil && err2 == nil
+}
+
+// scoutHandler serves /scout?opponent=Placer United 2012B: the opponent's
+// recent results, its position in its division and the venues it plays at,
+// from the results and schedules already scraped. Teams match when their
+// name contains opponent, ignoring case; eventid= narrows to one event.
+func (s *Server) scoutHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	opponent := strings.TrimSpace(q.Get("opponent"))
+	if opponent == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "opponent is required"})
+		return
+	}
+	packet, err := s.scout(r.Context(), opponent, strings.TrimSpace(q.Get("eventid")))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, packet)
+}
+
+func (s *Server) scout(ctx context.Context, opponent, eventID string) (scoutPacket, error) {
+	packet := scoutPacket{Opponent: opponent, Results: []scoutResult{}, Venues: []scoutVenue{}}
+	if s.store == nil {
+		return packet, nil
+	}
+	results, err := s.storedResults(ctx, eventID)
+	if err != nil {
+		return packet, err
+	}
+	matches := func(team string) bool { return containsFold(team, opponent) }
+
+	tables := divisionTables(results)
+	var division string
+	sort.Slice(results, func(i, j int) bool { return results[i].Date > results[j].Date })
+	for _, res := range results {
+		hg, ag, ok := goals(res.Result)
+		home := matches(res.HomeTeam)
+		if !home && !matches(res.AwayTeam) {
+			continue
+		}
+		sr := scoutResult{Date: res.Date, Home: home, Division: res.Division, Opponent: res.HomeTeam, Score: res.Score()}
+		if home {
+			sr.Opponent = res.AwayTeam
+		}
+		if ok {
+			gf, ga := hg, ag
+			if !home {
+				gf, ga = ag, hg
+			}
+			sr.Score = strconv.Itoa(gf) + "–" + strconv.Itoa(ga)
+			sr.Outcome = packet.Record.add(gf, ga)
+		}
+		if division == "" && ok {
+			division = res.Division
+		}
+		if len(packet.Results) < maxScoutResults {
+			packet.Results = append(packet.Results, sr)
+		}
+	}
+	packet.Standing = standing(tables[division], division, matches)
+
+	venues, err := s.opponentVenues(ctx, eventID, matches)
+	if err != nil {
+		return packet, err
+	}
+	packet.Venues = venues
+	return packet, nil
+}
+
+// standing returns the place in table of the team matches picks, or nil.
+func standing(table map[string]*scoutRecord, division string, matches func(string) bool) *scoutStanding {
+	for _, row := range rankTable(table) {
+		if matches(row.Team) {
+			return &scoutStanding{Division: division, Position: row.Position, Teams: len(table), Points: row.Points, Played: row.Played}
+		}
+	}
+	return nil
+}
+
+// storedResults returns every result recorded, in eventID when it is set.
+func (s *Server) storedResults(ctx context.Context, eventID string) ([]postedResult, error) {
+	rows, err := s.store.QueryContext(ctx, `SELECT DISTINCT game_id, data FROM posted_results WHERE data <> '' AND (? = '' OR event_id = ?)`, eventID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []postedResult
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		var r postedResult
+		if json.Unmarshal([]byte(data), &r) == nil {
+			out = append(out, r)
+		}
+	}
+	return out, rows.Err()
+}
+
+// opponentVenues counts the locations of stored games one of whose sides
+// matches, most used first.
+func (s *Server) opponentVenues(ctx context.Context, eventID string, matches func(string) bool) ([]scoutVenue, error) {
+	rows, err := s.store.QueryContext(ctx, `SELECT DISTINCT game_id, data FROM game_history WHERE (? = '' OR event_id = ?)`, eventID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		var g Game
+		if json.Unmarshal([]byte(data), &g) != nil || g.Location == "" {
+			continue
+		}
+		if matches(g.HomeTeam) || matches(g.AwayTeam) {
+			counts[g.Location]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	venues := []scoutVenue{}
+	for loc, n := range counts {
+		venues = append(venues, scoutVenue{Location: loc, Games: n})
+	}
+	sort.Slice(venues, func(i, j int) bool {
+		if venues[i].Games != venues[j].Games {
+			return venues[i].Games > venues[j].Games
+		}
+		return venues[i].Location < venues[j].Location
+	})
+	return venues, nil
+}
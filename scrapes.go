@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/* ---------- Scrape history ---------- */
+
+// scrapeAttempt is one live scrape, as kept in the scrape_log table.
+type scrapeAttempt struct {
+	ID         int64     `json:"id,omitempty"`
+	EventID    string    `json:"eventid"`
+	ClubID     string    `json:"clubid"`
+	Source     string    `json:"source"`
+	At         time.Time `json:"at"`
+	DurationMs int64     `json:"durationMs"`
+	HTTPStatus int       `json:"httpStatus,omitempty"` // 0 when no response arrived
+	Games      int       `json:"games"`
+	Error      string    `json:"error,omitempty"`
+	// Render is why a page with no games was or wasn't rendered in the
+	// headless browser; see renderVerdict.
+	Render string `json:"render,omitempty"`
+}
+
+// scrapeStatus is the outcome of the latest scrape of one event/club.
+type scrapeStatus struct {
+	scrapeAttempt
+	LastSuccess time.Time `json:"lastSuccess,omitempty"`
+}
+
+var (
+	scrapeStatusMu sync.Mutex
+	scrapeStatuses = map[string]*scrapeStatus{} // by cacheKey
+)
+
+// scrapeTrace collects details fetchPage learns during one scrape.
+type scrapeTrace struct {
+	HTTPStatus int
+	// Fetches counts upstream responses; NotModified counts the 304s among
+	// them.
+	Fetches     int
+	NotModified int
+	// Render is the headless-render decision, when one was made.
+	Render string
+}
+
+type scrapeTraceKey struct{}
+
+func withScrapeTrace(ctx context.Context) (context.Context, *scrapeTrace) {
+	t := &scrapeTrace{}
+	return context.WithValue(ctx, scrapeTraceKey{}, t), t
+}
+
+// noteHTTPStatus records the upstream status on the scrape in ctx, if any.
+func noteHTTPStatus(ctx context.Context, status int) {
+	if t, ok := ctx.Value(scrapeTraceKey{}).(*scrapeTrace); ok {
+		t.HTTPStatus = status
+		t.Fetches++
+	}
+}
+
+// noteRender records the render decision on the scrape in ctx, if any.
+func noteRender(ctx context.Context, decision string) {
+	if t, ok := ctx.Value(scrapeTraceKey{}).(*scrapeTrace); ok {
+		t.Render = decision
+	}
+}
+
+func init() {
+	describeMetric("gotsport_scrape_errors_total", kindCounter, "Failed scrapes by source and error code (see errorCode).")
+}
+
+// recordScrape notes one live scrape in memory and, when the datastore is
+// open, in scrape_log.
+func recordScrape(a scrapeAttempt, err error) {
+	if err != nil {
+		a.Error = err.Error()
+		incCounter("gotsport_scrape_errors_total", "source", a.Source, "code", errorCode(err))
+	}
+
+	scrapeStatusMu.Lock()
+	key := cacheKey(a.EventID, a.ClubID)
+	s := scrapeStatuses[key]
+	if s == nil {
+		s = &scrapeStatus{}
+		scrapeStatuses[key] = s
+	}
+	s.scrapeAttempt = a
+	if err == nil {
+		s.LastSuccess = a.At
+	}
+	scrapeStatusMu.Unlock()
+
+	if db == nil {
+		return // one-shot CLI runs keep no history
+	}
+	if _, err := db.Exec(`INSERT INTO scrape_log (at, event_id, club_id, source, duration_ms, http_status, games, error, render)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.At.UnixMilli(), a.EventID, a.ClubID, a.Source, a.DurationMs, a.HTTPStatus, a.Games, a.Error, a.Render); err != nil {
+		log.Printf("scrape log: %v", err)
+	}
+}
+
+// lastScrapes returns a copy of every scrape status, ordered by event.
+func lastScrapes() []scrapeStatus {
+	scrapeStatusMu.Lock()
+	out := make([]scrapeStatus, 0, len(scrapeStatuses))
+	for _, s := range scrapeStatuses {
+		out = append(out, *s)
+	}
+	scrapeStatusMu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].EventID != out[j].EventID {
+			return out[i].EventID < out[j].EventID
+		}
+		return out[i].ClubID < out[j].ClubID
+	})
+	return out
+}
+
+// adminScrapesHandler lists scrape attempts, newest first. Filters: eventid,
+// clubid, source, failed=true, since (RFC 3339 or a duration such as 24h),
+// limit (default 100).
+func adminScrapesHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	limit := 100
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_parameters", Detail: "limit must be a positive number"})
+			return
+		}
+		limit = n
+	}
+	var since time.Time
+	if v := q.Get("since"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, v); err == nil {
+			since = t
+		} else {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_parameters", Detail: "since must be RFC 3339 or a duration such as 24h"})
+			return
+		}
+	}
+	failedOnly := false
+	if v := q.Get("failed"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_parameters", Detail: "failed must be true or false"})
+			return
+		}
+		failedOnly = b
+	}
+	eventID, clubID, source := q.Get("eventid"), q.Get("clubid"), q.Get("source")
+
+	rows, err := db.Query(`SELECT id, at, event_id, club_id, source, duration_ms, http_status, games, error, render FROM scrape_log
+		WHERE (? = '' OR event_id = ?) AND (? = '' OR club_id = ?) AND (? = '' OR source = ?)
+			AND at >= ? AND (? = 0 OR error != '')
+		ORDER BY id DESC LIMIT ?`,
+		eventID, eventID, clubID, clubID, source, source, since.UnixMilli(), boolInt(failedOnly), limit)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return
+	}
+	defer rows.Close()
+	attempts := []scrapeAttempt{}
+	for rows.Next() {
+		var a scrapeAttempt
+		var at int64
+		if err := rows.Scan(&a.ID, &at, &a.EventID, &a.ClubID, &a.Source, &a.DurationMs, &a.HTTPStatus, &a.Games, &a.Error, &a.Render); err != nil {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+			return
+		}
+		a.At = time.UnixMilli(at).UTC()
+		attempts = append(attempts, a)
+	}
+	writeJSON(w, http.StatusOK, attempts)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"gotsport-api/internal/fixtures"
+)
+
+/* ---------- Parser self-test ---------- */
+
+// bundledFixtures are the golden fixtures, built into the binary so a
+// deployed server can check its parser against them.
+//
+//go:embed testdata/fixtures
+var bundledFixtures embed.FS
+
+// selfTestTimeout bounds one self-test run.
+const selfTestTimeout = time.Minute
+
+func init() {
+	cliCommands["selftest"] = cliSelfTest
+	describeMetric("gotsport_selftest_ok", kindGauge, "1 when the last parser self-test passed, 0 when it failed.")
+}
+
+// selfTestFixture is one fixture's outcome.
+type selfTestFixture struct {
+	ID     string `json:"id"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// selfTestResult is the outcome of one self-test run.
+type selfTestResult struct {
+	OK       bool              `json:"ok"`
+	At       time.Time         `json:"at"`
+	Fixtures []selfTestFixture `json:"fixtures"`
+	Error    string            `json:"error,omitempty"` // the run itself failed
+}
+
+// failures summarizes the fixtures that failed, or the run's own error.
+func (r selfTestResult) failures() string {
+	if r.Error != "" {
+		return r.Error
+	}
+	var ids []string
+	for _, f := range r.Fixtures {
+		if !f.OK {
+			ids = append(ids, f.ID)
+		}
+	}
+	return strings.Join(ids, ", ")
+}
+
+var (
+	selfTestMu   sync.Mutex
+	lastSelfTest *selfTestResult // nil until the first run finishes
+)
+
+// parseFixture parses a saved page as scrapeGotSport would have on the day
+// it was captured. Callers pin clock to f.Meta.AsOf first.
+func parseFixture(f fixtures.Fixture) ([]Game, error) {
+	// Pages are saved as served; decode them as fetchURL would.
+	page := toUTF8(f.Page, "")
+	stats := newParseStats(f.Meta.EventID, len(f.Page))
+	if f.Meta.View == scrapeViewExport {
+		candidates, rowDates, err := parseExportCSV(page, stats)
+		if err != nil {
+			return nil, err
+		}
+		return weekendGames(candidates, rowDates, f.Meta.EventID, scheduleFilter{}, stats), nil
+	}
+	return parseWeekendGames(string(page), f.Meta.EventID, scheduleFilter{}, stats), nil
+}
+
+// checkFixtures parses every bundled fixture against its golden output. It
+// moves clock, so it only runs in a process of its own: see cliSelfTest.
+func checkFixtures() selfTestResult {
+	res := selfTestResult{OK: true, At: time.Now().UTC()}
+	sub, err := fs.Sub(bundledFixtures, "testdata/fixtures")
+	if err != nil {
+		return selfTestResult{At: res.At, Error: err.Error()}
+	}
+	corpus, err := fixtures.LoadFS(sub)
+	if err != nil {
+		return selfTestResult{At: res.At, Error: err.Error()}
+	}
+	defer func() { clock = time.Now }()
+	for _, f := range corpus {
+		if f.Source != "gotsport" || f.Expected == nil {
+			continue
+		}
+		out := selfTestFixture{ID: f.ID(), OK: true}
+		clock = func() time.Time { return f.Meta.AsOf }
+		games, err := parseFixture(f)
+		if err == nil {
+			out.Detail, err = f.Diff(games)
+		}
+		if err != nil {
+			out.Detail = err.Error()
+		}
+		if out.Detail != "" {
+			out.OK, res.OK = false, false
+		}
+		res.Fixtures = append(res.Fixtures, out)
+	}
+	return res
+}
+
+// cliSelfTest prints checkFixtures' result as JSON and fails when it does.
+func cliSelfTest(args []string) error {
+	log.SetOutput(io.Discard)
+	res := checkFixtures()
+	if err := json.NewEncoder(os.Stdout).Encode(res); err != nil {
+		return err
+	}
+	if !res.OK {
+		return fmt.Errorf("self-test failed: %s", res.failures())
+	}
+	return nil
+}
+
+// runSelfTest runs the selftest command in a child process, which may pin
+// clock without disturbing live scrapes, and records its result.
+func runSelfTest(ctx context.Context) selfTestResult {
+	ctx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+	res := selfTestResult{At: time.Now().UTC()}
+	exe, err := os.Executable()
+	if err == nil {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, exe, "selftest")
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		runErr := cmd.Run()
+		if err = json.Unmarshal(stdout.Bytes(), &res); err != nil {
+			err = fmt.Errorf("%v: %s", runErr, strings.TrimSpace(stderr.String()))
+		}
+	}
+	if err != nil {
+		res = selfTestResult{At: res.At, Error: err.Error()}
+	}
+
+	selfTestMu.Lock()
+	lastSelfTest = &res
+	selfTestMu.Unlock()
+	setGauge("gotsport_selftest_ok", float64(boolInt(res.OK)))
+	if !res.OK {
+		log.Printf("parser self-test failed: %s", res.failures())
+	}
+	return res
+}
+
+// runSelfTests runs the self-test now and then every interval until ctx is
+// done.
+func runSelfTests(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		runSelfTest(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// selfTestHealth returns the /health fields for the last self-test and
+// whether it failed.
+func selfTestHealth() (map[string]string, bool) {
+	selfTestMu.Lock()
+	res := lastSelfTest
+	selfTestMu.Unlock()
+	if res == nil {
+		return map[string]string{"selfTest": "pending"}, false
+	}
+	out := map[string]string{"selfTest": "passed", "selfTestAt": res.At.Format(time.RFC3339)}
+	if !res.OK {
+		out["selfTest"] = "failed"
+		out["selfTestDetail"] = res.failures()
+	}
+	return out, !res.OK
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/* ---------- Server ---------- */
+
+// Scraper fetches and parses one event's schedule, narrowed by f.
+type Scraper interface {
+	Scrape(ctx context.Context, eventID, clubID string, f scheduleFilter) ([]Game, *parseStats, error)
+}
+
+// gotsportScraper is the live Scraper.
+type gotsportScraper struct{}
+
+func (gotsportScraper) Scrape(ctx context.Context, eventID, clubID string, f scheduleFilter) ([]Game, *parseStats, error) {
+	if strings.EqualFold(eventID, "ecnl") {
+		return []Game{}, nil, nil // TODO: implement ECNL if needed
+	}
+	return scrapeGotSport(ctx, eventID, clubID, f, false)
+}
+
+// Cache keeps scrape results by cache key; *scheduleCache is the in-memory
+// one and *redisCache the one instances share.
+type Cache interface {
+	get(key string, maxAge time.Duration) ([]Game, time.Duration, bool)
+	peek(key string) ([]Game, time.Time, bool)
+	set(key string, games []Game)
+	// maxAge is how old a result may be before it is scraped again.
+	maxAge() time.Duration
+}
+
+// Clock tells a Server the time for response-time logic such as past games
+// and countdowns. The parser's weekend window still reads clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// clockFunc adapts a func to Clock.
+type clockFunc func() time.Time
+
+func (f clockFunc) Now() time.Time { return f() }
+
+// packageClock follows clock, so tests that pin it pin the Server too.
+var packageClock = clockFunc(func() time.Time { return clock() })
+
+// Server serves the API from its own scraper, cache, datastore and clock,
+// and the curated data it loads from that datastore, so tests can run one
+// over fakes and a process can host several. What describes the process
+// rather than a Server is shared: metrics, upstream health, recent scrapes
+// and changes, the usage and scrape logs, and the job queue, whose jobs run
+// on defaultServer.
+type Server struct {
+	scraper Scraper
+	cache   Cache
+	store   Store // nil for one-shot CLI runs, which keep no history
+	clock   Clock
+	flights *flightGroup
+	started time.Time
+
+	// In-memory copies of the store's curated tables; see loadIndexes.
+	venues      *venueSet
+	annotations *annotationSet
+	overrides   *overrideSet
+	hidden      *hiddenSet
+	manual      *manualSet
+	webhooks    *webhookSet
+}
+
+// NewServer returns a Server over the given dependencies.
+func NewServer(scraper Scraper, cache Cache, store Store, clock Clock) *Server {
+	return &Server{
+		scraper: scraper,
+		cache:   cache,
+		store:   store,
+		clock:   clock,
+		flights: &flightGroup{calls: map[string]*flight{}},
+		started: time.Now(),
+
+		venues:      newVenueSet(appConfig.Venues),
+		annotations: &annotationSet{notes: map[string][]annotation{}},
+		overrides:   &overrideSet{byID: map[string]gameOverride{}},
+		hidden:      &hiddenSet{byID: map[string]hiddenGame{}},
+		manual:      &manualSet{byID: map[string]manualGame{}},
+		webhooks:    &webhookSet{byID: map[string]webhookSubscription{}},
+	}
+}
+
+// defaultServer serves background jobs and CLI commands; main replaces it
+// with the Server it listens with, once the datastore is open.
+var defaultServer = NewServer(gotsportScraper{}, defaultCache, nil, packageClock)
+
+// Handler returns s's routes behind the standard middleware.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schedule", s.scheduleHandler)
+	mux.HandleFunc("/schedule/print", s.printHandler)
+	mux.HandleFunc("/health", healthHandler)
+	mux.HandleFunc("/metrics", metricsHandler)
+	mux.HandleFunc("/stats", s.statsHandler)
+	mux.HandleFunc("/status", s.statusHandler)
+	mux.HandleFunc("/me/usage", meUsageHandler)
+	mux.HandleFunc("/widget", s.widgetHandler)
+	mux.HandleFunc("/itinerary", s.itineraryHandler)
+	mux.HandleFunc("/carpool", s.carpoolHandler)
+	mux.HandleFunc("/teams/directory", s.teamsDirectoryHandler)
+	mux.HandleFunc("/teams/registry", s.teamRegistryHandler)
+	mux.HandleFunc("/scout", s.scoutHandler)
+	mux.HandleFunc("/results", s.resultsHandler)
+	mux.HandleFunc("/standings", s.standingsHandler)
+	mux.HandleFunc("/audit/division", auditDivisionHandler)
+	mux.HandleFunc("/byes", s.byesHandler)
+	mux.HandleFunc("/conflicts/coaches", s.coachConflictsHandler)
+	mux.HandleFunc("/reports/field-usage", s.fieldUsageHandler)
+	mux.HandleFunc("/export/full", s.exportFullHandler)
+	mux.HandleFunc("/webhooks", s.webhooksHandler)
+	mux.HandleFunc("/schema/", schemaHandler)
+	mux.HandleFunc("/debug/parse", s.debugParseHandler)
+	if appConfig.PprofEnabled {
+		mountPprof(mux)
+	}
+	mux.HandleFunc("/admin/venues", s.adminVenuesHandler)
+	mux.HandleFunc("/admin/annotations", s.adminAnnotationsHandler)
+	mux.HandleFunc("/admin/overrides", s.adminOverridesHandler)
+	mux.HandleFunc("/admin/hidden", s.adminHiddenHandler)
+	mux.HandleFunc("/admin/games", s.adminGamesHandler)
+	mux.HandleFunc("/admin/backup", s.adminBackupHandler)
+	mux.HandleFunc("/admin/restore", s.adminRestoreHandler)
+	mux.HandleFunc("/admin/usage", adminUsageHandler)
+	mux.HandleFunc("/admin/refresh", adminRefreshHandler)
+	mux.HandleFunc("/admin/scrapes", adminScrapesHandler)
+	mux.HandleFunc("/admin/notify/preview", adminNotifyPreviewHandler)
+	mux.HandleFunc("/admin/login", adminLoginHandler)
+	mux.HandleFunc("/admin/callback", adminCallbackHandler)
+	mux.HandleFunc("/admin/logout", adminLogoutHandler)
+	mux.HandleFunc("/admin/", s.adminDashboardHandler)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if cors(w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "RenoApex GotSport Parser v13.0\n\nEndpoints:\n- GET/POST /schedule\n- /schedule/print\n- /widget\n- /itinerary\n- /carpool\n- /teams/directory\n- /teams/registry\n- /scout\n- /results\n- /standings\n- /audit/division\n- /byes\n- /conflicts/coaches\n- /reports/field-usage\n- /export/full\n- /schema/\n- /health\n- /metrics\n- /stats\n- /status\n- /me/usage\n- /admin/ (dashboard)\n- /admin/venues\n- /admin/annotations\n- /admin/overrides\n- /admin/hidden\n- /admin/games\n- /admin/backup\n- /admin/restore\n- /admin/usage\n- /admin/scrapes\n- /admin/notify/preview\n- /webhooks")
+	})
+	return logRequests(recordUsage(mux, recoverPanics(allowlist(rateLimit(shedLoad(mux))))))
+}
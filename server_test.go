@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// fakeScraper serves canned games and counts its calls.
+type fakeScraper struct {
+	games []Game
+	calls int
+}
+
+func (f *fakeScraper) Scrape(ctx context.Context, eventID, clubID string, _ scheduleFilter) ([]Game, *parseStats, error) {
+	f.calls++
+	return f.games, nil, nil
+}
+
+// Two Servers in one process keep separate caches, and each serves from
+// its own scraper: live first, then cached.
+func TestServerSchedule(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	now := time.Date(2025, 8, 27, 12, 0, 0, 0, time.UTC)
+	fixed := clockFunc(func() time.Time { return now })
+	game := Game{ID: "g1", HomeTeam: "Reno Apex 2012B", AwayTeam: "Visitors FC", Date: "2025-08-30", Time: "9:00AM"}
+	a := &fakeScraper{games: []Game{game}}
+	b := &fakeScraper{games: []Game{}}
+	srvA := NewServer(a, newScheduleCache(time.Minute), nil, fixed)
+	srvB := NewServer(b, newScheduleCache(time.Minute), nil, fixed)
+
+	for i, wantTier := range []dataTier{tierFresh, tierCache} {
+		rec := httptest.NewRecorder()
+		srvA.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/schedule?eventid=1&clubid=2&envelope=true", nil))
+		var env scheduleEnvelope
+		if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+			t.Fatalf("request %d: %v: %s", i, err, rec.Body)
+		}
+		if env.Tier != wantTier || len(env.Games) != 1 || env.Games[0].HomeTeam != game.HomeTeam {
+			t.Errorf("request %d: tier %q, games %+v; want tier %q and the fake's game", i, env.Tier, env.Games, wantTier)
+		}
+		if env.Games[0].IsPast {
+			t.Errorf("request %d: game marked past at %s", i, now)
+		}
+	}
+	if a.calls != 1 {
+		t.Errorf("server A scraped %d times, want 1", a.calls)
+	}
+
+	rec := httptest.NewRecorder()
+	srvB.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/schedule?eventid=1&clubid=2", nil))
+	var games []Game
+	if err := json.Unmarshal(rec.Body.Bytes(), &games); err != nil {
+		t.Fatalf("server B: %v: %s", err, rec.Body)
+	}
+	if len(games) != 0 || b.calls != 1 {
+		t.Errorf("server B: %d games after %d scrapes; want its own empty schedule", len(games), b.calls)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+/* ---------- Load shedding ---------- */
+
+var (
+	inFlight      atomic.Int64 // requests currently being served
+	fetchWaiters  atomic.Int64 // scrapes waiting for a fetchLimit slot
+	shedRetryHint = strconv.Itoa(int(appConfig.ShedRetryAfter.Seconds()))
+)
+
+func init() {
+	describeMetric("gotsport_requests_in_flight", kindGauge, "Requests currently being served.")
+	describeMetric("gotsport_requests_shed_total", kindCounter, "Requests rejected because the service was overloaded.")
+}
+
+// shedLoad rejects requests with 503 once too many are in flight. Health and
+// metrics stay reachable so operators can see what is going on.
+func shedLoad(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" || r.URL.Path == "/metrics" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		n := inFlight.Add(1)
+		defer func() { setGauge("gotsport_requests_in_flight", float64(inFlight.Add(-1))) }()
+		setGauge("gotsport_requests_in_flight", float64(n))
+
+		if limit := appConfig.MaxInFlight; limit > 0 && n > int64(limit) {
+			rejectOverloaded(w, http.StatusServiceUnavailable, "inflight",
+				"Too many requests in flight; retry shortly")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// scrapeQueueFull reports whether a request needing a live scrape should be
+// turned away because the fetch queue is already backed up.
+func scrapeQueueFull() bool {
+	limit := appConfig.MaxFetchQueue
+	return limit > 0 && fetchWaiters.Load() >= int64(limit)
+}
+
+func rejectOverloaded(w http.ResponseWriter, status int, reason, detail string) {
+	incCounter("gotsport_requests_shed_total", "reason", reason)
+	w.Header().Set("Retry-After", shedRetryHint)
+	writeJSON(w, status, ErrorResponse{
+		Error:  "overloaded",
+		Detail: detail,
+	})
+}
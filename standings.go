@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+/* ---------- Standings ---------- */
+
+// standingRow is one team's line in a division table.
+type standingRow struct {
+	Position int    `json:"position"`
+	Team     string `json:"team"`
+	scoutRecord
+	Points int `json:"points"`
+}
+
+// divisionStandings is one division's table, worked out from the results
+// this service has seen rather than the league's own table.
+type divisionStandings struct {
+	Division string        `json:"division"`
+	Teams    []standingRow `json:"teams"`
+}
+
+// divisionTables totals results by division, then team. Results with no
+// division or a score that can't be read are left out.
+func divisionTables(results []postedResult) map[string]map[string]*scoutRecord {
+	tables := map[string]map[string]*scoutRecord{}
+	for _, res := range results {
+		hg, ag, ok := goals(res.Result)
+		if !ok || res.Division == "" {
+			continue
+		}
+		t := tables[res.Division]
+		if t == nil {
+			t = map[string]*scoutRecord{}
+			tables[res.Division] = t
+		}
+		for _, side := range []struct {
+			team   string
+			gf, ga int
+		}{{res.HomeTeam, hg, ag}, {res.AwayTeam, ag, hg}} {
+			rec := t[side.team]
+			if rec == nil {
+				rec = &scoutRecord{}
+				t[side.team] = rec
+			}
+			rec.add(side.gf, side.ga)
+		}
+	}
+	return tables
+}
+
+// rankTable orders table by points, goal difference, goals scored and
+// then name.
+func rankTable(table map[string]*scoutRecord) []standingRow {
+	rows := make([]standingRow, 0, len(table))
+	for team, rec := range table {
+		rows = append(rows, standingRow{Team: team, scoutRecord: *rec, Points: rec.points()})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if gd, gdb := a.GoalsFor-a.GoalsAgainst, b.GoalsFor-b.GoalsAgainst; gd != gdb {
+			return gd > gdb
+		}
+		if a.GoalsFor != b.GoalsFor {
+			return a.GoalsFor > b.GoalsFor
+		}
+		return a.Team < b.Team
+	})
+	for i := range rows {
+		rows[i].Position = i + 1
+	}
+	return rows
+}
+
+// standingsFrom ranks every division results cover, or only the one whose
+// name contains division when it is set, in division order.
+func standingsFrom(results []postedResult, division string) []divisionStandings {
+	out := []divisionStandings{}
+	for name, table := range divisionTables(results) {
+		if division == "" || containsFold(name, division) {
+			out = append(out, divisionStandings{Division: name, Teams: rankTable(table)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Division < out[j].Division })
+	return out
+}
+
+// standingsHandler serves /standings?eventid=[&division=]: division tables
+// from the results recorded for the event.
+func (s *Server) standingsHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	eventID := strings.TrimSpace(q.Get("eventid"))
+	if eventID == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "eventid is required"})
+		return
+	}
+	var results []postedResult
+	if s.store != nil {
+		var err error
+		if results, err = s.storedResults(r.Context(), eventID); err != nil {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, standingsFrom(results, strings.TrimSpace(q.Get("division"))))
+}
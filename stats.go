@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+/* ---------- Parse stats ---------- */
+
+// parseStats describes the cost of parsing one scraped page.
+type parseStats struct {
+	EventID     string             `json:"eventid"`
+	At          time.Time          `json:"at"`
+	HTMLBytes   int                `json:"htmlBytes"`
+	JSONMatches int                `json:"jsonMatches"` // match objects found in embedded JSON
+	RowMatches  int                `json:"rowMatches"`
+	CellRows    int                `json:"cellRows"`    // rows with the expected 7 cells
+	HomeMatches int                `json:"homeMatches"` // club home rows that passed orientation checks
+	Games       int                `json:"games"`
+	Path        string             `json:"path,omitempty"` // the scrape path Games came from; see scrapePathXHR
+	Strategies  map[string]float64 `json:"strategyMs"`
+	TotalMs     float64            `json:"totalMs"`
+
+	// Warnings list club rows that could not be turned into games.
+	Warnings []parseWarning `json:"warnings,omitempty"`
+	// Results are final scores on the club's rows, which games leave out.
+	Results []postedResult `json:"results,omitempty"`
+
+	Explain []rowExplain `json:"explain,omitempty"`
+	explain bool         // record Explain rows
+	// club is the name club teams are recognised by; see isClubTeam.
+	club string
+	// allSides keeps the club's away games and bracket placeholders too.
+	allSides bool
+	// side is scheduleFilter.Side.
+	side string
+	// keepFixtures records every fixture judged, club or not, in fixtures.
+	keepFixtures bool
+	fixtures     []rawFixture
+}
+
+// Scrape paths, as parseStats.Path and game_history's source. The print
+// and export views are recorded by their view name.
+const (
+	scrapePathXHR    = "xhr"           // the schedule's own JSON endpoint
+	scrapePathJSON   = "embedded_json" // match objects embedded in the page
+	scrapePathTables = "html_tables"   // the page's schedule tables
+	scrapePathRender = "render"        // the page as a headless browser renders it
+)
+
+func newParseStats(eventID string, htmlBytes int) *parseStats {
+	return &parseStats{EventID: eventID, At: time.Now(), HTMLBytes: htmlBytes, Strategies: map[string]float64{}, club: appConfig.ClubName}
+}
+
+// newPageStats is newParseStats for a scrape narrowed by f, keeping the
+// club and sides f asks for.
+func newPageStats(eventID string, size int, f scheduleFilter, explain bool) *parseStats {
+	stats := newParseStats(eventID, size)
+	stats.explain = explain
+	stats.allSides = f.AllSides
+	stats.side = f.Side
+	if f.Club != "" {
+		stats.club = f.Club
+	}
+	return stats
+}
+
+// timeStrategy adds the time since start to the named strategy.
+func (s *parseStats) timeStrategy(name string, start time.Time) {
+	s.Strategies[name] += float64(time.Since(start).Microseconds()) / 1000
+}
+
+var (
+	lastParseMu sync.Mutex
+	lastParse   = map[string]*parseStats{} // by event ID
+)
+
+func init() {
+	describeHistogram("gotsport_html_bytes", "Size of scraped upstream pages.", bytesBuckets)
+	describeHistogram("gotsport_parse_seconds", "Total time spent parsing one page.", secondsBuckets)
+	describeHistogram("gotsport_parse_strategy_seconds", "Time spent in each parse strategy.", secondsBuckets)
+	describeMetric("gotsport_parse_regex_matches_total", kindCounter, "Regex matches produced while parsing, by pattern.")
+}
+
+func recordParseStats(s *parseStats) {
+	observe("gotsport_html_bytes", float64(s.HTMLBytes))
+	observe("gotsport_parse_seconds", s.TotalMs/1000)
+	for name, ms := range s.Strategies {
+		observe("gotsport_parse_strategy_seconds", ms/1000, "strategy", name)
+	}
+	addCounter("gotsport_parse_regex_matches_total", float64(s.RowMatches), "pattern", "row")
+	addCounter("gotsport_parse_regex_matches_total", float64(s.HomeMatches), "pattern", "home_marker")
+
+	snapshot := *s
+	snapshot.Explain = nil // explain output belongs to the caller that asked for it
+	lastParseMu.Lock()
+	lastParse[s.EventID] = &snapshot
+	lastParseMu.Unlock()
+}
+
+func (s *Server) statsHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	lastParseMu.Lock()
+	parses := make([]*parseStats, 0, len(lastParse))
+	for _, p := range lastParse {
+		parses = append(parses, p)
+	}
+	lastParseMu.Unlock()
+	sort.Slice(parses, func(i, j int) bool { return parses[i].EventID < parses[j].EventID })
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"uptimeSeconds": int(time.Since(s.started).Seconds()),
+		"inFlight":      inFlight.Load(),
+		"fetchesActive": fetchLimit.inUse(),
+		"fetchWaiters":  fetchWaiters.Load(),
+		"renderer":      renderPoolSnapshot(),
+		"lastParse":     parses,
+		"upstreams":     upstreamSummaries(),
+	})
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* ---------- Public status page ---------- */
+
+// statusEvent is one event/club schedule on /status.
+type statusEvent struct {
+	EventID         string     `json:"eventid"`
+	ClubID          string     `json:"clubid"`
+	LastSuccess     *time.Time `json:"lastSuccess,omitempty"`
+	GamesCached     int        `json:"gamesCached"`
+	CacheAgeSeconds *int       `json:"cacheAgeSeconds,omitempty"`
+	Stale           bool       `json:"stale"`
+}
+
+// statusSource groups events by the upstream site they are scraped from.
+type statusSource struct {
+	Source        string        `json:"source"`
+	LastSuccess   *time.Time    `json:"lastSuccess,omitempty"`
+	CircuitOpen   bool          `json:"circuitOpen"`
+	FailureStreak int           `json:"failureStreak"`
+	Events        []statusEvent `json:"events"`
+}
+
+// statusAlert is an active problem worth reporting before users notice it.
+type statusAlert struct {
+	Kind    string `json:"kind"` // circuit_open, scrape_failing, parser_no_rows, parser_no_cells
+	Source  string `json:"source"`
+	EventID string `json:"eventid,omitempty"`
+	Message string `json:"message"`
+}
+
+type statusReport struct {
+	Status      string         `json:"status"` // ok, degraded or down
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Sources     []statusSource `json:"sources"`
+	Alerts      []statusAlert  `json:"alerts"`
+}
+
+// sourceOf names the upstream an event ID is scraped from.
+func sourceOf(eventID string) string {
+	if strings.EqualFold(eventID, "ecnl") {
+		return "ecnl"
+	}
+	return "gotsport"
+}
+
+func (s *Server) buildStatus() statusReport {
+	rep := statusReport{Status: "ok", GeneratedAt: time.Now().UTC(), Alerts: []statusAlert{}}
+	bySource := map[string]*statusSource{}
+	source := func(name string) *statusSource {
+		s := bySource[name]
+		if s == nil {
+			s = &statusSource{Source: name, Events: []statusEvent{}}
+			bySource[name] = s
+		}
+		return s
+	}
+	for _, u := range upstreamSummaries() {
+		s := source(u.Source)
+		s.LastSuccess, s.CircuitOpen, s.FailureStreak = u.LastOK, u.CircuitOpen, u.Streak
+		if u.CircuitOpen {
+			rep.Alerts = append(rep.Alerts, statusAlert{Kind: "circuit_open", Source: u.Source,
+				Message: "Upstream is failing; requests are served from cache until it recovers"})
+		}
+	}
+
+	for _, e := range s.dashboardEvents() {
+		se := statusEvent{EventID: e.EventID, ClubID: e.ClubID, Stale: !e.Cached || e.Stale}
+		if !e.Scrape.LastSuccess.IsZero() {
+			t := e.Scrape.LastSuccess.UTC()
+			se.LastSuccess = &t
+		}
+		if games, at, ok := s.cache.peek(cacheKey(e.EventID, e.ClubID)); ok {
+			age := int(time.Since(at).Seconds())
+			se.GamesCached, se.CacheAgeSeconds = len(games), &age
+		}
+		src := sourceOf(e.EventID)
+		s := source(src)
+		s.Events = append(s.Events, se)
+		if e.Scrape.Error != "" && se.Stale {
+			rep.Alerts = append(rep.Alerts, statusAlert{Kind: "scrape_failing", Source: src, EventID: e.EventID,
+				Message: "Latest scrape failed and cached data is stale"})
+		}
+	}
+
+	lastParseMu.Lock()
+	for _, p := range lastParse {
+		switch {
+		case p.HTMLBytes > 0 && p.RowMatches == 0 && p.JSONMatches == 0:
+			rep.Alerts = append(rep.Alerts, statusAlert{Kind: "parser_no_rows", Source: sourceOf(p.EventID), EventID: p.EventID,
+				Message: "Schedule page had no table rows or embedded schedule data; the page layout may have changed"})
+		case p.RowMatches > 0 && p.CellRows == 0:
+			rep.Alerts = append(rep.Alerts, statusAlert{Kind: "parser_no_cells", Source: sourceOf(p.EventID), EventID: p.EventID,
+				Message: "No schedule row had the expected columns; the table layout may have changed"})
+		}
+	}
+	lastParseMu.Unlock()
+
+	for _, s := range bySource {
+		rep.Sources = append(rep.Sources, *s)
+	}
+	sort.Slice(rep.Sources, func(i, j int) bool { return rep.Sources[i].Source < rep.Sources[j].Source })
+	sort.SliceStable(rep.Alerts, func(i, j int) bool { return rep.Alerts[i].Source < rep.Alerts[j].Source })
+
+	stale, total := 0, 0
+	for _, s := range rep.Sources {
+		for _, e := range s.Events {
+			total++
+			if e.Stale {
+				stale++
+			}
+		}
+	}
+	switch {
+	case total > 0 && stale == total && len(rep.Alerts) > 0:
+		rep.Status = "down"
+	case len(rep.Alerts) > 0 || stale > 0:
+		rep.Status = "degraded"
+	}
+	return rep
+}
+
+var statusTmpl = template.Must(template.New("status").Funcs(template.FuncMap{
+	"ago": func(t *time.Time) string {
+		if t == nil {
+			return "never"
+		}
+		return time.Since(*t).Round(time.Second).String() + " ago"
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="60">
+<title>Schedule service status</title>
+<style>
+body{margin:0 auto;max-width:800px;padding:16px;color:#1b1f24;font:14px/1.4 system-ui,-apple-system,Segoe UI,Roboto,sans-serif}
+h1{font-size:20px}h2{font-size:16px;margin-top:24px}
+table{width:100%;border-collapse:collapse}
+th,td{padding:5px 8px;border-bottom:1px solid #e3e6ea;text-align:left}
+th{color:#5b636d;font-weight:600;font-size:12px;text-transform:uppercase}
+.ok{color:#1e7b34}.degraded{color:#a15c00}.down,.bad{color:#b3261e}
+.muted{color:#5b636d}
+</style></head>
+<body>
+<h1>Schedule service: <span class="{{.Status}}">{{.Status}}</span></h1>
+{{if .Alerts}}<h2>Active alerts</h2><ul>{{range .Alerts}}
+<li class="bad">{{.Source}}{{with .EventID}} event {{.}}{{end}}: {{.Message}}</li>{{end}}
+</ul>{{end}}
+{{range .Sources}}<h2>{{.Source}}{{if .CircuitOpen}} <span class="bad">(unavailable)</span>{{end}}</h2>
+<p class="muted">Last successful fetch: {{ago .LastSuccess}}</p>
+<table>
+<thead><tr><th>Event / club</th><th>Last successful scrape</th><th>Games cached</th><th>Freshness</th></tr></thead>
+<tbody>{{range .Events}}
+<tr><td>{{.EventID}} / {{.ClubID}}</td><td>{{ago .LastSuccess}}</td><td>{{.GamesCached}}</td>
+<td>{{if .Stale}}<span class="bad">stale</span>{{else}}<span class="ok">fresh</span>{{end}}</td></tr>{{else}}
+<tr><td colspan="4" class="muted">No schedules tracked yet.</td></tr>{{end}}
+</tbody></table>{{else}}<p class="muted">No schedules tracked yet.</p>{{end}}
+<p class="muted">Generated {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}.</p>
+</body></html>
+`))
+
+// statusHandler serves /status as JSON, or HTML for browsers and format=html.
+func (s *Server) statusHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	rep := s.buildStatus()
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Add("Vary", "Accept")
+	html := strings.EqualFold(r.URL.Query().Get("format"), "html")
+	if r.URL.Query().Get("format") == "" {
+		if ranges := parseAccept(r.Header.Get("Accept")); len(ranges) > 0 && ranges[0] == "text/html" {
+			html = true
+		}
+	}
+	if !html {
+		writeJSON(w, http.StatusOK, rep)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statusTmpl.Execute(w, rep); err != nil {
+		log.Printf("status render: %v", err)
+	}
+}
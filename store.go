@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+/* ---------- Datastore ---------- */
+
+const (
+	dialectSQLite   = "sqlite"
+	dialectPostgres = "postgres"
+)
+
+// Store is the datastore as the rest of the server sees it. Queries are
+// written once, in SQLite's dialect with ? placeholders; each backend
+// translates what it must.
+type Store interface {
+	Dialect() string
+	Exec(query string, args ...any) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	Begin() (Tx, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+	Close() error
+}
+
+// Tx is a transaction opened on a Store.
+type Tx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Commit() error
+	Rollback() error
+}
+
+// db is the process-wide datastore, opened in main from DATABASE_URL or
+// DB_PATH.
+var db Store
+
+// sqlStore is a Store over a database/sql handle.
+type sqlStore struct {
+	db      *sql.DB
+	dialect string
+}
+
+func (s *sqlStore) Dialect() string { return s.dialect }
+func (s *sqlStore) Close() error    { return s.db.Close() }
+
+func (s *sqlStore) Exec(query string, args ...any) (sql.Result, error) {
+	return s.db.Exec(s.rebind(query), args...)
+}
+
+func (s *sqlStore) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return s.db.ExecContext(ctx, s.rebind(query), args...)
+}
+
+func (s *sqlStore) Query(query string, args ...any) (*sql.Rows, error) {
+	return s.db.Query(s.rebind(query), args...)
+}
+
+func (s *sqlStore) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, s.rebind(query), args...)
+}
+
+func (s *sqlStore) QueryRow(query string, args ...any) *sql.Row {
+	return s.db.QueryRow(s.rebind(query), args...)
+}
+
+func (s *sqlStore) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return s.db.QueryRowContext(ctx, s.rebind(query), args...)
+}
+
+func (s *sqlStore) Begin() (Tx, error) {
+	return s.BeginTx(context.Background(), nil)
+}
+
+func (s *sqlStore) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{tx: tx, store: s}, nil
+}
+
+type sqlTx struct {
+	tx    *sql.Tx
+	store *sqlStore
+}
+
+func (t *sqlTx) Exec(query string, args ...any) (sql.Result, error) {
+	return t.tx.Exec(t.store.rebind(query), args...)
+}
+
+func (t *sqlTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, t.store.rebind(query), args...)
+}
+
+func (t *sqlTx) Commit() error   { return t.tx.Commit() }
+func (t *sqlTx) Rollback() error { return t.tx.Rollback() }
+
+// rebind rewrites ? placeholders as $1, $2, ... for Postgres, leaving any
+// inside quoted literals alone.
+func (s *sqlStore) rebind(query string) string {
+	if s.dialect != dialectPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	n, quoted := 0, false
+	for _, c := range query {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == '?' && !quoted:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// sqliteHandle returns the database/sql handle behind d when it is a SQLite
+// store, for the few SQLite-only operations such as backups.
+func sqliteHandle(d Store) (*sql.DB, bool) {
+	s, ok := d.(*sqlStore)
+	if !ok || s.dialect != dialectSQLite {
+		return nil, false
+	}
+	return s.db, true
+}
+
+// migrations are applied in order; append new statements, never edit old ones.
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS jobs (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind         TEXT    NOT NULL,
+		dedupe_key   TEXT    NOT NULL DEFAULT '',
+		payload      TEXT    NOT NULL,
+		status       TEXT    NOT NULL DEFAULT 'pending',
+		attempts     INTEGER NOT NULL DEFAULT 0,
+		max_attempts INTEGER NOT NULL,
+		run_at       INTEGER NOT NULL,
+		last_error   TEXT    NOT NULL DEFAULT '',
+		created_at   INTEGER NOT NULL,
+		updated_at   INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS jobs_due ON jobs (status, run_at)`,
+	`CREATE TABLE IF NOT EXISTS venues (
+		name       TEXT    PRIMARY KEY COLLATE NOCASE,
+		address    TEXT    NOT NULL,
+		updated_at INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS api_usage (
+		day      TEXT    NOT NULL,
+		endpoint TEXT    NOT NULL,
+		client   TEXT    NOT NULL,
+		requests INTEGER NOT NULL,
+		errors   INTEGER NOT NULL,
+		PRIMARY KEY (day, endpoint, client)
+	)`,
+	`CREATE TABLE IF NOT EXISTS api_audit (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		at          INTEGER NOT NULL,
+		endpoint    TEXT    NOT NULL,
+		client      TEXT    NOT NULL,
+		method      TEXT    NOT NULL,
+		params      TEXT    NOT NULL,
+		status      INTEGER NOT NULL,
+		duration_ms INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS api_audit_client ON api_audit (client, id)`,
+	`CREATE TABLE IF NOT EXISTS scrape_log (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		at          INTEGER NOT NULL,
+		event_id    TEXT    NOT NULL,
+		club_id     TEXT    NOT NULL,
+		source      TEXT    NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		http_status INTEGER NOT NULL,
+		games       INTEGER NOT NULL,
+		error       TEXT    NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS scrape_log_event ON scrape_log (event_id, id)`,
+	`CREATE TABLE IF NOT EXISTS game_annotations (
+		game_id    TEXT    NOT NULL,
+		label      TEXT    NOT NULL COLLATE NOCASE,
+		note       TEXT    NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (game_id, label)
+	)`,
+	`CREATE TABLE IF NOT EXISTS game_overrides (
+		game_id    TEXT    PRIMARY KEY,
+		location   TEXT    NOT NULL,
+		date       TEXT    NOT NULL,
+		time       TEXT    NOT NULL,
+		status     TEXT    NOT NULL,
+		note       TEXT    NOT NULL,
+		updated_at INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS game_hidden (
+		game_id   TEXT    PRIMARY KEY,
+		reason    TEXT    NOT NULL,
+		hidden_at INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS manual_games (
+		id         TEXT    PRIMARY KEY,
+		club_id    TEXT    NOT NULL,
+		event_id   TEXT    NOT NULL,
+		home_team  TEXT    NOT NULL,
+		away_team  TEXT    NOT NULL,
+		date       TEXT    NOT NULL,
+		time       TEXT    NOT NULL,
+		location   TEXT    NOT NULL,
+		division   TEXT    NOT NULL,
+		created_at INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS manual_games_club ON manual_games (club_id)`,
+	`CREATE TABLE IF NOT EXISTS game_history (
+		game_id    TEXT    NOT NULL,
+		event_id   TEXT    NOT NULL,
+		club_id    TEXT    NOT NULL,
+		data       TEXT    NOT NULL,
+		first_seen INTEGER NOT NULL,
+		last_seen  INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (game_id, event_id, club_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS game_history_updated ON game_history (updated_at)`,
+	`CREATE TABLE IF NOT EXISTS posted_results (
+		game_id   TEXT    NOT NULL,
+		event_id  TEXT    NOT NULL,
+		club_id   TEXT    NOT NULL,
+		result    TEXT    NOT NULL,
+		posted_at INTEGER NOT NULL,
+		PRIMARY KEY (game_id, event_id, club_id)
+	)`,
+	`ALTER TABLE posted_results ADD COLUMN data TEXT NOT NULL DEFAULT ''`,
+	`CREATE TABLE IF NOT EXISTS team_names (
+		team_id    TEXT    NOT NULL,
+		name       TEXT    NOT NULL,
+		first_seen INTEGER NOT NULL,
+		last_seen  INTEGER NOT NULL,
+		PRIMARY KEY (team_id, name)
+	)`,
+	`ALTER TABLE scrape_log ADD COLUMN render TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE game_history ADD COLUMN source TEXT NOT NULL DEFAULT ''`,
+	`CREATE TABLE IF NOT EXISTS webhooks (
+		id         TEXT    PRIMARY KEY,
+		url        TEXT    NOT NULL,
+		event_id   TEXT    NOT NULL,
+		club_id    TEXT    NOT NULL,
+		secret     TEXT    NOT NULL,
+		created_at INTEGER NOT NULL
+	)`,
+}
+
+// openStore opens the Postgres database at databaseURL when it is set, and
+// the SQLite file at path otherwise.
+func openStore(databaseURL, path string) (Store, error) {
+	if databaseURL != "" {
+		return openPostgres(databaseURL)
+	}
+	return openDB(path)
+}
+
+// openDB opens the SQLite datastore at path, creating and migrating it as
+// needed.
+func openDB(path string) (Store, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)", path)
+	d, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %v", path, err)
+	}
+	// SQLite allows one writer; a single connection avoids SQLITE_BUSY churn.
+	d.SetMaxOpenConns(1)
+	s := &sqlStore{db: d, dialect: dialectSQLite}
+	if err := migrate(s); err != nil {
+		d.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// migrate brings d up to the latest schema, translating each migration for
+// Postgres.
+func migrate(d Store) error {
+	if _, err := d.Exec(`CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)`); err != nil {
+		return fmt.Errorf("migrate: %v", err)
+	}
+	var version int
+	if err := d.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return fmt.Errorf("migrate: %v", err)
+	}
+	for i := version; i < len(migrations); i++ {
+		stmt := migrations[i]
+		if d.Dialect() == dialectPostgres {
+			stmt = postgresDDL(stmt)
+		}
+		if _, err := d.Exec(stmt); err != nil {
+			return fmt.Errorf("migration %d: %v", i+1, err)
+		}
+		if _, err := d.Exec(`INSERT INTO schema_version (version) VALUES (?)`, i+1); err != nil {
+			return fmt.Errorf("migration %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
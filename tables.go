@@ -0,0 +1,246 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/* ---------- Table structure ---------- */
+
+var (
+	// tableTagPattern finds the tags that shape a table; everything else is
+	// cell content.
+	tableTagPattern = regexp.MustCompile(`(?is)<(/?)(table|tr|td|th)\b([^>]*)>`)
+	colspanAttr     = regexp.MustCompile(`(?i)\bcolspan\s*=\s*["']?(\d+)`)
+	rowspanAttr     = regexp.MustCompile(`(?i)\browspan\s*=\s*["']?(\d+)`)
+	// teamLinkPattern finds links to a team's schedule or page and captures
+	// its GotSport team ID.
+	teamLinkPattern = regexp.MustCompile(`(?i)\bhref\s*=\s*["']?[^"'>\s]*(?:[?&]team=|/teams/)(\d+)`)
+	// gameLinkPattern finds links to a game's own page and captures the
+	// href.
+	gameLinkPattern = regexp.MustCompile(`(?i)\bhref\s*=\s*["']?([^"'>\s]*(?:/matches?/\d+|[?&](?:match|match_id|game)=\d+)[^"'>\s]*)`)
+	// dateHeadingPattern finds headings, captions and date-classed elements,
+	// where pages that split the schedule into one table per day put the day.
+	dateHeadingPattern = regexp.MustCompile(`(?is)<h[1-6]\b[^>]*>(.*?)</h[1-6]>|<caption\b[^>]*>(.*?)</caption>|<\w+\b[^>]*\bclass\s*=\s*["'][^"']*\bdate[^"']*["'][^>]*>([^<]*)<`)
+)
+
+// maxSpan caps colspan and rowspan, as browsers do, so a bad attribute can't
+// blow up the grid.
+const maxSpan = 1000
+
+// tableRow is one <tr> laid out on the table's grid: cells[i] is the
+// cleaned text in column i, with colspan and rowspan cells placed in every
+// column and row they cover.
+type tableRow struct {
+	cells []string
+	// teamIDs are the GotSport team IDs linked from each cell, in order.
+	teamIDs [][]string
+	// link is the first game page a cell of the row links to, as written.
+	link   string
+	header bool // every cell is a <th>
+	// group is the text of a row's lone cell, such as a "Saturday, August
+	// 30" separator; cells is then empty.
+	group string
+}
+
+// pageTable is one outermost table and the markup between it and the
+// previous one.
+type pageTable struct {
+	inner string
+	lead  string
+}
+
+// leadDate returns the date a table is headed with: its caption or else the
+// last heading or date-classed element before it that reads as a date.
+func (t pageTable) leadDate() string {
+	date := ""
+	for _, src := range []string{t.lead, t.inner[:captionEnd(t.inner)]} {
+		for _, m := range dateHeadingPattern.FindAllStringSubmatch(src, -1) {
+			if text := cleanText(m[1] + m[2] + m[3]); hasDate(text) {
+				date = text
+			}
+		}
+	}
+	return date
+}
+
+// captionEnd bounds the search for a caption to the markup before the
+// first row.
+func captionEnd(inner string) int {
+	if loc := tableTagPattern.FindStringIndex(inner); loc != nil {
+		return loc[0]
+	}
+	return len(inner)
+}
+
+// rawCell is a <td> or <th> as written, before layout.
+type rawCell struct {
+	html             string
+	header           bool
+	colspan, rowspan int
+}
+
+// hasTables reports whether html has a <table> at all.
+func hasTables(html string) bool {
+	for _, m := range tableTagPattern.FindAllStringSubmatchIndex(html, -1) {
+		if strings.EqualFold(html[m[4]:m[5]], "table") {
+			return true
+		}
+	}
+	return false
+}
+
+// pageTables returns each outermost table in html. Nested tables stay
+// inside their parent's cells. A page with no tables is treated as one.
+func pageTables(html string) []pageTable {
+	var out []pageTable
+	depth, start, prevEnd := 0, 0, 0
+	lead := ""
+	for _, m := range tableTagPattern.FindAllStringSubmatchIndex(html, -1) {
+		if !strings.EqualFold(html[m[4]:m[5]], "table") {
+			continue
+		}
+		if m[3] == m[2] { // opening tag
+			if depth == 0 {
+				lead, start = html[prevEnd:m[0]], m[1]
+			}
+			depth++
+		} else if depth > 0 {
+			if depth--; depth == 0 {
+				out = append(out, pageTable{inner: html[start:m[0]], lead: lead})
+				prevEnd = m[1]
+			}
+		}
+	}
+	if depth > 0 {
+		out = append(out, pageTable{inner: html[start:], lead: lead}) // unclosed table
+	}
+	if len(out) == 0 {
+		out = []pageTable{{inner: html}}
+	}
+	return out
+}
+
+// tableRows splits a table's inner markup into rows and lays them out.
+// Tags inside nested tables are skipped, and omitted </td> and </tr> end
+// tags are implied, as the HTML parser would.
+func tableRows(table string) []tableRow {
+	var rows [][]rawCell
+	var cur []rawCell
+	inRow, cellStart, depth := false, -1, 0
+	closeCell := func(end int) {
+		if cellStart >= 0 {
+			cur[len(cur)-1].html = table[cellStart:end]
+			cellStart = -1
+		}
+	}
+	closeRow := func(end int) {
+		closeCell(end)
+		if inRow && len(cur) > 0 {
+			rows = append(rows, cur)
+		}
+		cur, inRow = nil, false
+	}
+	for _, m := range tableTagPattern.FindAllStringSubmatchIndex(table, -1) {
+		closing := m[3] > m[2]
+		name := strings.ToLower(table[m[4]:m[5]])
+		if name == "table" {
+			if !closing {
+				depth++
+			} else if depth > 0 {
+				depth--
+			}
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		switch {
+		case name == "tr" && !closing:
+			closeRow(m[0])
+			inRow = true
+		case name == "tr":
+			closeRow(m[0])
+		case !closing:
+			closeCell(m[0])
+			inRow = true
+			attrs := table[m[6]:m[7]]
+			cur = append(cur, rawCell{header: name == "th", colspan: spanAttr(colspanAttr, attrs), rowspan: spanAttr(rowspanAttr, attrs)})
+			cellStart = m[1]
+		default:
+			closeCell(m[0])
+		}
+	}
+	closeRow(len(table))
+	return layoutRows(rows)
+}
+
+// linkedTeamIDs returns the team IDs html links to, in order.
+func linkedTeamIDs(html string) []string {
+	var ids []string
+	for _, m := range teamLinkPattern.FindAllStringSubmatch(html, -1) {
+		ids = append(ids, m[1])
+	}
+	return ids
+}
+
+func spanAttr(pattern *regexp.Regexp, attrs string) int {
+	if m := pattern.FindStringSubmatch(attrs); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > 0 {
+			return min(n, maxSpan)
+		}
+	}
+	return 1
+}
+
+// layoutRows places cells on the grid. A rowspan cell is repeated in the
+// rows below it; a colspan cell fills each column it covers.
+func layoutRows(rows [][]rawCell) []tableRow {
+	type carry struct {
+		text string
+		ids  []string
+		left int // rows still covered
+	}
+	var pending []carry // by column
+	out := make([]tableRow, 0, len(rows))
+	for _, raw := range rows {
+		if len(raw) == 1 && raw[0].rowspan == 1 {
+			out = append(out, tableRow{group: cleanText(raw[0].html)})
+			continue
+		}
+		row := tableRow{header: true}
+		col := 0
+		place := func(text string, ids []string) {
+			row.cells = append(row.cells, text)
+			row.teamIDs = append(row.teamIDs, ids)
+			col++
+		}
+		fillCarried := func() {
+			for col < len(pending) && pending[col].left > 0 {
+				pending[col].left--
+				place(pending[col].text, pending[col].ids)
+			}
+		}
+		for _, c := range raw {
+			fillCarried()
+			text, ids := cleanText(c.html), linkedTeamIDs(c.html)
+			if m := gameLinkPattern.FindStringSubmatch(c.html); m != nil && row.link == "" {
+				row.link = m[1]
+			}
+			row.header = row.header && c.header
+			for i := 0; i < c.colspan; i++ {
+				for len(pending) <= col {
+					pending = append(pending, carry{})
+				}
+				if c.rowspan > 1 {
+					pending[col] = carry{text: text, ids: ids, left: c.rowspan - 1}
+				}
+				place(text, ids)
+			}
+		}
+		fillCarried()
+		out = append(out, row)
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+/* ---------- Team directory ---------- */
+
+// slugUnsafe matches the runs of characters a slug replaces with "-".
+var slugUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// teamSlug is the URL-safe name of a team, "reno-apex-2012b-elite" for
+// "Reno Apex 2012B Elite". It depends only on the name, so it is stable
+// across scrapes and restarts.
+func teamSlug(name string) string {
+	return strings.Trim(slugUnsafe.ReplaceAllString(strings.ToLower(normalizeText(name)), "-"), "-")
+}
+
+// teamGames keeps the games one of whose sides has the given slug, in place.
+// A side listed under a TEAM_ALIASES alias has its team's slug as well.
+func teamGames(games []Game, slug string) []Game {
+	aliases := linkTeams(nil)
+	out := games[:0]
+	for _, g := range games {
+		a := aliases.apply(g)
+		if teamSlug(g.HomeTeam) == slug || teamSlug(g.AwayTeam) == slug || teamSlug(a.HomeTeam) == slug || teamSlug(a.AwayTeam) == slug {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// teamGamesByID keeps the games one of whose sides has the given GotSport
+// team ID, in place.
+func teamGamesByID(games []Game, id string) []Game {
+	out := games[:0]
+	for _, g := range games {
+		if g.HomeTeamID == id || g.AwayTeamID == id {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// clubSideID returns the GotSport team ID of g's club side, or "".
+func clubSideID(g Game) string {
+	switch side := clubSide(g); {
+	case side == "":
+		return ""
+	case side == g.HomeTeam:
+		return g.HomeTeamID
+	default:
+		return g.AwayTeamID
+	}
+}
+
+// teamEntry is one of the club's teams in /teams/directory.
+type teamEntry struct {
+	// ID is the GotSport team ID, when the schedule links the team.
+	ID       string   `json:"id,omitempty"`
+	Slug     string   `json:"slug"`
+	Name     string   `json:"name"`
+	Division string   `json:"division,omitempty"`
+	ClubID   string   `json:"clubid"`
+	Events   []string `json:"events"`
+	// Aliases are other names the team is listed under; see linkTeams.
+	Aliases []string `json:"aliases,omitempty"`
+	// Feeds are /schedule URLs for the team by format name.
+	Feeds map[string]string `json:"feeds"`
+}
+
+// teamFeedFormats are the formats listed in each entry's feeds.
+var teamFeedFormats = []string{"json", "ics", "rss", "csv"}
+
+// teamsDirectoryHandler serves /teams/directory: every club team seen in
+// the configured events' schedules, with the feeds that follow it (by team
+// ID when the schedule links the team, else by slug). A team
+// cross-listed under different names has one entry whose feeds cover every
+// event it is in. Teams only appear once a schedule that lists them has
+// been scraped.
+func (s *Server) teamsDirectoryHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	var sets []eventGames
+	var clubs []string
+	for _, ref := range appConfig.Events {
+		res, err := s.getSchedule(r.Context(), ref.EventID, ref.ClubID, scheduleFilter{}, false, s.cache.maxAge())
+		if err != nil {
+			log.Printf("teams directory %s/%s: %v", ref.EventID, ref.ClubID, err)
+			continue
+		}
+		sets = append(sets, eventGames{EventID: ref.EventID, Games: res.Games})
+		clubs = append(clubs, ref.ClubID)
+	}
+	links := linkTeams(sets)
+
+	bySlug := map[string]*teamEntry{}
+	for i, set := range sets {
+		for _, g := range set.Games {
+			listed := clubSide(g)
+			name := links.canonical(listed)
+			slug := teamSlug(name)
+			if slug == "" {
+				continue
+			}
+			e, ok := bySlug[slug]
+			if !ok {
+				e = &teamEntry{Slug: slug, Name: name, ClubID: clubs[i]}
+				bySlug[slug] = e
+			}
+			if e.ID == "" {
+				e.ID = clubSideID(g)
+			}
+			if listed != name && !containsString(e.Aliases, listed) {
+				e.Aliases = append(e.Aliases, listed)
+			}
+			if e.Division == "" {
+				e.Division = g.Division
+			}
+			if !containsString(e.Events, set.EventID) {
+				e.Events = append(e.Events, set.EventID)
+			}
+		}
+	}
+
+	teams := make([]teamEntry, 0, len(bySlug))
+	for _, e := range bySlug {
+		e.Feeds = map[string]string{}
+		for _, format := range teamFeedFormats {
+			q := url.Values{"eventid": {strings.Join(e.Events, ",")}, "clubid": {e.ClubID}, "teamSlug": {e.Slug}, "format": {format}}
+			if e.ID != "" {
+				// Follow the ID, so the feed survives the team's renaming.
+				q.Del("teamSlug")
+				q.Set("teamId", e.ID)
+			}
+			e.Feeds[format] = "/schedule?" + q.Encode()
+		}
+		teams = append(teams, *e)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Slug < teams[j].Slug })
+	writeJSON(w, http.StatusOK, teams)
+}
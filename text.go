@@ -0,0 +1,31 @@
+package main
+
+import (
+	"html"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+/* ---------- Text normalization ---------- */
+
+// punctuationFolds maps typographic punctuation to the ASCII GotSport uses
+// elsewhere, so "St. Mary’s" and "St. Mary's" are the same team.
+var punctuationFolds = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "′", "'",
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`, "″", `"`,
+	"‐", "-", "‑", "-", "‒", "-", "–", "-", "—", "-",
+	"\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "",
+)
+
+// normalizeText is applied to every string the parsers extract: it decodes
+// HTML entities, folds smart quotes and dashes, composes accents (NFC) and
+// collapses runs of whitespace, non-breaking spaces included.
+func normalizeText(s string) string {
+	if strings.Contains(s, "&") {
+		s = html.UnescapeString(s)
+	}
+	s = punctuationFolds.Replace(s)
+	s = norm.NFC.String(s)
+	return strings.Join(strings.Fields(s), " ")
+}
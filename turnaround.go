@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+/* ---------- Double-headers and short rest ---------- */
+
+// turnaroundGame is one of a club team's games placed on the clock.
+type turnaroundGame struct {
+	i           int // index into the schedule
+	start, stop time.Time
+}
+
+func init() {
+	registerFlagCheck(flagTurnarounds)
+}
+
+// flagTurnarounds flags club teams' games that share a day with another of
+// the team's games ("double_header") or start less than appConfig.MinRest
+// after the previous one ends ("short_rest"). Cancelled and postponed games
+// and games without a kickoff are left out.
+func flagTurnarounds(games []Game) {
+	byTeam := map[string][]turnaroundGame{}
+	for i, g := range games {
+		team := clubSide(g)
+		if team == "" || g.Status == "cancelled" || g.Status == "postponed" {
+			continue
+		}
+		start, ok := gameKickoff(g)
+		if !ok {
+			continue
+		}
+		byTeam[team] = append(byTeam[team], turnaroundGame{i, start, start.Add(gameDuration(g, start))})
+	}
+	for team, tg := range byTeam {
+		sort.Slice(tg, func(a, b int) bool { return tg[a].start.Before(tg[b].start) })
+		perDay := map[string]int{}
+		for _, t := range tg {
+			perDay[games[t.i].Date]++
+		}
+		for k, t := range tg {
+			g := &games[t.i]
+			if n := perDay[g.Date]; n > 1 {
+				g.flag("double_header", fmt.Sprintf("%s has %d games on %s", team, n, g.Date))
+			}
+			if k == 0 || appConfig.MinRest <= 0 {
+				continue
+			}
+			prev := tg[k-1]
+			if rest := t.start.Sub(prev.stop); rest < appConfig.MinRest {
+				g.flag("short_rest", fmt.Sprintf("%s kicks off %s after its %s game ends", team, restText(rest), games[prev.i].Time))
+			}
+		}
+	}
+}
+
+// restText writes a rest gap as "45m" or "1h30m"; an overlap reads as
+// "0m".
+func restText(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+	_ "time/tzdata" // tz= accepts any IANA zone; don't depend on the host's zoneinfo
+)
+
+/* ---------- Timezones ---------- */
+
+const defaultEventTZ = "America/Los_Angeles"
+
+func eventTZFromEnv() string {
+	name := stringFromEnv("EVENT_TZ", defaultEventTZ)
+	if _, err := time.LoadLocation(name); err != nil {
+		log.Printf("invalid EVENT_TZ=%q, using %s", name, defaultEventTZ)
+		return defaultEventTZ
+	}
+	return name
+}
+
+// eventLocation is the zone upstream pages print kickoffs in, and the one
+// Game dates and times are stored in.
+var eventLocation = sync.OnceValue(func() *time.Location {
+	loc, err := time.LoadLocation(appConfig.EventTZ)
+	if err != nil {
+		return time.FixedZone("PDT", -7*60*60) // fallback
+	}
+	return loc
+})
+
+// inTimezone rewrites each game's date, time and end time into loc. Games without a
+// parseable kickoff are left as scraped. It edits games in place, so call
+// it last: gameKickoff reads Game times as event-local.
+func inTimezone(games []Game, loc *time.Location) []Game {
+	for i, g := range games {
+		if start, ok := gameKickoff(g); ok {
+			start = start.In(loc)
+			games[i].Date = start.Format("2006-01-02")
+			games[i].Time = start.Format("3:04PM MST")
+			if g.DurationMinutes > 0 {
+				games[i].EndTime = start.Add(time.Duration(g.DurationMinutes) * time.Minute).Format("3:04PM MST")
+			}
+		}
+	}
+	return games
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/* ---------- Upstream health ---------- */
+
+const latencyWindow = 200 // recent samples kept per source for percentiles
+
+// upstreamHealth tracks one upstream source's latency, status codes and
+// failure streak. The streak drives the circuit breaker.
+type upstreamHealth struct {
+	latencies []time.Duration // ring buffer
+	next      int
+	statuses  map[string]int
+	streak    int
+	lastError string
+	lastOK    time.Time
+	openUntil time.Time
+}
+
+var (
+	upstreamMu sync.Mutex
+	upstreams  = map[string]*upstreamHealth{}
+)
+
+func init() {
+	describeHistogram("gotsport_upstream_seconds", "Upstream request latency by source.", secondsBuckets)
+	describeMetric("gotsport_upstream_responses_total", kindCounter, "Upstream responses by source and status code (\"error\" for transport failures).")
+	describeMetric("gotsport_upstream_failure_streak", kindGauge, "Consecutive failed upstream requests by source.")
+	describeMetric("gotsport_upstream_circuit_open", kindGauge, "1 while the circuit breaker for a source is open.")
+}
+
+func upstreamFor(source string) *upstreamHealth {
+	u, ok := upstreams[source]
+	if !ok {
+		u = &upstreamHealth{statuses: map[string]int{}}
+		upstreams[source] = u
+	}
+	return u
+}
+
+// recordUpstream notes the outcome of one request; status 0 means the request
+// never got a response.
+func recordUpstream(source string, status int, took time.Duration, err error) {
+	code := "error"
+	if status != 0 {
+		code = strconv.Itoa(status)
+	}
+	observe("gotsport_upstream_seconds", took.Seconds(), "source", source)
+	incCounter("gotsport_upstream_responses_total", "source", source, "code", code)
+
+	upstreamMu.Lock()
+	defer upstreamMu.Unlock()
+	u := upstreamFor(source)
+	if len(u.latencies) < latencyWindow {
+		u.latencies = append(u.latencies, took)
+	} else {
+		u.latencies[u.next] = took
+	}
+	u.next = (u.next + 1) % latencyWindow
+	u.statuses[code]++
+
+	if err == nil {
+		u.streak = 0
+		u.lastOK = time.Now()
+		u.openUntil = time.Time{}
+	} else {
+		u.streak++
+		u.lastError = err.Error()
+		if t := appConfig.BreakerThreshold; t > 0 && u.streak >= t {
+			u.openUntil = time.Now().Add(appConfig.BreakerCooldown)
+		}
+	}
+	setGauge("gotsport_upstream_failure_streak", float64(u.streak), "source", source)
+	setGauge("gotsport_upstream_circuit_open", boolGauge(!u.openUntil.IsZero()), "source", source)
+}
+
+// checkCircuit fails fast while a source's breaker is open. Once the cooldown
+// passes one request is let through; its outcome closes or re-opens it.
+func checkCircuit(source string) error {
+	upstreamMu.Lock()
+	defer upstreamMu.Unlock()
+	u := upstreamFor(source)
+	if u.openUntil.IsZero() {
+		return nil
+	}
+	if wait := time.Until(u.openUntil); wait > 0 {
+		return fmt.Errorf("%s circuit open after %d consecutive failures; retry in %s",
+			source, u.streak, wait.Round(time.Second))
+	}
+	u.openUntil = time.Now().Add(appConfig.BreakerCooldown) // half-open: one probe
+	return nil
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// upstreamSummary is the /stats view of one source.
+type upstreamSummary struct {
+	Source      string         `json:"source"`
+	Samples     int            `json:"samples"`
+	P50Ms       float64        `json:"p50Ms"`
+	P90Ms       float64        `json:"p90Ms"`
+	P99Ms       float64        `json:"p99Ms"`
+	Statuses    map[string]int `json:"statuses"`
+	Streak      int            `json:"failureStreak"`
+	LastError   string         `json:"lastError,omitempty"`
+	LastOK      *time.Time     `json:"lastSuccess,omitempty"`
+	CircuitOpen bool           `json:"circuitOpen"`
+}
+
+func upstreamSummaries() []upstreamSummary {
+	upstreamMu.Lock()
+	defer upstreamMu.Unlock()
+	out := make([]upstreamSummary, 0, len(upstreams))
+	for source, u := range upstreams {
+		sorted := append([]time.Duration(nil), u.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		s := upstreamSummary{
+			Source:      source,
+			Samples:     len(sorted),
+			P50Ms:       percentileMs(sorted, 0.50),
+			P90Ms:       percentileMs(sorted, 0.90),
+			P99Ms:       percentileMs(sorted, 0.99),
+			Statuses:    make(map[string]int, len(u.statuses)),
+			Streak:      u.streak,
+			LastError:   u.lastError,
+			CircuitOpen: time.Now().Before(u.openUntil),
+		}
+		for k, v := range u.statuses {
+			s.Statuses[k] = v
+		}
+		if !u.lastOK.IsZero() {
+			t := u.lastOK
+			s.LastOK = &t
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Source < out[j].Source })
+	return out
+}
+
+func percentileMs(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(p * float64(len(sorted)-1))
+	return float64(sorted[i].Microseconds()) / 1000
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ---------- API usage ---------- */
+
+// usageEvent is one served request, queued for the usage writer.
+type usageEvent struct {
+	At       time.Time
+	Endpoint string // mux pattern, e.g. "/schema/"
+	Client   string // "key:<hash prefix>" or "ip:<addr>"
+	Method   string
+	Params   string // query string with secrets removed
+	Status   int
+	Duration time.Duration
+}
+
+// usageQueue decouples request handling from datastore writes; events are
+// dropped rather than blocking a request when the writer falls behind.
+var usageQueue = make(chan usageEvent, 1024)
+
+// unloggedParams are never written to the audit or access logs.
+var unloggedParams = []string{"apikey", "api_key", "key", "token", "access_token"}
+
+func init() {
+	describeMetric("gotsport_usage_dropped_total", kindCounter, "Usage events dropped because the writer queue was full.")
+}
+
+// recordUsage wraps the server handler, queueing one usageEvent per request.
+// Probe endpoints are skipped so they don't drown out real clients.
+func recordUsage(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := mux.Handler(r)
+		if !appConfig.UsageLog || pattern == "/health" || pattern == "/metrics" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if pattern == "" {
+			pattern = "(unmatched)"
+		}
+		ev := usageEvent{
+			At:       start.UTC(),
+			Endpoint: pattern,
+			Client:   clientID(r),
+			Method:   r.Method,
+			Params:   auditParams(r.URL.Query()),
+			Status:   rec.status,
+			Duration: time.Since(start),
+		}
+		select {
+		case usageQueue <- ev:
+		default:
+			incCounter("gotsport_usage_dropped_total")
+		}
+	})
+}
+
+// statusRecorder remembers the status code a handler wrote.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// Flush passes through so streaming handlers keep working behind it.
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (s *statusRecorder) Unwrap() http.ResponseWriter { return s.ResponseWriter }
+
+// clientID names the caller: a hash prefix of its API key, so keys never
+// reach the datastore, or else its IP address.
+func clientID(r *http.Request) string {
+	if key := apiKeyFrom(r); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:6])
+	}
+	return "ip:" + clientIP(r)
+}
+
+// clientIP is the remote address or, behind a trusted proxy (TRUST_PROXY),
+// the right-most X-Forwarded-For hop not itself a trusted proxy. The
+// left-most hop is whatever the client sent, so it is never believed.
+func clientIP(r *http.Request) string {
+	if appConfig.TrustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			hops := strings.Split(fwd, ",")
+			i := len(hops) - 1
+			for i > 0 && inNets(strings.TrimSpace(hops[i]), appConfig.TrustedProxies) {
+				i--
+			}
+			return strings.TrimSpace(hops[i])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func auditParams(q url.Values) string {
+	for _, name := range unloggedParams {
+		q.Del(name)
+	}
+	return q.Encode()
+}
+
+// loggedURL is u for the access log, with the values of unloggedParams
+// redacted.
+func loggedURL(u *url.URL) string {
+	q := u.Query()
+	redacted := false
+	for _, name := range unloggedParams {
+		if q.Has(name) {
+			q.Set(name, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u.String()
+	}
+	c := *u
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+// runUsageWriter drains usageQueue into the datastore in small batches
+// until ctx is done.
+func runUsageWriter(ctx context.Context) {
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	var batch []usageEvent
+	for {
+		select {
+		case <-ctx.Done():
+			flushUsage(batch)
+			return
+		case ev := <-usageQueue:
+			batch = append(batch, ev)
+			if len(batch) < 100 {
+				continue
+			}
+		case <-tick.C:
+		}
+		if len(batch) > 0 {
+			flushUsage(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func flushUsage(batch []usageEvent) {
+	if len(batch) == 0 {
+		return
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("usage: %v", err)
+		return
+	}
+	defer tx.Rollback()
+	for _, ev := range batch {
+		if _, err := tx.Exec(`INSERT INTO api_usage (day, endpoint, client, requests, errors)
+			VALUES (?, ?, ?, 1, ?)
+			ON CONFLICT (day, endpoint, client) DO UPDATE SET
+				requests = api_usage.requests + 1, errors = api_usage.errors + excluded.errors`,
+			ev.At.Format("2006-01-02"), ev.Endpoint, ev.Client, boolInt(ev.Status >= 400)); err != nil {
+			log.Printf("usage: %v", err)
+			return
+		}
+		if _, err := tx.Exec(`INSERT INTO api_audit (at, endpoint, client, method, params, status, duration_ms)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			ev.At.Unix(), ev.Endpoint, ev.Client, ev.Method, ev.Params, ev.Status, ev.Duration.Milliseconds()); err != nil {
+			log.Printf("usage: %v", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("usage: %v", err)
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// usageRow is one endpoint/client total in /admin/usage.
+type usageRow struct {
+	Endpoint string `json:"endpoint"`
+	Client   string `json:"client"`
+	Requests int    `json:"requests"`
+	Errors   int    `json:"errors"`
+}
+
+// auditEntry is one request from the audit log.
+type auditEntry struct {
+	At         time.Time `json:"at"`
+	Endpoint   string    `json:"endpoint"`
+	Client     string    `json:"client"`
+	Method     string    `json:"method"`
+	Params     string    `json:"params"`
+	Status     int       `json:"status"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// adminUsageHandler reports request totals per endpoint and client over the
+// last days (default 7), plus the most recent audit entries (limit, default
+// 50). client= and endpoint= narrow both.
+func adminUsageHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	days, limit := 7, 50
+	for name, dst := range map[string]*int{"days": &days, "limit": &limit} {
+		if v := q.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_parameters", Detail: name + " must be a positive number"})
+				return
+			}
+			*dst = n
+		}
+	}
+	since := time.Now().UTC().AddDate(0, 0, -(days - 1))
+	client, endpoint := q.Get("client"), q.Get("endpoint")
+
+	totals := []usageRow{}
+	rows, err := db.Query(`SELECT endpoint, client, SUM(requests), SUM(errors) FROM api_usage
+		WHERE day >= ? AND (? = '' OR client = ?) AND (? = '' OR endpoint = ?)
+		GROUP BY endpoint, client ORDER BY SUM(requests) DESC`,
+		since.Format("2006-01-02"), client, client, endpoint, endpoint)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return
+	}
+	for rows.Next() {
+		var u usageRow
+		if err := rows.Scan(&u.Endpoint, &u.Client, &u.Requests, &u.Errors); err != nil {
+			rows.Close()
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+			return
+		}
+		totals = append(totals, u)
+	}
+	rows.Close()
+
+	recent := []auditEntry{}
+	rows, err = db.Query(`SELECT at, endpoint, client, method, params, status, duration_ms FROM api_audit
+		WHERE (? = '' OR client = ?) AND (? = '' OR endpoint = ?)
+		ORDER BY id DESC LIMIT ?`, client, client, endpoint, endpoint, limit)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a auditEntry
+		var at int64
+		if err := rows.Scan(&at, &a.Endpoint, &a.Client, &a.Method, &a.Params, &a.Status, &a.DurationMs); err != nil {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+			return
+		}
+		a.At = time.Unix(at, 0).UTC()
+		recent = append(recent, a)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"since":  since.Format("2006-01-02"),
+		"totals": totals,
+		"recent": recent,
+	})
+}
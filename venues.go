@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+/* ---------- Venues ---------- */
+
+// venue maps a GotSport location name to a street address.
+type venue struct {
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// venueSet is an in-memory copy of the venues table. A Server's starts with
+// the VENUES config so tools that never open the datastore still get
+// addresses.
+type venueSet struct {
+	mu     sync.RWMutex
+	venues map[string]venue // by lower-cased name
+}
+
+func newVenueSet(seed map[string]string) *venueSet {
+	s := &venueSet{venues: map[string]venue{}}
+	for name, addr := range seed {
+		s.venues[strings.ToLower(name)] = venue{Name: name, Address: addr}
+	}
+	return s
+}
+
+func (s *venueSet) replace(vs []venue) {
+	m := make(map[string]venue, len(vs))
+	for _, v := range vs {
+		m[strings.ToLower(v.Name)] = v
+	}
+	s.mu.Lock()
+	s.venues = m
+	s.mu.Unlock()
+}
+
+func (s *venueSet) list() []venue {
+	s.mu.RLock()
+	out := make([]venue, 0, len(s.venues))
+	for _, v := range s.venues {
+		out = append(out, v)
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// venuePrefix reports whether the lower-cased location loc names the venue
+// key or one of its fields: it starts with key, and the name ends there
+// rather than mid-word, so "reno" matches "reno - field 2" but not
+// "renown field".
+func venuePrefix(loc, key string) bool {
+	if !strings.HasPrefix(loc, key) {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(loc[len(key):])
+	last, _ := utf8.DecodeLastRuneInString(key)
+	return len(loc) == len(key) || !isWordRune(next) || !isWordRune(last)
+}
+
+func isWordRune(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+// address finds the venue whose name is the longest prefix of location, so
+// "Golden Eagle Regional Park - Field 3" matches "Golden Eagle Regional Park".
+func (s *venueSet) address(location string) string {
+	loc := strings.ToLower(strings.TrimSpace(location))
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	best, addr := 0, ""
+	for key, v := range s.venues {
+		if len(key) > best && venuePrefix(loc, key) {
+			best, addr = len(key), v.Address
+		}
+	}
+	return addr
+}
+
+// withVenues returns a copy of games with Address filled from the venue
+// index, AtHome from HOME_VENUES and Logistics from VENUE_LOGISTICS; cached
+// slices are never modified.
+func (s *Server) withVenues(games []Game) []Game {
+	out := make([]Game, len(games))
+	for i, g := range games {
+		g.Address = s.venues.address(g.Location)
+		g.AtHome = isHomeVenue(g.Location, appConfig.HomeVenues)
+		g.Logistics = logisticsFor(g.Location)
+		out[i] = g
+	}
+	return out
+}
+
+// isHomeVenue reports whether location names one of homes, which are
+// lower-cased, or a field there, so every field of a home complex counts.
+func isHomeVenue(location string, homes []string) bool {
+	loc := strings.ToLower(strings.TrimSpace(location))
+	for _, h := range homes {
+		if venuePrefix(loc, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// atHomeOnly drops games away from the club's home venues, in place.
+func atHomeOnly(games []Game) []Game {
+	out := games[:0]
+	for _, g := range games {
+		if g.AtHome {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// seedVenues inserts configured venues that the table does not have yet, so
+// edits made through the admin API survive restarts.
+func (s *Server) seedVenues(seed map[string]string) error {
+	now := time.Now().Unix()
+	for name, addr := range seed {
+		if _, err := s.store.Exec(`INSERT INTO venues (name, address, updated_at) VALUES (?, ?, ?)
+			ON CONFLICT (name) DO NOTHING`, name, addr, now); err != nil {
+			return err
+		}
+	}
+	return s.reloadVenues()
+}
+
+func (s *Server) reloadVenues() error {
+	rows, err := s.store.Query(`SELECT name, address, updated_at FROM venues`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var vs []venue
+	for rows.Next() {
+		var v venue
+		var updated int64
+		if err := rows.Scan(&v.Name, &v.Address, &updated); err != nil {
+			return err
+		}
+		v.UpdatedAt = time.Unix(updated, 0).UTC()
+		vs = append(vs, v)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.venues.replace(vs)
+	return nil
+}
+
+// adminVenuesHandler lists (GET), upserts (PUT/POST JSON {name, address})
+// and deletes (DELETE ?name=) venues.
+func (s *Server) adminVenuesHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	if !requireAdmin(w, r) || !requireSameOrigin(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.venues.list())
+		return
+
+	case http.MethodPut, http.MethodPost:
+		var v venue
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Detail: "Invalid JSON body"})
+			return
+		}
+		v.Name, v.Address = strings.TrimSpace(v.Name), strings.TrimSpace(v.Address)
+		if v.Name == "" || v.Address == "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "name and address are required"})
+			return
+		}
+		v.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+		_, err := s.store.Exec(`INSERT INTO venues (name, address, updated_at) VALUES (?, ?, ?)
+			ON CONFLICT (name) DO UPDATE SET address = excluded.address, updated_at = excluded.updated_at`,
+			v.Name, v.Address, v.UpdatedAt.Unix())
+		if !s.venuesChanged(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusOK, v)
+
+	case http.MethodDelete:
+		name := strings.TrimSpace(r.URL.Query().Get("name"))
+		if name == "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "name is required"})
+			return
+		}
+		res, err := s.store.Exec(`DELETE FROM venues WHERE name = ?`, name)
+		if err == nil {
+			if n, _ := res.RowsAffected(); n == 0 {
+				writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "No venue named " + name})
+				return
+			}
+		}
+		if !s.venuesChanged(w, err) {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{
+			Error:  "method_not_allowed",
+			Detail: "Use GET, PUT, POST or DELETE",
+		})
+	}
+}
+
+// venuesChanged reloads the index after a write, reporting any failure.
+func (s *Server) venuesChanged(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		err = s.reloadVenues()
+	}
+	if err != nil {
+		log.Printf("venues: %v", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return false
+	}
+	return true
+}
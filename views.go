@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+/* ---------- Print and export views ---------- */
+
+const (
+	scrapeViewPage   = "page"
+	scrapeViewPrint  = "print"
+	scrapeViewExport = "export"
+)
+
+// Default view URLs; {event} and {club} are substituted. Override with
+// SCRAPE_VIEW_URL when GotSport moves them.
+var defaultViewURLs = map[string]string{
+	scrapeViewPrint:  gotsportOrigin + "/org_event/events/{event}/schedules/print?club={club}",
+	scrapeViewExport: gotsportOrigin + "/org_event/events/{event}/schedules.csv?club={club}",
+}
+
+func scrapeViewFromEnv() string {
+	switch view := strings.ToLower(stringFromEnv("SCRAPE_VIEW", scrapeViewPage)); view {
+	case scrapeViewPage, scrapeViewPrint, scrapeViewExport:
+		return view
+	default:
+		log.Printf("invalid SCRAPE_VIEW=%q, using %s", view, scrapeViewPage)
+		return scrapeViewPage
+	}
+}
+
+func viewURL(view, eventID, clubID string) string {
+	tmpl := appConfig.ScrapeViewURL
+	if tmpl == "" {
+		tmpl = defaultViewURLs[view]
+	}
+	return strings.NewReplacer("{event}", eventID, "{club}", clubID).Replace(tmpl)
+}
+
+func init() {
+	describeMetric("gotsport_view_fallbacks_total", kindCounter, "Print/export view scrapes that fell back to the schedule page, by view.")
+}
+
+// scrapeGotSportView fetches the configured print or export view. ok is
+// false when it could not be fetched or parsed, and the caller scrapes the
+// full schedule page instead.
+func scrapeGotSportView(ctx context.Context, eventID, clubID string, f scheduleFilter, explain bool) ([]Game, *parseStats, bool, error) {
+	view := appConfig.ScrapeView
+	u := f.withUpstreamQuery(viewURL(view, eventID, clubID))
+	fallback := func(err error) ([]Game, *parseStats, bool, error) {
+		log.Printf("%s view of %s/%s fell back to the schedule page: %v", view, eventID, clubID, err)
+		incCounter("gotsport_view_fallbacks_total", "view", view)
+		return nil, nil, false, nil
+	}
+
+	body, err := fetchPage(ctx, "gotsport", u)
+	if err != nil {
+		return fallback(err)
+	}
+	stats := newPageStats(eventID, len(body), f, explain)
+	start := time.Now()
+
+	var games []Game
+	switch view {
+	case scrapeViewExport:
+		candidates, rowDates, err := parseExportCSV(body, stats)
+		stats.timeStrategy("export_csv", start)
+		if err != nil {
+			return fallback(err)
+		}
+		games = weekendGames(candidates, rowDates, eventID, f, stats)
+	default:
+		games = parseWeekendGames(string(body), eventID, f, stats)
+		if stats.CellRows == 0 && stats.JSONMatches == 0 {
+			return fallback(fmt.Errorf("no schedule rows in %d-byte print view", len(body)))
+		}
+	}
+	stats.TotalMs = float64(time.Since(start).Microseconds()) / 1000
+	stats.Games = len(games)
+	stats.Path = view
+	recordParseStats(stats)
+	if len(games) == 0 {
+		return nil, stats, true, classify(ErrParseEmpty, fmt.Errorf("no games found for event %s", eventID))
+	}
+	return games, stats, true, nil
+}
+
+// scheduleColumns maps lower-cased column headers, from CSV exports and
+// schedule table <th> rows, to fixture fields.
+var scheduleColumns = map[string]string{
+	"match #": "id", "match": "id", "match number": "id", "game #": "id", "#": "id",
+	"date": "date", "time": "time", "start time": "time", "date/time": "date", "date & time": "date", "kickoff": "date",
+	"home": "home", "home team": "home", "away": "away", "away team": "away", "visitor": "away", "visiting team": "away",
+	"location": "location", "venue": "venue", "complex": "venue", "field": "field",
+	"division": "division", "bracket": "division", "group": "division", "flight": "division",
+	"result": "result", "results": "result", "score": "result",
+	"home score": "homeGoal", "away score": "awayGoal",
+}
+
+// columnKeywords place headers scheduleColumns doesn't list, such as
+// "Home Club" or "Kickoff Time", by the first word they contain.
+var columnKeywords = []struct{ word, role string }{
+	{"home", "home"}, {"away", "away"}, {"visit", "away"},
+	{"date", "date"}, {"time", "time"},
+	{"location", "location"}, {"venue", "venue"}, {"complex", "venue"}, {"field", "field"},
+	{"division", "division"}, {"bracket", "division"}, {"flight", "division"},
+	{"result", "result"}, {"score", "result"},
+	{"match", "id"}, {"game", "id"},
+}
+
+// columnRole names the fixture field a column header holds, or "".
+func columnRole(header string) string {
+	h := strings.ToLower(strings.TrimSpace(header))
+	if role, ok := scheduleColumns[h]; ok {
+		return role
+	}
+	for _, k := range columnKeywords {
+		if strings.Contains(h, k.word) {
+			return k.role
+		}
+	}
+	return ""
+}
+
+// parseExportCSV reads a schedule export with a header row, in any column
+// order. An error means the body isn't a usable export.
+func parseExportCSV(body []byte, stats *parseStats) ([]candidate, map[string]bool, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("export csv: %v", err)
+	}
+	if len(records) < 1 {
+		return nil, nil, fmt.Errorf("export csv is empty")
+	}
+	cols := map[string]int{}
+	for i, h := range records[0] {
+		if role := columnRole(h); role != "" {
+			if _, dup := cols[role]; !dup {
+				cols[role] = i
+			}
+		}
+	}
+	for _, need := range []string{"home", "away", "date"} {
+		if _, ok := cols[need]; !ok {
+			return nil, nil, fmt.Errorf("export csv has no %s column (header %q)", need, records[0])
+		}
+	}
+
+	var games []candidate
+	rowDates := map[string]bool{}
+	stats.RowMatches = len(records) - 1
+	for n, rec := range records[1:] {
+		get := func(role string) string {
+			if i, ok := cols[role]; ok && i < len(rec) {
+				return normalizeText(rec[i])
+			}
+			return ""
+		}
+		stats.CellRows++
+
+		dateTime, result, location := fixtureFields(get)
+		d, t := parseDateTime(dateTime)
+		if t == "TBD" {
+			d, t = jsonKickoff(get("date"), get("time"))
+		}
+		if t != "TBD" {
+			rowDates[d] = true
+		}
+
+		o := orientFixture(get("home"), get("away"))
+		ex := rowExplain{
+			Row:      n + 1,
+			Strategy: "export_csv",
+			Fields: map[string]fieldSource{
+				"matchId":  {"csv:id", get("id")},
+				"dateTime": {"csv:date+time", dateTime},
+				"homeTeam": {"csv:home", get("home")},
+				"result":   {"csv:result", result},
+				"awayTeam": {"csv:away", get("away")},
+				"location": {"csv:location", location},
+				"division": {"csv:division", get("division")},
+				"date":     {"parsed from dateTime", d},
+				"time":     {"parsed from dateTime", t},
+			},
+		}
+		games = judgeFixture(rawFixture{
+			Home:      o.home,
+			Away:      o.away,
+			Date:      d,
+			Time:      t,
+			Result:    result,
+			Location:  location,
+			Division:  get("division"),
+			Swapped:   o.swapped,
+			Via:       o.via,
+			Confirmed: true, // the header names the columns
+		}, ex, games, stats)
+	}
+	return games, rowDates, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+/* ---------- Cache warming ---------- */
+
+const jobKindScrape = "scrape"
+
+func init() {
+	registerJobHandler(jobKindScrape, func(ctx context.Context, payload json.RawMessage) error {
+		var ref eventRef
+		if err := json.Unmarshal(payload, &ref); err != nil {
+			return err
+		}
+		games, _, err := defaultServer.loadSchedule(ctx, ref.EventID, ref.ClubID, scheduleFilter{})
+		if err != nil {
+			return err
+		}
+		log.Printf("scrape job %s/%s: %d games cached", ref.EventID, ref.ClubID, len(games))
+		return nil
+	})
+}
+
+// warmCache queues a scrape of every configured event, staggered by interval,
+// so the first user request after a deploy hits the cache.
+func warmCache(refs []eventRef, interval time.Duration) {
+	if len(refs) == 0 {
+		return
+	}
+	log.Printf("Warming cache for %d configured events", len(refs))
+	start := time.Now()
+	for i, ref := range refs {
+		err := enqueueJob(jobKindScrape, ref, jobOptions{
+			DedupeKey: cacheKey(ref.EventID, ref.ClubID),
+			RunAt:     start.Add(time.Duration(i) * interval),
+		})
+		if err != nil {
+			log.Printf("warm %s/%s: %v", ref.EventID, ref.ClubID, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+/* ---------- Parse warnings ---------- */
+
+// parseWarning describes a row that matched the club but could not be
+// turned into a game.
+type parseWarning struct {
+	EventID  string `json:"eventid,omitempty"`
+	Row      int    `json:"row"`
+	Strategy string `json:"strategy,omitempty"`
+	MatchID  string `json:"matchId,omitempty"`
+	Field    string `json:"field"`
+	Value    string `json:"value"`
+	Message  string `json:"message"`
+}
+
+// warn records a warning about field in the row ex describes. Unlike
+// explainRow it is not gated on explain mode.
+func (s *parseStats) warn(ex rowExplain, field, message string) {
+	s.Warnings = append(s.Warnings, parseWarning{
+		EventID:  s.EventID,
+		Row:      ex.Row,
+		Strategy: ex.Strategy,
+		MatchID:  ex.Fields["matchId"].Value,
+		Field:    field,
+		Value:    ex.Fields[field].Value,
+		Message:  message,
+	})
+}
+
+var (
+	parseWarningsMu sync.Mutex
+	parseWarnings   = map[string][]parseWarning{} // by cache key, for the cached games
+)
+
+// recordWarnings replaces the warnings for the games cached under key.
+func recordWarnings(key string, warnings []parseWarning) {
+	parseWarningsMu.Lock()
+	defer parseWarningsMu.Unlock()
+	if len(warnings) == 0 {
+		delete(parseWarnings, key)
+		return
+	}
+	parseWarnings[key] = warnings
+}
+
+// scheduleWarnings returns the warnings for every event of a request, in
+// event order.
+func scheduleWarnings(eventIDs []string, clubID string, f scheduleFilter) []parseWarning {
+	parseWarningsMu.Lock()
+	defer parseWarningsMu.Unlock()
+	var out []parseWarning
+	for _, id := range eventIDs {
+		out = append(out, parseWarnings[cacheKey(id, clubID)+f.cacheSuffix()]...)
+	}
+	return out
+}
+
+// parseWarningsError is a failed scrape whose page had club rows the parser
+// could not read; in strict mode it is reported as 422 rather than 500.
+type parseWarningsError struct {
+	err      error
+	warnings []parseWarning
+}
+
+func (e *parseWarningsError) Error() string { return e.err.Error() }
+func (e *parseWarningsError) Unwrap() error { return e.err }
+
+// strictErrorResponse is the strict=true 422 body.
+type strictErrorResponse struct {
+	ErrorResponse
+	Warnings []parseWarning `json:"warnings"`
+}
+
+func rejectUnparsed(w http.ResponseWriter, warnings []parseWarning) {
+	writeJSON(w, http.StatusUnprocessableEntity, strictErrorResponse{
+		ErrorResponse: ErrorResponse{
+			Error:  "unparsed_rows",
+			Detail: fmt.Sprintf("%d club row(s) could not be parsed; see warnings", len(warnings)),
+		},
+		Warnings: warnings,
+	})
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+/* ---------- CLI watch mode ---------- */
+
+func init() {
+	cliCommands["watch"] = cliWatch
+}
+
+// cliWatch polls a schedule and prints a diff whenever it changes:
+//
+//	gotsport-scraper watch --event 44145 --club 12893 --interval 15m --exec ./notify.sh
+//
+// The hook runs via sh -c with the diff as JSON on stdin and the counts in
+// GOTSPORT_ADDED, GOTSPORT_REMOVED and GOTSPORT_CHANGED.
+func cliWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	event := fs.String("event", "", "GotSport event ID (required)")
+	club := fs.String("club", "", "GotSport club ID (required)")
+	interval := fs.Duration("interval", 15*time.Minute, "time between polls")
+	hook := fs.String("exec", "", "shell command to run when the schedule changes")
+	quiet := fs.Bool("quiet", false, "suppress scraper logging")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *event == "" || *club == "" {
+		return fmt.Errorf("--event and --club are required")
+	}
+	if *interval < time.Minute {
+		return fmt.Errorf("--interval must be at least 1m")
+	}
+	if *quiet {
+		log.SetOutput(io.Discard)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var last []Game
+	first := true
+	for {
+		games, _, err := defaultServer.loadSchedule(ctx, *event, *club, scheduleFilter{})
+		now := time.Now().Format(time.RFC3339)
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "%s poll failed: %v\n", now, err)
+		case first:
+			fmt.Printf("%s watching %s/%s: %d games\n", now, *event, *club, len(games))
+			last, first = games, false
+		default:
+			d := diffSchedules(last, games)
+			if !d.empty() {
+				fmt.Printf("%s schedule changed:\n", now)
+				for _, line := range d.describe() {
+					fmt.Println("  " + line)
+				}
+				if *hook != "" {
+					if err := runWatchHook(ctx, *hook, d); err != nil {
+						fmt.Fprintf(os.Stderr, "%s hook failed: %v\n", now, err)
+					}
+				}
+			}
+			last = games
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+func runWatchHook(ctx context.Context, command string, d scheduleDiff) error {
+	payload, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(),
+		"GOTSPORT_ADDED="+strconv.Itoa(len(d.Added)),
+		"GOTSPORT_REMOVED="+strconv.Itoa(len(d.Removed)),
+		"GOTSPORT_CHANGED="+strconv.Itoa(len(d.Changed)),
+	)
+	return cmd.Run()
+}
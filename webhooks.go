@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ---------- Change webhooks ---------- */
+
+const (
+	jobKindWebhook         = "webhook"
+	defaultWebhookAttempts = 5
+	// webhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of
+	// the body under the subscription's secret.
+	webhookSignatureHeader = "X-Gotsport-Signature"
+)
+
+// webhookSubscription asks for a signed POST to URL whenever a scrape of
+// EventID (and ClubID, when set) finds games added, moved or cancelled.
+type webhookSubscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	EventID   string    `json:"eventid"`
+	ClubID    string    `json:"clubid,omitempty"`
+	Secret    string    `json:"secret,omitempty"` // only shown when created
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// webhookPayload is the body of a delivery.
+type webhookPayload struct {
+	Event   string       `json:"event"` // always "schedule.changed"
+	EventID string       `json:"eventid"`
+	ClubID  string       `json:"clubid"`
+	At      time.Time    `json:"at"`
+	Added   []Game       `json:"added"`
+	Moved   []gameChange `json:"moved"`
+	// Cancelled lists games that dropped off the schedule.
+	Cancelled []Game `json:"cancelled"`
+}
+
+// webhookJob is one queued delivery. It is built and signed when queued, so
+// a retry sends what the first attempt would have and the job queue, shared
+// by every Server, needs no subscription to run it.
+type webhookJob struct {
+	Subscription string          `json:"subscription"`
+	URL          string          `json:"url"`
+	Signature    string          `json:"signature"`
+	Body         json.RawMessage `json:"body"`
+}
+
+// webhookSet is an in-memory copy of the webhooks table.
+type webhookSet struct {
+	mu   sync.RWMutex
+	byID map[string]webhookSubscription
+}
+
+func (s *webhookSet) replace(subs []webhookSubscription) {
+	m := make(map[string]webhookSubscription, len(subs))
+	for _, sub := range subs {
+		m[sub.ID] = sub
+	}
+	s.mu.Lock()
+	s.byID = m
+	s.mu.Unlock()
+}
+
+// list returns every subscription, or those for eventID/clubID when
+// eventID is set. Secrets are left out unless withSecrets is set.
+func (s *webhookSet) list(eventID, clubID string, withSecrets bool) []webhookSubscription {
+	s.mu.RLock()
+	out := []webhookSubscription{}
+	for _, sub := range s.byID {
+		if eventID == "" || sub.EventID == eventID && (sub.ClubID == "" || sub.ClubID == clubID) {
+			if !withSecrets {
+				sub.Secret = ""
+			}
+			out = append(out, sub)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+func (s *Server) reloadWebhooks() error {
+	rows, err := s.store.Query(`SELECT id, url, event_id, club_id, secret, created_at FROM webhooks`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var subs []webhookSubscription
+	for rows.Next() {
+		var sub webhookSubscription
+		var at int64
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.EventID, &sub.ClubID, &sub.Secret, &at); err != nil {
+			return err
+		}
+		sub.CreatedAt = time.Unix(at, 0).UTC()
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.webhooks.replace(subs)
+	return nil
+}
+
+func init() {
+	describeMetric("gotsport_webhooks_total", kindCounter, "Change webhook delivery attempts, by outcome (sent or failed).")
+	registerJobHandler(jobKindWebhook, func(ctx context.Context, payload json.RawMessage) error {
+		var job webhookJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return err
+		}
+		if err := deliverWebhook(ctx, job); err != nil {
+			incCounter("gotsport_webhooks_total", "outcome", "failed")
+			return fmt.Errorf("webhook %s: %v", job.Subscription, err)
+		}
+		incCounter("gotsport_webhooks_total", "outcome", "sent")
+		return nil
+	})
+}
+
+// signWebhook is the signature header value for body under secret.
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func deliverWebhook(ctx context.Context, job webhookJob) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", job.URL, bytes.NewReader(job.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotsport-Event", "schedule.changed")
+	req.Header.Set(webhookSignatureHeader, job.Signature)
+	return notifyDo(req)
+}
+
+// notifyWebhooks queues a delivery of diff to every subscription for
+// eventID/clubID. Deliveries already queued still go out if the
+// subscription is deleted.
+func (s *Server) notifyWebhooks(eventID, clubID string, diff scheduleDiff) {
+	if diff.empty() {
+		return
+	}
+	subs := s.webhooks.list(eventID, clubID, true)
+	if len(subs) == 0 {
+		return
+	}
+	body, err := json.Marshal(webhookPayload{
+		Event:     "schedule.changed",
+		EventID:   eventID,
+		ClubID:    clubID,
+		At:        time.Now().UTC(),
+		Added:     append([]Game{}, diff.Added...),
+		Moved:     append([]gameChange{}, diff.Changed...),
+		Cancelled: append([]Game{}, diff.Removed...),
+	})
+	if err != nil {
+		log.Printf("webhooks: %v", err)
+		return
+	}
+	for _, sub := range subs {
+		job := webhookJob{Subscription: sub.ID, URL: sub.URL, Signature: signWebhook(sub.Secret, body), Body: body}
+		if err := enqueueJob(jobKindWebhook, job, jobOptions{MaxAttempts: defaultWebhookAttempts}); err != nil {
+			log.Printf("webhook %s: %v", sub.ID, err)
+		}
+	}
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// webhooksHandler lists (GET [?eventid=&clubid=]), creates (POST JSON {url,
+// eventid[, clubid][, secret]}) and deletes (DELETE ?id=) change webhooks.
+// A created subscription's secret, generated unless given, is returned only
+// then; deliveries carry the body's HMAC-SHA256 under it in
+// X-Gotsport-Signature.
+func (s *Server) webhooksHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	if !requireAdmin(w, r) || !requireSameOrigin(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		writeJSON(w, http.StatusOK, s.webhooks.list(strings.TrimSpace(q.Get("eventid")), strings.TrimSpace(q.Get("clubid")), false))
+
+	case http.MethodPost:
+		var sub webhookSubscription
+		if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Detail: "Invalid JSON body"})
+			return
+		}
+		sub.URL, sub.EventID, sub.ClubID = strings.TrimSpace(sub.URL), strings.TrimSpace(sub.EventID), strings.TrimSpace(sub.ClubID)
+		if sub.URL == "" || sub.EventID == "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "url and eventid are required"})
+			return
+		}
+		if u, err := url.Parse(sub.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse(badParamsf("url must be an absolute http or https URL")))
+			return
+		}
+		var err error
+		if sub.ID, err = randomHex(8); err == nil && sub.Secret == "" {
+			sub.Secret, err = randomHex(32)
+		}
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "internal", Detail: err.Error()})
+			return
+		}
+		sub.CreatedAt = time.Now().UTC().Truncate(time.Second)
+		_, err = s.store.Exec(`INSERT INTO webhooks (id, url, event_id, club_id, secret, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			sub.ID, sub.URL, sub.EventID, sub.ClubID, sub.Secret, sub.CreatedAt.Unix())
+		if !s.webhooksChanged(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusCreated, sub)
+
+	case http.MethodDelete:
+		id := strings.TrimSpace(r.URL.Query().Get("id"))
+		if id == "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "missing_parameters", Detail: "id is required"})
+			return
+		}
+		res, err := s.store.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+		if err == nil {
+			if n, _ := res.RowsAffected(); n == 0 {
+				writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Detail: "No webhook " + id})
+				return
+			}
+		}
+		if !s.webhooksChanged(w, err) {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{
+			Error:  "method_not_allowed",
+			Detail: "Use GET, POST or DELETE",
+		})
+	}
+}
+
+// webhooksChanged reloads the index after a write, reporting any failure.
+func (s *Server) webhooksChanged(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		err = s.reloadWebhooks()
+	}
+	if err != nil {
+		log.Printf("webhooks: %v", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "store_failed", Detail: err.Error()})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+/* ---------- Embeddable widget ---------- */
+
+var widgetTmpl = template.Must(template.New("widget").Parse(`<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Upcoming games</title>
+<style>
+:root{--bg:#fff;--fg:#1b1f24;--muted:#5b636d;--line:#e3e6ea;--accent:#0b3d91}
+.dark{--bg:#15181c;--fg:#e8eaed;--muted:#9aa0a6;--line:#2c3137;--accent:#7fb0ff}
+body{margin:0;background:var(--bg);color:var(--fg);font:14px/1.4 system-ui,-apple-system,Segoe UI,Roboto,sans-serif}
+table{width:100%;border-collapse:collapse}
+th,td{padding:6px 8px;border-bottom:1px solid var(--line);text-align:left;vertical-align:top}
+th{color:var(--muted);font-weight:600;font-size:12px;text-transform:uppercase}
+td.when{white-space:nowrap;color:var(--accent);font-weight:600}
+.div{color:var(--muted);font-size:12px}
+.empty{padding:12px;color:var(--muted)}
+</style></head>
+<body class="{{.Theme}}">
+{{if .Games}}<table>
+<thead><tr><th>When</th><th>Match</th><th>Field</th></tr></thead>
+<tbody>{{range .Games}}
+<tr><td class="when">{{.When}}</td><td>{{.Home}} vs {{.Away}}<div class="div">{{.Division}}</div></td><td>{{.Location}}{{range .Logistics}}<div class="div">{{.}}</div>{{end}}</td></tr>{{end}}
+</tbody></table>{{else}}<div class="empty">No upcoming games.</div>{{end}}
+</body></html>
+`))
+
+type widgetRow struct {
+	When, Home, Away, Location, Division string
+	Logistics                            []string
+}
+
+// widgetHandler serves /widget?clubid=12893[&eventid=44145][&theme=dark][&limit=10].
+// Without eventid, every configured event for the club is included.
+func (s *Server) widgetHandler(w http.ResponseWriter, r *http.Request) {
+	if cors(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	clubID := q.Get("clubid")
+	if clubID == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "missing_parameters",
+			Detail: "clubid is required",
+		})
+		return
+	}
+	theme := "light"
+	if q.Get("theme") == "dark" {
+		theme = "dark"
+	}
+	limit := 10
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	var eventIDs []string
+	if id := q.Get("eventid"); id != "" {
+		eventIDs = []string{id}
+	} else {
+		for _, ref := range appConfig.Events {
+			if ref.ClubID == clubID {
+				eventIDs = append(eventIDs, ref.EventID)
+			}
+		}
+	}
+	if len(eventIDs) == 0 {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:  "missing_parameters",
+			Detail: "eventid is required when the club has no configured events",
+		})
+		return
+	}
+
+	var sets []eventGames
+	for _, id := range eventIDs {
+		res, err := s.getSchedule(r.Context(), id, clubID, scheduleFilter{}, false, s.cache.maxAge())
+		if err != nil {
+			log.Printf("widget %s/%s: %v", id, clubID, err)
+			continue
+		}
+		sets = append(sets, eventGames{EventID: id, Games: res.Games})
+	}
+	games := mergeSchedules(sets)
+
+	now := time.Now()
+	type upcoming struct {
+		at time.Time
+		g  Game
+	}
+	var list []upcoming
+	for _, g := range games {
+		if at, ok := gameKickoff(g); ok && at.Add(gameDuration(g, at)).After(now) {
+			list = append(list, upcoming{at, g})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].at.Before(list[j].at) })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	rows := make([]widgetRow, 0, len(list))
+	for _, u := range list {
+		row := widgetRow{
+			When:     u.at.Format("Mon Jan 2, 3:04 PM"),
+			Home:     u.g.HomeTeam,
+			Away:     u.g.AwayTeam,
+			Location: u.g.Location,
+			Division: u.g.Division,
+		}
+		if u.g.Logistics != nil {
+			row.Logistics = u.g.Logistics.lines()
+		}
+		rows = append(rows, row)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(s.cache.maxAge().Seconds())))
+	if err := widgetTmpl.Execute(w, map[string]any{"Theme": theme, "Games": rows}); err != nil {
+		log.Printf("widget render: %v", err)
+	}
+}
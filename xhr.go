@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ---------- GotSport XHR endpoints ---------- */
+
+const (
+	scrapeModeHTML = "html"
+	scrapeModeXHR  = "xhr"
+
+	gotsportOrigin = "https://system.gotsport.com"
+	xhrEndpointTTL = 24 * time.Hour
+)
+
+func scrapeModeFromEnv() string {
+	switch mode := strings.ToLower(stringFromEnv("SCRAPE_MODE", scrapeModeHTML)); mode {
+	case scrapeModeHTML, scrapeModeXHR:
+		return mode
+	default:
+		log.Printf("invalid SCRAPE_MODE=%q, using %s", mode, scrapeModeHTML)
+		return scrapeModeHTML
+	}
+}
+
+var (
+	// endpointRefPattern finds URLs the page's scripts fetch: data-url style
+	// attributes, "url": config entries and fetch()/axios calls.
+	endpointRefPattern = regexp.MustCompile(`(?i)(?:data-(?:url|src|source|endpoint|schedule-url)\s*=\s*|["']?(?:url|endpoint|api_url|apiUrl|schedule_url|scheduleUrl)["']?\s*:\s*|(?:fetch|axios\.get|\$\.getJSON|\$\.get)\(\s*)["']((?:https://system\.gotsport\.com)?(?:\\?/)[^"'\s<>]+)["']`)
+	endpointHint       = regexp.MustCompile(`(?i)(?:/api/|\.json\b|format=json)`)
+	endpointSubject    = regexp.MustCompile(`(?i)schedul|match|game`)
+)
+
+// xhrEndpoint is a discovered backend URL for one event/club schedule.
+type xhrEndpoint struct {
+	url          string
+	discoveredAt time.Time
+}
+
+var gotsportBase, _ = url.Parse(gotsportOrigin)
+
+var (
+	xhrMu        sync.Mutex
+	xhrEndpoints = map[string]xhrEndpoint{} // by cacheKey
+)
+
+func init() {
+	describeMetric("gotsport_xhr_fallbacks_total", kindCounter, "XHR-mode scrapes that fell back to the HTML page, by reason.")
+}
+
+// discoverXHREndpoint remembers the JSON endpoint a schedule page loads its
+// data from, preferring one that mentions the event.
+func discoverXHREndpoint(eventID, clubID, html string) {
+	var best string
+	for _, m := range endpointRefPattern.FindAllStringSubmatch(html, -1) {
+		ref := strings.ReplaceAll(strings.ReplaceAll(m[1], `\/`, "/"), "&amp;", "&")
+		if !endpointHint.MatchString(ref) || !endpointSubject.MatchString(ref) {
+			continue
+		}
+		if best == "" || (!strings.Contains(best, eventID) && strings.Contains(ref, eventID)) {
+			best = ref
+		}
+	}
+	if best == "" {
+		return
+	}
+	u, err := url.Parse(best)
+	if err != nil {
+		return
+	}
+	u = gotsportBase.ResolveReference(u)
+	if u.Host != "system.gotsport.com" {
+		return // never follow the page off-site
+	}
+	if q := u.Query(); q.Get("club") == "" && q.Get("club_id") == "" {
+		q.Set("club", clubID)
+		u.RawQuery = q.Encode()
+	}
+
+	key := cacheKey(eventID, clubID)
+	xhrMu.Lock()
+	defer xhrMu.Unlock()
+	if old, ok := xhrEndpoints[key]; !ok || old.url != u.String() {
+		log.Printf("Discovered schedule endpoint for %s: %s", key, u)
+	}
+	xhrEndpoints[key] = xhrEndpoint{url: u.String(), discoveredAt: time.Now()}
+}
+
+func lookupXHREndpoint(key string) string {
+	xhrMu.Lock()
+	defer xhrMu.Unlock()
+	ep, ok := xhrEndpoints[key]
+	if !ok || time.Since(ep.discoveredAt) > xhrEndpointTTL {
+		delete(xhrEndpoints, key)
+		return ""
+	}
+	return ep.url
+}
+
+func forgetXHREndpoint(key string) {
+	xhrMu.Lock()
+	delete(xhrEndpoints, key)
+	xhrMu.Unlock()
+}
+
+// scrapeGotSportXHR reads the schedule from its discovered JSON endpoint.
+// ok is false when there is no endpoint yet or it failed; the caller then
+// scrapes the HTML page, which also rediscovers the endpoint.
+func scrapeGotSportXHR(ctx context.Context, eventID, clubID string, f scheduleFilter, explain bool) ([]Game, *parseStats, bool, error) {
+	key := cacheKey(eventID, clubID)
+	endpoint := lookupXHREndpoint(key)
+	if endpoint == "" {
+		return nil, nil, false, nil
+	}
+	fallback := func(reason string, err error) ([]Game, *parseStats, bool, error) {
+		log.Printf("XHR scrape of %s fell back to HTML (%s): %v", key, reason, err)
+		incCounter("gotsport_xhr_fallbacks_total", "reason", reason)
+		if reason != "fetch_failed" {
+			forgetXHREndpoint(key) // the payload no longer looks like a schedule
+		}
+		return nil, nil, false, nil
+	}
+
+	body, err := fetchJSON(ctx, "gotsport", f.withUpstreamQuery(endpoint))
+	if err != nil {
+		return fallback("fetch_failed", err)
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fallback("not_json", err)
+	}
+
+	stats := newPageStats(eventID, len(body), f, explain)
+	start := time.Now()
+	candidates, rowDates, ok := gamesFromDocuments([]any{doc}, "xhr", stats)
+	stats.timeStrategy("xhr", start)
+	if !ok {
+		return fallback("shape_changed", fmt.Errorf("no match objects in %d-byte payload", len(body)))
+	}
+	games := weekendGames(candidates, rowDates, eventID, f, stats)
+	stats.TotalMs = float64(time.Since(start).Microseconds()) / 1000
+	stats.Games = len(games)
+	stats.Path = scrapePathXHR
+	recordParseStats(stats)
+	if len(games) == 0 {
+		return nil, stats, true, classify(ErrParseEmpty, fmt.Errorf("no games found for event %s", eventID))
+	}
+	return games, stats, true, nil
+}
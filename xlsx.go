@@ -0,0 +1,181 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* ---------- XLSX output ---------- */
+
+// A minimal SpreadsheetML writer: one sheet per division, bold frozen header
+// row, and real date/time cells so the workbook sorts and filters in Excel.
+
+const (
+	xlsxStyleHeader = 1
+	xlsxStyleDate   = 2
+	xlsxStyleTime   = 3
+)
+
+var (
+	xlsxHeaders   = []string{"Date", "Kickoff", "Home", "Away", "Field", "Division"}
+	xlsxColWidths = []int{12, 10, 34, 34, 28, 24}
+	xlsxEpoch     = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	xlsxBadChars  = strings.NewReplacer("[", "(", "]", ")", ":", "-", "*", "", "?", "", "/", "-", `\`, "-")
+)
+
+func writeXLSX(w http.ResponseWriter, games []Game) {
+	// Group by division, keeping each sheet in kickoff order.
+	byDiv := map[string][]Game{}
+	for _, g := range games {
+		div := strings.TrimSpace(g.Division)
+		if div == "" {
+			div = "Other"
+		}
+		byDiv[div] = append(byDiv[div], g)
+	}
+	divs := make([]string, 0, len(byDiv))
+	for d := range byDiv {
+		divs = append(divs, d)
+	}
+	sort.Strings(divs)
+	if len(divs) == 0 {
+		divs = []string{"Schedule"}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	put := func(name, body string) {
+		f, err := zw.Create(name)
+		if err == nil {
+			_, _ = f.Write([]byte(xml.Header + body))
+		}
+	}
+
+	var sheets, rels, overrides strings.Builder
+	used := map[string]bool{}
+	for i, div := range divs {
+		n := i + 1
+		name := xlsxSheetName(div, used)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(name), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		put(fmt.Sprintf("xl/worksheets/sheet%d.xml", n), xlsxSheet(byDiv[div]))
+	}
+	stylesRel := len(divs) + 1
+
+	put("[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`+
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`+
+		`<Default Extension="xml" ContentType="application/xml"/>`+
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`+
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`+
+		overrides.String()+`</Types>`)
+	put("_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`+
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>`+
+		`</Relationships>`)
+	put("xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`+
+		`<sheets>`+sheets.String()+`</sheets></workbook>`)
+	put("xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`+
+		rels.String()+
+		fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, stylesRel)+
+		`</Relationships>`)
+	put("xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`+
+		`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="h:mm AM/PM"/></numFmts>`+
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>`+
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>`+
+		`<borders count="1"><border/></borders>`+
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`+
+		`<cellXfs count="4"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>`+
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>`+
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>`+
+		`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>`+
+		`</styleSheet>`)
+
+	if err := zw.Close(); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "encode_failed", Detail: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", `attachment; filename="schedule.xlsx"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func xlsxSheet(games []Game) string {
+	sort.SliceStable(games, func(i, j int) bool {
+		a, _ := gameKickoff(games[i])
+		b, _ := gameKickoff(games[j])
+		return a.Before(b)
+	})
+
+	var b strings.Builder
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	b.WriteString(`<cols>`)
+	for i, wd := range xlsxColWidths {
+		fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, wd)
+	}
+	b.WriteString(`</cols><sheetData><row r="1">`)
+	for i, h := range xlsxHeaders {
+		b.WriteString(xlsxString(i, 1, h, xlsxStyleHeader))
+	}
+	b.WriteString(`</row>`)
+	for i, g := range games {
+		r := i + 2
+		fmt.Fprintf(&b, `<row r="%d">`, r)
+		if at, ok := gameKickoff(g); ok {
+			wall := time.Date(at.Year(), at.Month(), at.Day(), at.Hour(), at.Minute(), 0, 0, time.UTC)
+			serial := wall.Sub(xlsxEpoch).Hours() / 24
+			day := float64(int(serial))
+			fmt.Fprintf(&b, `<c r="A%d" s="%d"><v>%g</v></c>`, r, xlsxStyleDate, day)
+			fmt.Fprintf(&b, `<c r="B%d" s="%d"><v>%g</v></c>`, r, xlsxStyleTime, serial-day)
+		} else {
+			b.WriteString(xlsxString(0, r, g.Date, 0))
+			b.WriteString(xlsxString(1, r, g.Time, 0))
+		}
+		b.WriteString(xlsxString(2, r, g.HomeTeam, 0))
+		b.WriteString(xlsxString(3, r, g.AwayTeam, 0))
+		b.WriteString(xlsxString(4, r, g.Location, 0))
+		b.WriteString(xlsxString(5, r, g.Division, 0))
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+func xlsxString(col, row int, s string, style int) string {
+	return fmt.Sprintf(`<c r="%c%d" t="inlineStr" s="%d"><is><t>%s</t></is></c>`, 'A'+col, row, style, xmlEscape(s))
+}
+
+// xlsxSheetName makes a valid, unique sheet name (31 chars, no []:*?/\).
+func xlsxSheetName(s string, used map[string]bool) string {
+	name := strings.TrimSpace(xlsxBadChars.Replace(s))
+	if name == "" {
+		name = "Sheet"
+	}
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	base := name
+	for i := 2; used[strings.ToLower(name)]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		r := []rune(base)
+		if len(r)+len(suffix) > 31 {
+			r = r[:31-len(suffix)]
+		}
+		name = string(r) + suffix
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
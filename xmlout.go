@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"sort"
+)
+
+/* ---------- XML output ---------- */
+
+// encodeXML renders v as XML using its JSON shape, so element names follow
+// the json struct tags: a []Game becomes <games><game><homeTeam>...
+func encodeXML(root, item string, v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	if err := xmlValue(enc, root, item, generic); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func xmlValue(enc *xml.Encoder, name, item string, v any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch x := v.(type) {
+	case nil:
+	case []any:
+		for _, e := range x {
+			if err := xmlValue(enc, item, "item", e); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := xmlValue(enc, k, "item", x[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		var s string
+		switch y := x.(type) {
+		case string:
+			s = y
+		case json.Number:
+			s = y.String()
+		case bool:
+			s = "false"
+			if y {
+				s = "true"
+			}
+		}
+		if err := enc.EncodeToken(xml.CharData(s)); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func writeXML(w http.ResponseWriter, status int, root, item string, v any) {
+	body, err := encodeXML(root, item, v)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "encode_failed", Detail: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}